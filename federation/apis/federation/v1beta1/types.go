@@ -54,6 +54,10 @@ const (
 	ClusterReady ClusterConditionType = "Ready"
 	// ClusterOffline means the cluster is temporarily down or not reachable
 	ClusterOffline ClusterConditionType = "Offline"
+	// ClusterDuplicate means the cluster registration points at the same
+	// member cluster as an older registration and is therefore ignored by
+	// the federated controllers.
+	ClusterDuplicate ClusterConditionType = "Duplicate"
 )
 
 // ClusterCondition describes current state of a cluster.
@@ -20,6 +20,7 @@ limitations under the License.
 package app
 
 import (
+	"expvar"
 	"fmt"
 	"net"
 	"net/http"
@@ -132,6 +133,9 @@ func Run(s *options.CMServer) error {
 			mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 		}
 		mux.Handle("/metrics", prometheus.Handler())
+		if s.EnableExpvarMetrics {
+			mux.Handle("/debug/vars", expvar.Handler())
+		}
 
 		server := &http.Server{
 			Addr:    net.JoinHostPort(s.Address, strconv.Itoa(s.Port)),
@@ -192,10 +196,29 @@ func StartControllers(s *options.CMServer, restClientCfg *restclient.Config) err
 	// TODO: rename s.ConcurentReplicaSetSyncs
 	go deploymentController.Run(s.ConcurrentReplicaSetSyncs, wait.NeverStop)
 
+	var propagationPolicy *util.PropagationPolicy
+	if s.LabelPropagationPolicy != "" {
+		var err error
+		propagationPolicy, err = util.ParsePropagationPolicy(s.LabelPropagationPolicy)
+		if err != nil {
+			glog.Fatalf("Invalid --label-propagation-policy: %v", err)
+		}
+	}
+
 	if controllerEnabled(s.Controllers, serverResources, ingresscontroller.ControllerName, ingresscontroller.RequiredResources, true) {
 		glog.Infof("Loading client config for ingress controller %q", "ingress-controller")
 		ingClientset := federationclientset.NewForConfigOrDie(restclient.AddUserAgent(restClientCfg, "ingress-controller"))
-		ingressController := ingresscontroller.NewIngressController(ingClientset)
+		ingressController := ingresscontroller.NewIngressControllerWithOptions(ingresscontroller.IngressControllerOptions{
+			FedClient: ingClientset,
+			Config: &ingresscontroller.IngressControllerConfig{
+				PropagationPolicy:           propagationPolicy,
+				EnableExpvarMetrics:         s.EnableExpvarMetrics,
+				IncludeStatusInComparison:   s.IncludeIngressStatusInComparison,
+				DisableUIDReconciliation:    !s.IngressUIDReconciliation,
+				ClusterShardSelector:        s.ClusterShardSelector,
+				ClusterShardFederatedWriter: s.ClusterShardFederatedWriter,
+			},
+		})
 		glog.Infof("Running ingress controller")
 		ingressController.Run(wait.NeverStop)
 	}
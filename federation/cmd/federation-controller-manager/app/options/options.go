@@ -68,6 +68,33 @@ type ControllerManagerConfiguration struct {
 	LeaderElection componentconfig.LeaderElectionConfiguration `json:"leaderElection"`
 	// contentType is contentType of requests sent to apiserver.
 	ContentType string `json:"contentType"`
+	// labelPropagationPolicy restricts which label and annotation keys the
+	// sync controllers propagate onto member cluster copies, in the form
+	// "include=glob1,glob2;exclude=glob3". Empty means propagate everything.
+	LabelPropagationPolicy string `json:"labelPropagationPolicy"`
+	// enableExpvarMetrics additionally publishes controller counters via
+	// expvar at /debug/vars, for deployments that do not scrape Prometheus.
+	EnableExpvarMetrics bool `json:"enableExpvarMetrics"`
+	// includeIngressStatusInComparison extends the federated ingress
+	// comparison to the loadbalancer status, re-deriving the federated status
+	// when a cluster copy's status was externally wiped.
+	IncludeIngressStatusInComparison bool `json:"includeIngressStatusInComparison"`
+	// clusterShardSelector, when non-empty, is a label selector restricting
+	// which member clusters this controller-manager instance manages.
+	// Several instances with disjoint selectors can split a very large
+	// federation between them; exactly one of them must also run with
+	// clusterShardFederatedWriter so that only that instance writes to the
+	// federated objects themselves.
+	ClusterShardSelector string `json:"clusterShardSelector"`
+	// clusterShardFederatedWriter marks this instance as the shard that
+	// writes federated object status, finalizers and coordination
+	// annotations. Only meaningful together with clusterShardSelector.
+	ClusterShardFederatedWriter bool `json:"clusterShardFederatedWriter"`
+	// ingressUIDReconciliation controls whether the ingress controller keeps
+	// the ingress-uid ConfigMap in member clusters in sync. Disable for
+	// federations whose member clusters have no such ConfigMap (e.g. all
+	// running nginx-ingress); the controller then does pure spec propagation.
+	IngressUIDReconciliation bool `json:"ingressUIDReconciliation"`
 	// ConfigurationMap determining which controllers should be enabled or disabled
 	Controllers config.ConfigurationMap `json:"controllers"`
 }
@@ -97,6 +124,7 @@ func NewCMServer() *CMServer {
 			APIServerQPS:              20.0,
 			APIServerBurst:            30,
 			LeaderElection:            leaderelection.DefaultLeaderElectionConfiguration(),
+			IngressUIDReconciliation:  true,
 			Controllers:               make(config.ConfigurationMap),
 		},
 	}
@@ -118,6 +146,12 @@ func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&s.Master, "master", s.Master, "The address of the federation API server (overrides any value in kubeconfig)")
 	fs.StringVar(&s.Kubeconfig, "kubeconfig", s.Kubeconfig, "Path to kubeconfig file with authorization and master location information.")
 	fs.StringVar(&s.ContentType, "kube-api-content-type", s.ContentType, "ContentType of requests sent to apiserver. Passing application/vnd.kubernetes.protobuf is an experimental feature now.")
+	fs.StringVar(&s.LabelPropagationPolicy, "label-propagation-policy", s.LabelPropagationPolicy, "Label and annotation keys to always propagate to, or strip from, member cluster objects, in the form 'include=glob1,glob2;exclude=glob3'. Empty propagates everything.")
+	fs.BoolVar(&s.EnableExpvarMetrics, "enable-expvar-metrics", s.EnableExpvarMetrics, "Additionally publish controller counters via expvar at /debug/vars, as a dependency-light alternative to the Prometheus endpoint.")
+	fs.BoolVar(&s.IncludeIngressStatusInComparison, "include-ingress-status-in-comparison", s.IncludeIngressStatusInComparison, "Also compare the loadbalancer status of federated ingresses against the member cluster copies, re-deriving the federated status when a cluster copy's status was externally cleared.")
+	fs.StringVar(&s.ClusterShardSelector, "cluster-shard-selector", s.ClusterShardSelector, "Label selector restricting which member clusters this controller-manager instance manages. Several instances with disjoint selectors can split a very large federation between them; exactly one of them must also run with --cluster-shard-federated-writer. Empty manages all clusters.")
+	fs.BoolVar(&s.ClusterShardFederatedWriter, "cluster-shard-federated-writer", s.ClusterShardFederatedWriter, "Mark this instance as the shard that writes federated object status, finalizers and coordination annotations. Only meaningful together with --cluster-shard-selector.")
+	fs.BoolVar(&s.IngressUIDReconciliation, "ingress-uid-reconciliation", s.IngressUIDReconciliation, "Keep the ingress-uid ConfigMap in member clusters in sync so that all GCE ingress controllers share one ingress UID. Disable for federations whose member clusters have no such ConfigMap (e.g. all running nginx-ingress); federated ingresses are then propagated without any UID, first-cluster or static IP coordination.")
 	fs.Float32Var(&s.APIServerQPS, "federated-api-qps", s.APIServerQPS, "QPS to use while talking with federation apiserver")
 	fs.IntVar(&s.APIServerBurst, "federated-api-burst", s.APIServerBurst, "Burst to use while talking with federation apiserver")
 	fs.StringVar(&s.DnsProvider, "dns-provider", s.DnsProvider, "DNS provider. Valid values are: "+fmt.Sprintf("%q", dnsprovider.RegisteredDnsProviders()))
@@ -127,4 +161,5 @@ func (s *CMServer) AddFlags(fs *pflag.FlagSet) {
 		"to controller manager to enable/disable specific controllers. Valid options are: \n"+
 		"ingress=true|false (default=true)")
 	leaderelection.BindFlags(&s.LeaderElection, fs)
+	config.DefaultFeatureGate.AddFlag(fs)
 }
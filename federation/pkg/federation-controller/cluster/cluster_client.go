@@ -29,6 +29,7 @@ import (
 	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
 	"k8s.io/kubernetes/pkg/client/restclient"
 	"k8s.io/kubernetes/pkg/client/typed/discovery"
+	"k8s.io/kubernetes/pkg/types"
 	"k8s.io/kubernetes/pkg/util/sets"
 )
 
@@ -45,6 +46,12 @@ type ClusterClient struct {
 }
 
 func NewClusterClientSet(c *federation_v1beta1.Cluster) (*ClusterClient, error) {
+	// Reject clusters whose kubeconfig secret is missing or malformed with a
+	// specific error instead of failing deep inside client construction. The
+	// validation result is cached until the secret changes.
+	if secretErr := validateClusterSecret(c); secretErr != nil {
+		return nil, secretErr
+	}
 	clusterConfig, err := util.BuildClusterConfig(c)
 	if err != nil {
 		return nil, err
@@ -112,6 +119,18 @@ func (self *ClusterClient) GetClusterHealthStatus() *federation_v1beta1.ClusterS
 	return &clusterStatus
 }
 
+// GetClusterUID returns the UID of the kube-system namespace of the member
+// cluster. The UID survives renames and re-registrations of the Cluster
+// object and therefore identifies the physical cluster itself, which makes
+// it suitable for detecting duplicate registrations.
+func (self *ClusterClient) GetClusterUID() (types.UID, error) {
+	namespace, err := self.kubeClient.Core().Namespaces().Get(api.NamespaceSystem, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	return namespace.ObjectMeta.UID, nil
+}
+
 // GetClusterZones gets the kubernetes cluster zones and region by inspecting labels on nodes in the cluster.
 func (self *ClusterClient) GetClusterZones() (zones []string, region string, err error) {
 	return getZoneNames(self.kubeClient)
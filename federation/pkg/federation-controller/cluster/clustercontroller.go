@@ -17,6 +17,7 @@ limitations under the License.
 package cluster
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -24,10 +25,14 @@ import (
 	federationv1beta1 "k8s.io/kubernetes/federation/apis/federation/v1beta1"
 	clustercache "k8s.io/kubernetes/federation/client/cache"
 	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util/eventsink"
 	"k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/controller"
 	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/types"
 	utilruntime "k8s.io/kubernetes/pkg/util/runtime"
 	"k8s.io/kubernetes/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/util/wait"
@@ -48,19 +53,33 @@ type ClusterController struct {
 	// clusterKubeClientMap is a mapping of clusterName and restclient
 	clusterKubeClientMap map[string]ClusterClient
 
+	// clusterUIDMap caches, per Cluster object name, the UID of the member
+	// cluster's kube-system namespace. It is fetched once per registration
+	// and used to detect different registrations of the same member cluster.
+	clusterUIDMap map[string]types.UID
+
 	// cluster framework and store
 	clusterController *cache.Controller
 	clusterStore      clustercache.StoreToClusterLister
+
+	// eventRecorder is used to attach secret validation failures to the
+	// Cluster object as events.
+	eventRecorder record.EventRecorder
 }
 
 // NewclusterController returns a new cluster controller
 func NewclusterController(federationClient federationclientset.Interface, clusterMonitorPeriod time.Duration) *ClusterController {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(eventsink.NewFederatedEventSink(federationClient))
+	recorder := broadcaster.NewRecorder(v1.EventSource{Component: UserAgentName})
 	cc := &ClusterController{
 		knownClusterSet:         make(sets.String),
 		federationClient:        federationClient,
 		clusterMonitorPeriod:    clusterMonitorPeriod,
 		clusterClusterStatusMap: make(map[string]federationv1beta1.ClusterStatus),
 		clusterKubeClientMap:    make(map[string]ClusterClient),
+		clusterUIDMap:           make(map[string]types.UID),
+		eventRecorder:           recorder,
 	}
 	cc.clusterStore.Store, cc.clusterController = cache.NewInformer(
 		&cache.ListWatch{
@@ -87,6 +106,8 @@ func (cc *ClusterController) delFromClusterSet(obj interface{}) {
 	cluster := obj.(*federationv1beta1.Cluster)
 	cc.knownClusterSet.Delete(cluster.Name)
 	delete(cc.clusterKubeClientMap, cluster.Name)
+	delete(cc.clusterUIDMap, cluster.Name)
+	validatedSecrets.forget(cluster.Name)
 }
 
 // addToClusterSet insert the new cluster to clusterSet and create a corresponding
@@ -116,6 +137,13 @@ func (cc *ClusterController) Run() {
 }
 
 func (cc *ClusterController) GetClusterStatus(cluster *federationv1beta1.Cluster) (*federationv1beta1.ClusterStatus, error) {
+	// Surface kubeconfig secret problems as a specific NotReady reason and an
+	// event instead of a generic client creation failure in the logs.
+	if secretErr := validateClusterSecret(cluster); secretErr != nil {
+		glog.V(2).Infof("Kubeconfig secret for cluster %s failed validation: %v", cluster.Name, secretErr)
+		cc.eventRecorder.Eventf(cluster, v1.EventTypeWarning, secretErr.Reason, "%v", secretErr)
+		return clusterStatusForSecretError(secretErr), nil
+	}
 	// just get the status of cluster, by requesting the restapi "/healthz"
 	clusterClient, found := cc.clusterKubeClientMap[cluster.Name]
 	if !found {
@@ -132,6 +160,60 @@ func (cc *ClusterController) GetClusterStatus(cluster *federationv1beta1.Cluster
 	return clusterStatus, nil
 }
 
+// detectDuplicates returns, for every Cluster object that points at the same
+// member cluster as another registration, the name of the registration it
+// duplicates. The member cluster is identified by the UID of its kube-system
+// namespace, fetched once per registration and cached in clusterUIDMap. The
+// oldest registration wins; all newer ones pointing at the same member
+// cluster are reported as duplicates of it.
+func (cc *ClusterController) detectDuplicates(clusters []federationv1beta1.Cluster) map[string]string {
+	duplicateOf := make(map[string]string)
+	registrationsByUID := make(map[types.UID]*federationv1beta1.Cluster)
+	for i := range clusters {
+		cluster := &clusters[i]
+		memberUID, found := cc.clusterUIDMap[cluster.Name]
+		if !found {
+			clusterClient, ok := cc.clusterKubeClientMap[cluster.Name]
+			if !ok {
+				continue
+			}
+			uid, err := clusterClient.GetClusterUID()
+			if err != nil {
+				glog.Warningf("Failed to get member cluster UID for cluster %s: %v", cluster.Name, err)
+				continue
+			}
+			memberUID = uid
+			cc.clusterUIDMap[cluster.Name] = memberUID
+		}
+		original, found := registrationsByUID[memberUID]
+		if !found {
+			registrationsByUID[memberUID] = cluster
+			continue
+		}
+		if cluster.CreationTimestamp.Before(original.CreationTimestamp) {
+			duplicateOf[original.Name] = cluster.Name
+			registrationsByUID[memberUID] = cluster
+		} else {
+			duplicateOf[cluster.Name] = original.Name
+		}
+	}
+	return duplicateOf
+}
+
+// newClusterDuplicateCondition builds the condition set on registrations that
+// duplicate an older registration of the same member cluster.
+func newClusterDuplicateCondition(duplicateOf string) federationv1beta1.ClusterCondition {
+	currentTime := metav1.Now()
+	return federationv1beta1.ClusterCondition{
+		Type:               federationv1beta1.ClusterDuplicate,
+		Status:             v1.ConditionTrue,
+		Reason:             "DuplicateRegistration",
+		Message:            fmt.Sprintf("points at the same member cluster as existing registration %s", duplicateOf),
+		LastProbeTime:      currentTime,
+		LastTransitionTime: currentTime,
+	}
+}
+
 // UpdateClusterStatus checks cluster status and get the metrics from cluster's restapi
 func (cc *ClusterController) UpdateClusterStatus() error {
 	clusters, err := cc.federationClient.Federation().Clusters().List(v1.ListOptions{})
@@ -157,6 +239,7 @@ func (cc *ClusterController) UpdateClusterStatus() error {
 			cc.knownClusterSet.Delete(clusterName)
 		}
 	}
+	duplicateOf := cc.detectDuplicates(clusters.Items)
 	for _, cluster := range clusters.Items {
 		clusterStatusNew, err := cc.GetClusterStatus(&cluster)
 		if err != nil {
@@ -170,6 +253,10 @@ func (cc *ClusterController) UpdateClusterStatus() error {
 		} else {
 			hasTransition := false
 			for i := 0; i < len(clusterStatusNew.Conditions); i++ {
+				if i >= len(clusterStatusOld.Conditions) {
+					hasTransition = true
+					break
+				}
 				if !(strings.EqualFold(string(clusterStatusNew.Conditions[i].Type), string(clusterStatusOld.Conditions[i].Type)) &&
 					strings.EqualFold(string(clusterStatusNew.Conditions[i].Status), string(clusterStatusOld.Conditions[i].Status))) {
 					hasTransition = true
@@ -196,6 +283,12 @@ func (cc *ClusterController) UpdateClusterStatus() error {
 			clusterStatusNew.Zones = zones
 			clusterStatusNew.Region = region
 		}
+		if original, isDuplicate := duplicateOf[cluster.Name]; isDuplicate {
+			clusterStatusNew.Conditions = append(clusterStatusNew.Conditions, newClusterDuplicateCondition(original))
+			cc.eventRecorder.Eventf(&cluster, v1.EventTypeWarning, "DuplicateCluster",
+				"Cluster points at the same member cluster (kube-system namespace UID %s) as existing registration %s",
+				cc.clusterUIDMap[cluster.Name], original)
+		}
 		cc.clusterClusterStatusMap[cluster.Name] = *clusterStatusNew
 		cluster.Status = *clusterStatusNew
 		cluster, err := cc.federationClient.Federation().Clusters().UpdateStatus(&cluster)
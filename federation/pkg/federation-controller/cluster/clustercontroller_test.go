@@ -22,6 +22,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	federationv1beta1 "k8s.io/kubernetes/federation/apis/federation/v1beta1"
 	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
@@ -32,6 +33,8 @@ import (
 	"k8s.io/kubernetes/pkg/client/restclient"
 	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
 	clientcmdapi "k8s.io/kubernetes/pkg/client/unversioned/clientcmd/api"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/sets"
 	"k8s.io/kubernetes/pkg/util/uuid"
 )
 
@@ -107,6 +110,67 @@ func createHttptestFakeHandlerForCluster(canBeGotten bool) *http.HandlerFunc {
 	return &fakeHandler
 }
 
+// init a fake http handler, simulate a member cluster apiserver, response the
+// kube-system namespace with the given UID for any "GET"
+func createHttptestFakeHandlerForMemberCluster(namespaceUID string) *http.HandlerFunc {
+	fakeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"kube-system","uid":"%s"}}`, namespaceUID)
+	})
+	return &fakeHandler
+}
+
+func TestDetectDuplicateClusters(t *testing.T) {
+	// Two registrations whose member clusters report the same kube-system
+	// namespace UID, i.e. the same physical cluster registered twice.
+	testClusterServer1 := httptest.NewServer(createHttptestFakeHandlerForMemberCluster("member-cluster-uid"))
+	defer testClusterServer1.Close()
+	testClusterServer2 := httptest.NewServer(createHttptestFakeHandlerForMemberCluster("member-cluster-uid"))
+	defer testClusterServer2.Close()
+
+	olderCluster := newCluster("older-cluster", testClusterServer1.URL)
+	olderCluster.CreationTimestamp = metav1.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	newerCluster := newCluster("newer-cluster", testClusterServer2.URL)
+	newerCluster.CreationTimestamp = metav1.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// Override KubeconfigGetterForCluster to avoid having to setup service accounts and mount files with secret tokens.
+	originalGetter := controllerutil.KubeconfigGetterForCluster
+	controllerutil.KubeconfigGetterForCluster = func(c *federationv1beta1.Cluster) clientcmd.KubeconfigGetter {
+		return func() (*clientcmdapi.Config, error) {
+			return &clientcmdapi.Config{}, nil
+		}
+	}
+	defer func() { controllerutil.KubeconfigGetterForCluster = originalGetter }()
+
+	cc := &ClusterController{
+		knownClusterSet:      make(sets.String),
+		clusterKubeClientMap: make(map[string]ClusterClient),
+		clusterUIDMap:        make(map[string]types.UID),
+	}
+	for _, cluster := range []*federationv1beta1.Cluster{olderCluster, newerCluster} {
+		clusterClient, err := NewClusterClientSet(cluster)
+		if err != nil {
+			t.Fatalf("Failed to create cluster client for %s: %v", cluster.Name, err)
+		}
+		cc.clusterKubeClientMap[cluster.Name] = *clusterClient
+	}
+
+	// The newer registration is the duplicate, regardless of the order in
+	// which the registrations are seen.
+	duplicateOf := cc.detectDuplicates([]federationv1beta1.Cluster{*newerCluster, *olderCluster})
+	if len(duplicateOf) != 1 {
+		t.Fatalf("Expected exactly one duplicate registration, got: %v", duplicateOf)
+	}
+	if duplicateOf["newer-cluster"] != "older-cluster" {
+		t.Errorf("Expected newer-cluster to duplicate older-cluster, got: %v", duplicateOf)
+	}
+
+	// The member cluster UIDs are fetched once and cached.
+	if cc.clusterUIDMap["older-cluster"] != "member-cluster-uid" || cc.clusterUIDMap["newer-cluster"] != "member-cluster-uid" {
+		t.Errorf("Expected member cluster UIDs to be cached, got: %v", cc.clusterUIDMap)
+	}
+}
+
 func TestUpdateClusterStatusOK(t *testing.T) {
 	clusterName := "foobarCluster"
 	// create dummy httpserver
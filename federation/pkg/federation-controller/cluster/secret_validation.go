@@ -0,0 +1,188 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	federation_v1beta1 "k8s.io/kubernetes/federation/apis/federation/v1beta1"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	clientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// Condition reasons written on a Cluster whose kubeconfig secret cannot be
+// used to build a cluster client. They distinguish the common failure modes
+// that would otherwise all surface as a generic client creation error.
+const (
+	// The referenced secret does not exist or cannot be fetched.
+	ReasonSecretMissing = "SecretMissing"
+	// The secret exists but its kubeconfig data is absent or does not parse.
+	ReasonKubeconfigInvalid = "KubeconfigInvalid"
+	// The kubeconfig parses but contains no credentials.
+	ReasonAuthInfoMissing = "AuthInfoMissing"
+)
+
+// ClusterSecretError describes why a cluster's kubeconfig secret was
+// rejected. Reason is one of the Reason* constants above and is suitable for
+// use as a condition reason on the Cluster object.
+type ClusterSecretError struct {
+	Reason  string
+	message string
+}
+
+func (e *ClusterSecretError) Error() string {
+	return e.message
+}
+
+// getClusterSecret fetches the named kubeconfig secret from the namespace the
+// controller runs in. This is a variable so that tests can substitute secret
+// fixtures without an in-cluster client.
+var getClusterSecret = func(secretName string) (*api.Secret, error) {
+	// Get the namespace this is running in from the env variable.
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		return nil, fmt.Errorf("unexpected: POD_NAMESPACE env var returned empty string")
+	}
+	cc, err := restclient.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error in creating in-cluster client: %s", err)
+	}
+	client, err := clientset.NewForConfig(cc)
+	if err != nil {
+		return nil, fmt.Errorf("error in creating in-cluster client: %s", err)
+	}
+	return client.Core().Secrets(namespace).Get(secretName, metav1.GetOptions{})
+}
+
+type secretValidationEntry struct {
+	secretUID       types.UID
+	resourceVersion string
+	result          *ClusterSecretError
+}
+
+// secretValidationCache remembers the validation outcome per cluster, keyed
+// by the secret's UID and resource version, so that an unchanged kubeconfig
+// is not re-parsed on every monitoring cycle.
+type secretValidationCache struct {
+	sync.Mutex
+	entries map[string]secretValidationEntry
+}
+
+func (c *secretValidationCache) get(clusterName string, secret *api.Secret) (*ClusterSecretError, bool) {
+	c.Lock()
+	defer c.Unlock()
+	entry, found := c.entries[clusterName]
+	if !found || entry.secretUID != secret.UID || entry.resourceVersion != secret.ResourceVersion {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+func (c *secretValidationCache) put(clusterName string, secret *api.Secret, result *ClusterSecretError) {
+	c.Lock()
+	defer c.Unlock()
+	c.entries[clusterName] = secretValidationEntry{
+		secretUID:       secret.UID,
+		resourceVersion: secret.ResourceVersion,
+		result:          result,
+	}
+}
+
+func (c *secretValidationCache) forget(clusterName string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.entries, clusterName)
+}
+
+var validatedSecrets = &secretValidationCache{entries: map[string]secretValidationEntry{}}
+
+// validateClusterSecret checks that the kubeconfig secret referenced by the
+// given cluster can be used to build a cluster client, returning a
+// ClusterSecretError with a specific reason when it cannot. A cluster without
+// a secret reference uses insecure access and has nothing to validate.
+// Validation results are cached per cluster until the secret changes.
+func validateClusterSecret(cluster *federation_v1beta1.Cluster) *ClusterSecretError {
+	if cluster.Spec.SecretRef == nil {
+		return nil
+	}
+	secretName := cluster.Spec.SecretRef.Name
+	secret, err := getClusterSecret(secretName)
+	if err != nil || secret == nil {
+		validatedSecrets.forget(cluster.Name)
+		return &ClusterSecretError{
+			Reason:  ReasonSecretMissing,
+			message: fmt.Sprintf("cluster %q references kubeconfig secret %q that cannot be fetched: %v", cluster.Name, secretName, err),
+		}
+	}
+	if result, found := validatedSecrets.get(cluster.Name, secret); found {
+		return result
+	}
+	result := validateKubeconfigSecret(cluster.Name, secret)
+	validatedSecrets.put(cluster.Name, secret, result)
+	return result
+}
+
+// validateKubeconfigSecret checks that the given secret carries a parseable
+// kubeconfig with credentials under the expected data key.
+func validateKubeconfigSecret(clusterName string, secret *api.Secret) *ClusterSecretError {
+	data, found := secret.Data[KubeconfigSecretDataKey]
+	if !found || len(data) == 0 {
+		return &ClusterSecretError{
+			Reason:  ReasonKubeconfigInvalid,
+			message: fmt.Sprintf("kubeconfig secret %q for cluster %q has no data under the %q key", secret.Name, clusterName, KubeconfigSecretDataKey),
+		}
+	}
+	config, err := clientcmd.Load(data)
+	if err != nil {
+		return &ClusterSecretError{
+			Reason:  ReasonKubeconfigInvalid,
+			message: fmt.Sprintf("kubeconfig secret %q for cluster %q does not parse: %v", secret.Name, clusterName, err),
+		}
+	}
+	if len(config.AuthInfos) == 0 {
+		return &ClusterSecretError{
+			Reason:  ReasonAuthInfoMissing,
+			message: fmt.Sprintf("kubeconfig secret %q for cluster %q contains no auth info", secret.Name, clusterName),
+		}
+	}
+	return nil
+}
+
+// clusterStatusForSecretError returns a status marking the cluster NotReady
+// with the secret validation failure as the condition reason and message.
+func clusterStatusForSecretError(secretErr *ClusterSecretError) *federation_v1beta1.ClusterStatus {
+	currentTime := metav1.Now()
+	return &federation_v1beta1.ClusterStatus{
+		Conditions: []federation_v1beta1.ClusterCondition{
+			{
+				Type:               federation_v1beta1.ClusterReady,
+				Status:             v1.ConditionFalse,
+				Reason:             secretErr.Reason,
+				Message:            secretErr.Error(),
+				LastProbeTime:      currentTime,
+				LastTransitionTime: currentTime,
+			},
+		},
+	}
+}
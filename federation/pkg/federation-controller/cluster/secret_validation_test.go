@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"testing"
+
+	federationv1beta1 "k8s.io/kubernetes/federation/apis/federation/v1beta1"
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+const validKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://example.com
+  name: foo
+users:
+- name: admin
+  user:
+    token: secret-token
+`
+
+const kubeconfigWithoutAuthInfo = `
+apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://example.com
+  name: foo
+`
+
+// Checks that the secret validation distinguishes a missing secret, an
+// unparseable kubeconfig and a kubeconfig without credentials, each with its
+// own condition reason.
+func TestValidateClusterSecret(t *testing.T) {
+	originalGetter := getClusterSecret
+	defer func() { getClusterSecret = originalGetter }()
+
+	secrets := map[string]*api.Secret{}
+	getClusterSecret = func(secretName string) (*api.Secret, error) {
+		secret, found := secrets[secretName]
+		if !found {
+			return nil, fmt.Errorf("secret %q not found", secretName)
+		}
+		return secret, nil
+	}
+	makeSecret := func(name string, data map[string][]byte) *api.Secret {
+		secret := &api.Secret{}
+		secret.Name = name
+		secret.UID = types.UID("uid-" + name)
+		secret.ResourceVersion = "1"
+		secret.Data = data
+		secrets[name] = secret
+		return secret
+	}
+	makeCluster := func(name, secretName string) *federationv1beta1.Cluster {
+		cluster := newCluster(name, "http://localhost:8080")
+		if secretName != "" {
+			cluster.Spec.SecretRef = &v1.LocalObjectReference{Name: secretName}
+		}
+		return cluster
+	}
+
+	makeSecret("no-kubeconfig-key", map[string][]byte{"other": []byte("data")})
+	makeSecret("garbage", map[string][]byte{KubeconfigSecretDataKey: []byte("{not kubeconfig")})
+	makeSecret("no-auth-info", map[string][]byte{KubeconfigSecretDataKey: []byte(kubeconfigWithoutAuthInfo)})
+	makeSecret("valid", map[string][]byte{KubeconfigSecretDataKey: []byte(validKubeconfig)})
+
+	testCases := []struct {
+		cluster    *federationv1beta1.Cluster
+		wantReason string
+	}{
+		{makeCluster("insecure", ""), ""},
+		{makeCluster("missing-secret", "no-such-secret"), ReasonSecretMissing},
+		{makeCluster("missing-key", "no-kubeconfig-key"), ReasonKubeconfigInvalid},
+		{makeCluster("garbage-kubeconfig", "garbage"), ReasonKubeconfigInvalid},
+		{makeCluster("no-credentials", "no-auth-info"), ReasonAuthInfoMissing},
+		{makeCluster("healthy", "valid"), ""},
+	}
+	for _, testCase := range testCases {
+		result := validateClusterSecret(testCase.cluster)
+		if testCase.wantReason == "" {
+			if result != nil {
+				t.Errorf("cluster %s: expected valid secret, got %q: %v", testCase.cluster.Name, result.Reason, result)
+			}
+		} else if result == nil {
+			t.Errorf("cluster %s: expected reason %s, got no error", testCase.cluster.Name, testCase.wantReason)
+		} else if result.Reason != testCase.wantReason {
+			t.Errorf("cluster %s: expected reason %s, got %s: %v", testCase.cluster.Name, testCase.wantReason, result.Reason, result)
+		}
+	}
+
+	// The validation outcome is cached: fixing the secret data without
+	// changing its resource version does not trigger a re-parse, bumping the
+	// resource version does.
+	cluster := makeCluster("cached", "garbage")
+	result := validateClusterSecret(cluster)
+	if result == nil || result.Reason != ReasonKubeconfigInvalid {
+		t.Fatalf("expected reason %s, got %v", ReasonKubeconfigInvalid, result)
+	}
+	secrets["garbage"].Data[KubeconfigSecretDataKey] = []byte(validKubeconfig)
+	if result := validateClusterSecret(cluster); result == nil || result.Reason != ReasonKubeconfigInvalid {
+		t.Errorf("expected the cached result for an unchanged secret, got %v", result)
+	}
+	secrets["garbage"].ResourceVersion = "2"
+	if result := validateClusterSecret(cluster); result != nil {
+		t.Errorf("expected revalidation after a secret change, got %v", result)
+	}
+}
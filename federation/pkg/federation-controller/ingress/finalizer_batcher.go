@@ -0,0 +1,136 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"sync"
+	"time"
+
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/clock"
+	"k8s.io/kubernetes/pkg/util/flowcontrol"
+
+	"github.com/golang/glog"
+)
+
+// All removals share one backoff entry: when the federation api server is
+// rejecting writes during a deletion burst, the whole pool slows down together
+// instead of every object probing the server independently.
+const finalizerRemovalBackoffKey = ".all-finalizer-removals"
+
+// finalizerRemovalBatcher coalesces the finalizer-removal writes of a mass
+// deletion. Each removal is still one api call per object, but the calls are
+// drained by a bounded pool of workers sharing a single backoff, so a burst
+// of deletions cannot fan out into an unbounded number of concurrent
+// federation writes. Enqueueing an object whose removal is already queued
+// replaces the pending copy instead of adding a second entry.
+type finalizerRemovalBatcher struct {
+	workers int
+	remove  func(ingress *extensionsv1beta1.Ingress) error
+	clock   clock.Clock
+	backoff *flowcontrol.Backoff
+
+	lock sync.Mutex
+	// pending holds the newest enqueued copy per key; order holds the keys
+	// in first-enqueued order.
+	pending map[string]*extensionsv1beta1.Ingress
+	order   []string
+	// Buffered wake-up signal for workers idling on an empty queue.
+	notify chan struct{}
+}
+
+func newFinalizerRemovalBatcher(workers int, cl clock.Clock, remove func(*extensionsv1beta1.Ingress) error) *finalizerRemovalBatcher {
+	return &finalizerRemovalBatcher{
+		workers: workers,
+		remove:  remove,
+		clock:   cl,
+		backoff: flowcontrol.NewBackOff(time.Second, time.Minute),
+		pending: make(map[string]*extensionsv1beta1.Ingress),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// run starts the worker pool. All workers stop when stopChan is closed.
+func (b *finalizerRemovalBatcher) run(stopChan <-chan struct{}) {
+	for i := 0; i < b.workers; i++ {
+		go b.worker(stopChan)
+	}
+}
+
+// enqueue schedules the removal of the federation finalizers from the given
+// ingress. A removal already queued under the same key is replaced by the
+// newer copy.
+func (b *finalizerRemovalBatcher) enqueue(ingress *extensionsv1beta1.Ingress) {
+	key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}.String()
+	b.lock.Lock()
+	if _, queued := b.pending[key]; !queued {
+		b.order = append(b.order, key)
+	}
+	b.pending[key] = ingress
+	recordPendingFinalizerRemovals(len(b.pending))
+	b.lock.Unlock()
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next pops the oldest queued removal, or nil when the queue is empty.
+func (b *finalizerRemovalBatcher) next() *extensionsv1beta1.Ingress {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for len(b.order) > 0 {
+		key := b.order[0]
+		b.order = b.order[1:]
+		if ingress, queued := b.pending[key]; queued {
+			delete(b.pending, key)
+			recordPendingFinalizerRemovals(len(b.pending))
+			return ingress
+		}
+	}
+	return nil
+}
+
+func (b *finalizerRemovalBatcher) worker(stopChan <-chan struct{}) {
+	for {
+		ingress := b.next()
+		if ingress == nil {
+			select {
+			case <-b.notify:
+				continue
+			case <-stopChan:
+				return
+			}
+		}
+		if delay := b.backoff.Get(finalizerRemovalBackoffKey); delay > 0 {
+			select {
+			case <-b.clock.After(delay):
+			case <-stopChan:
+				return
+			}
+		}
+		if err := b.remove(ingress); err != nil {
+			b.backoff.Next(finalizerRemovalBackoffKey, b.clock.Now())
+			glog.V(2).Infof("Failed to remove federation finalizers from ingress %s/%s (will retry): %v", ingress.Namespace, ingress.Name, err)
+			b.enqueue(ingress)
+			continue
+		}
+		b.backoff.Reset(finalizerRemovalBackoffKey)
+		recordFinalizerRemoval()
+	}
+}
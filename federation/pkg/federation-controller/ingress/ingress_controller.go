@@ -17,8 +17,14 @@ limitations under the License.
 package ingress
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	federationapi "k8s.io/kubernetes/federation/apis/federation/v1beta1"
@@ -28,16 +34,24 @@ import (
 	"k8s.io/kubernetes/federation/pkg/federation-controller/util/eventsink"
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
 	"k8s.io/kubernetes/pkg/api/v1"
 	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/client/cache"
 	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	"k8s.io/kubernetes/pkg/client/record"
 	"k8s.io/kubernetes/pkg/controller"
+	"k8s.io/kubernetes/pkg/labels"
 	pkgruntime "k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/runtime/schema"
 	"k8s.io/kubernetes/pkg/types"
+	"k8s.io/kubernetes/pkg/util/clock"
+	utilconfig "k8s.io/kubernetes/pkg/util/config"
 	"k8s.io/kubernetes/pkg/util/flowcontrol"
+	"k8s.io/kubernetes/pkg/util/intstr"
+	"k8s.io/kubernetes/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/util/wait"
 	"k8s.io/kubernetes/pkg/watch"
 
 	"github.com/golang/glog"
@@ -50,6 +64,8 @@ const (
 	staticIPNameKeyWritable = "kubernetes.io/ingress.global-static-ip-name" // The writable annotation on Ingress to tell the controller to use a specific, named, static IP
 	staticIPNameKeyReadonly = "ingress.kubernetes.io/static-ip"             // The readonly key via which the cluster's Ingress Controller communicates which static IP it used.  If staticIPNameKeyWritable above is specified, it is used.
 	uidAnnotationKey        = "kubernetes.io/ingress.uid"                   // The annotation on federation clusters, where we store the ingress UID
+	uidAnnotationOwnerKey   = "federation.alpha.kubernetes.io/ingress-uid-owner" // Marks that the uidAnnotationKey annotation was written by federation, so that cleanup does not remove a user-set UID.
+	uidAnnotationOwnerValue = "federation"
 	uidConfigMapName        = "ingress-uid"                                 // Name of the config-map and key the ingress controller stores its uid in.
 	uidConfigMapNamespace   = "kube-system"
 	uidKey                  = "uid"
@@ -59,12 +75,285 @@ const (
 	// other cluster.
 	firstClusterAnnotation = "ingress.federation.kubernetes.io/first-cluster"
 	ControllerName         = "ingress"
+	// Condition set on a Cluster while its api server is rate-limiting (429)
+	// propagation requests from the federation.
+	clusterThrottledCondition = federationapi.ClusterConditionType("Throttled")
+	// Condition set on a Cluster while its api server forbids the watch verb
+	// and the federation falls back to polling its objects.
+	clusterWatchDegradedCondition = federationapi.ClusterConditionType("WatchDegraded")
+	// Condition set on a Cluster whose api endpoint is the federation's own:
+	// the host cluster joined as a member. Propagating ingresses into it
+	// would write into the same API surface the federation watches, so such
+	// clusters are skipped.
+	clusterSelfReferenceCondition = federationapi.ClusterConditionType("SelfReference")
+	// Condition set on a Cluster whose backend service NodePort disagrees
+	// with the other clusters hosting a federated ingress. GLBC health
+	// checks every cluster of a shared global loadbalancer on the same
+	// NodePort, so a disagreeing cluster serves unhealthy backends.
+	clusterNodePortMismatchCondition = federationapi.ClusterConditionType("IngressNodePortMismatch")
+	// Condition set on a Cluster while ingress propagation into one or more
+	// of its namespaces is blocked by an exhausted resource quota. The
+	// message lists the blocked namespaces.
+	clusterQuotaBlockedCondition = federationapi.ClusterConditionType("IngressQuotaBlocked")
+	// Annotation on a federation Cluster object explicitly pinning it as the
+	// authoritative source of the ingress UID. Only consulted when the
+	// prefer-oldest UID reconciliation strategy is enabled.
+	uidAuthorityAnnotation = "federation.kubernetes.io/ingress-uid-authority"
+	// Finalizer set on Cluster objects while at least one federation-managed
+	// ingress targets the cluster. It guards against removing a cluster from
+	// the federation while it still hosts live ingresses (and hence cloud
+	// load balancers) that would be orphaned by the removal.
+	clusterIngressFinalizer = "federation.kubernetes.io/ingress-cleanup"
+	// Minimum time between "skipping not-ready cluster" log messages per
+	// cluster, to keep a flapping cluster from spamming the logs.
+	notReadySkipLogInterval = time.Minute
+	// Annotation on the federated ingress requesting a canary traffic split.
+	// The value has the form "<cluster>=<weight>" with weight in [0, 100]:
+	// only the named cluster's copy gets the per-cluster weight annotation,
+	// all other clusters keep the stable config.
+	canaryAnnotation = "federation.alpha.kubernetes.io/canary"
+	// Per-cluster annotation carrying the canary weight, consumed by cluster
+	// ingress controllers that support traffic splitting.
+	canaryWeightAnnotation = "ingress.alpha.kubernetes.io/canary-weight"
+	// Name of the target store index over the presence of the provider static
+	// IP annotation on cluster ingress copies.
+	providerStaticIPIndex = "provider-static-ip"
+	// Well-known annotation selecting the ingress controller responsible for
+	// an ingress.
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+	// Label on a federation Cluster object overriding the ingress class
+	// stamped on the cluster's copies of federated ingresses.
+	ingressClassOverrideLabel = "federation.kubernetes.io/ingress-class"
+	// Label on a federation Cluster object declaring whether the cluster can
+	// provision external load balancers. Clusters labeled "false" (e.g. bare
+	// metal clusters without a cloud provider) are excluded from ingress
+	// placement: a copy created there would report a pending loadbalancer
+	// status forever.
+	lbCapableLabel = "federation.kubernetes.io/load-balancer-capable"
+	// Annotation on a cluster's ingress UID ConfigMap opting the cluster out
+	// of UID reconciliation: with the value "cluster" the uid value is owned
+	// by the cluster and never overwritten by federation.
+	uidManagedByAnnotation = "federation.alpha.kubernetes.io/uid-managed-by"
+	uidManagedByCluster    = "cluster"
+
+	// Annotation (on the federated ingress or on its federated namespace)
+	// opting into delete protection: a deletion only cascades into the member
+	// clusters if the confirmation annotation was present before the delete,
+	// otherwise the cluster copies are orphaned.
+	deleteProtectionAnnotation = "federation.alpha.kubernetes.io/delete-protection"
+	deleteProtectionCascading  = "cascading"
+	// Annotation confirming that a cascading delete of a protected ingress is
+	// intentional. Must be set before deleting the federated ingress.
+	confirmCascadingDeleteAnnotation = "federation.alpha.kubernetes.io/confirm-cascading-delete"
+
+	// Annotation on cluster copies recording which top-level spec fields the
+	// federation manages, so that preserve-local merges only overwrite fields
+	// that federation previously owned and relinquish fields it stopped
+	// setting.
+	managedFieldsAnnotation = "federation.kubernetes.io/managed-fields"
+	managedFieldBackend     = "backend"
+	managedFieldTLS         = "tls"
+	managedFieldRules       = "rules"
+	// Annotation on a federation Cluster object mapping federated namespaces
+	// to the namespace the cluster hosts federation-managed objects in. The
+	// value is a template containing the {{namespace}} placeholder, e.g.
+	// "fed-{{namespace}}". Absent means copies live in the same namespace as
+	// the federated object.
+	namespaceMappingAnnotation = "federation.alpha.kubernetes.io/namespace-mapping"
+	// Placeholder replaced with the federated namespace in the mapping
+	// template.
+	namespaceMappingPlaceholder = "{{namespace}}"
+	// Annotation set on the federated ingress while no member cluster has
+	// reported a loadbalancer IP or hostname yet, so that consumers can tell
+	// "still provisioning" apart from "failed". Cleared as soon as any
+	// cluster reports an address.
+	lbPendingAnnotation = "federation.kubernetes.io/lb-pending"
+	// Annotation on cluster copies recording exactly what federation last
+	// wrote there, so that fields added afterwards by the cluster (e.g. a
+	// mutating admission webhook) can be told apart from drift in fields
+	// federation set.
+	lastAppliedAnnotation = "federation.alpha.kubernetes.io/last-applied"
+	// Annotation on the federated ingress recording the spec that last
+	// reached all clusters, as a JSON-serialized IngressSpec. A staggered
+	// rollout with RollbackOnFailure reverts already-updated clusters to it
+	// when a later stage fails.
+	lastKnownGoodSpecAnnotation = "federation.alpha.kubernetes.io/last-known-good-spec"
+	// Annotation on a federated ingress holding a host template such as
+	// "{{host}}.{{cluster}}.example.com". Every rule and TLS host of the
+	// per-cluster desired spec is rewritten through the template ({{host}}
+	// expands to the first label of the federated host, {{cluster}} to the
+	// cluster name), so each cluster serves its own name while DNS maps the
+	// global name across clusters. Simple placeholder substitution only.
+	hostTemplateAnnotation = "federation.alpha.kubernetes.io/host-template"
+	// Annotation on a federated ingress naming the cluster whose
+	// loadbalancer status is used verbatim as the federated status instead
+	// of the usual merge across all cluster copies. Useful in active/passive
+	// setups where only the active cluster's address should be published.
+	// When the named cluster is not ready (or unknown) the federated status
+	// falls back to the merge.
+	statusSourceClusterAnnotation = "federation.kubernetes.io/status-source-cluster"
+
+	// Set on the federated ingress when the merged loadbalancer status was
+	// truncated to MaxStatusIngressEntries; the value is the number of
+	// addresses dropped. Removed when the status fits the cap again.
+	statusTruncatedAnnotation = "federation.kubernetes.io/status-truncated"
+	// Optional per-ingress propagation deadline, e.g. "10m". When the
+	// ingress has not fully propagated to all targeted clusters within the
+	// deadline (measured from when the current generation was first seen
+	// incompletely propagated), the controller emits a
+	// PropagationDeadlineExceeded warning event and marks the ingress
+	// degraded. The timer restarts when the spec changes.
+	propagationDeadlineAnnotation = "federation.alpha.kubernetes.io/propagation-deadline"
+	// Set on the federated ingress when its propagation deadline was
+	// exceeded; removed once the ingress converges in all clusters.
+	degradedAnnotation = "federation.alpha.kubernetes.io/degraded"
+	// Federation-level pre-declaration of a GLBC static IP name. The value
+	// is stamped verbatim into the writable static IP annotation and hence
+	// propagated to every cluster copy, so no first-cluster election or IP
+	// transfer is needed. Useful when the global IP is allocated up front
+	// (e.g. via gcloud), including for default-backend-only ingresses.
+	preallocatedStaticIPAnnotation = "federation.alpha.kubernetes.io/static-ip-name"
+	// When set to "true" on a federated ingress, creates are withheld from
+	// every cluster until each TLS secret referenced by the spec exists in
+	// every targeted cluster. All-or-nothing: a single cluster missing a
+	// secret holds the ingress back everywhere, so no subset of clusters
+	// serves the hostname without TLS while the rest has it.
+	requireTLSSecretAnnotation = "federation.alpha.kubernetes.io/require-tls-secret"
+
+	// Annotation on a federated ingress naming the cluster whose copy must be
+	// established first. Copies in the other clusters are held back until the
+	// primary copy satisfies the readiness predicate (by default: it reports
+	// a loadbalancer address), so that e.g. ACME HTTP-01 solvers do not race
+	// across clusters for the same hostname. On deletion the order reverses:
+	// the primary copy is removed last.
+	primaryClusterAnnotation = "federation.alpha.kubernetes.io/primary-cluster"
+	// Condition set on a Cluster whose self-managed ingress UID differs from
+	// the federation's UID.
+	clusterUIDConflictCondition = federationapi.ClusterConditionType("IngressUIDConflict")
+
+	// Reasons why a reconcile was deferred on a not-yet-synced informer.
+	unsyncedReasonClusterList       = "cluster-list"
+	unsyncedReasonIngressInformer   = "ingress-informer"
+	unsyncedReasonConfigMapInformer = "configmap-informer"
+	// How often the deferred-reconcile summary is logged while deferrals
+	// keep occurring.
+	unsyncedSkipLogInterval = time.Minute
+
+	// The maximum number of cluster or ingress names listed in the shutdown
+	// summary. Entries beyond the cap are folded into a count so the summary
+	// stays bounded regardless of federation size.
+	maxShutdownSummaryNames = 10
+
+	// Patch type used for server-side apply writes. Api servers that do not
+	// understand apply reject requests with this content type, so writes are
+	// gated on the per-cluster capability probe.
+	serverSideApplyPatchType = api.PatchType("application/apply-patch+yaml")
+	// The stable field manager identity under which federation's apply
+	// writes are recorded, so field ownership stays consistent across
+	// controller restarts.
+	serverSideApplyFieldManager = "federation-ingress-controller"
+	// Minor version of the 1.x release line from which server-side apply is
+	// available on the api server.
+	serverSideApplyMinMinorVersion = 16
 )
 
 var (
 	RequiredResources = []schema.GroupVersionResource{extensionsv1beta1.SchemeGroupVersion.WithResource("ingresses")}
+
+	// gceProviderAnnotationKeys is the one authoritative list of annotations
+	// the GCE ingress controller (GLBC) stamps on an ingress it manages,
+	// naming the cloud resources it created for it. They are stripped from
+	// cluster copies on an ingress class change when
+	// GCProviderAnnotationsOnClassChange is enabled.
+	gceProviderAnnotationKeys = sets.NewString(
+		"ingress.kubernetes.io/backends",
+		"ingress.kubernetes.io/forwarding-rule",
+		"ingress.kubernetes.io/https-forwarding-rule",
+		"ingress.kubernetes.io/https-target-proxy",
+		"ingress.kubernetes.io/ssl-cert",
+		"ingress.kubernetes.io/static-ip",
+		"ingress.kubernetes.io/target-proxy",
+		"ingress.kubernetes.io/url-map",
+	)
+)
+
+// AdoptionConflictPolicy controls how conflicts between federation's
+// annotations and those already present on an adopted cluster ingress are
+// resolved.
+type AdoptionConflictPolicy string
+
+const (
+	// AdoptionConflictOverwrite resolves conflicts in favor of the federated
+	// ingress. This is the default and matches the historical behavior.
+	AdoptionConflictOverwrite AdoptionConflictPolicy = "federation-overwrites"
+	// AdoptionConflictPreserve keeps the existing cluster values for
+	// conflicting annotations.
+	AdoptionConflictPreserve AdoptionConflictPolicy = "preserve-existing"
+	// AdoptionConflictFail refuses to adopt a cluster ingress whose
+	// annotations conflict with federation's.
+	AdoptionConflictFail AdoptionConflictPolicy = "fail"
+)
+
+// adoptionConflictError reports an annotation conflict that the
+// AdoptionConflictFail policy refused to resolve.
+type adoptionConflictError struct {
+	key             string
+	federationValue string
+	clusterValue    string
+}
+
+func (e *adoptionConflictError) Error() string {
+	return fmt.Sprintf("conflicting annotation %q: federation has %q, cluster has %q", e.key, e.federationValue, e.clusterValue)
+}
+
+// RolloutStrategyType is the type of the rollout strategy for spec updates.
+type RolloutStrategyType string
+
+const (
+	// RolloutStrategyImmediate applies spec updates to all clusters at once.
+	RolloutStrategyImmediate RolloutStrategyType = "Immediate"
+	// RolloutStrategyStaggered applies spec updates to at most MaxUnavailable
+	// clusters at a time, waiting Interval between consecutive batches, so that
+	// a bad change is caught before it hits all clusters.
+	RolloutStrategyStaggered RolloutStrategyType = "Staggered"
+)
+
+// LBStatusMergeOrder selects how loadbalancer addresses reported by the
+// member clusters are ordered when merged into the federated status.
+type LBStatusMergeOrder string
+
+const (
+	// LBStatusMergeSorted orders the merged addresses lexicographically and
+	// drops duplicates. The result is stable regardless of which cluster
+	// reported first.
+	LBStatusMergeSorted LBStatusMergeOrder = "Sorted"
+	// LBStatusMergePreserveFirstSeen orders the merged addresses by the name
+	// of the reporting cluster, keeping the first occurrence of each address.
+	// For DNS integrations that map addresses positionally per cluster.
+	LBStatusMergePreserveFirstSeen LBStatusMergeOrder = "PreserveFirstSeen"
 )
 
+// RolloutStrategy controls how ingress spec updates propagate across the
+// member clusters. Status reads are not affected by the strategy.
+type RolloutStrategy struct {
+	Type RolloutStrategyType
+	// Interval between applying the update in consecutive cluster batches.
+	// Only used by RolloutStrategyStaggered.
+	Interval time.Duration
+	// Maximum number of clusters updated (and hence potentially unavailable)
+	// at a time. Only used by RolloutStrategyStaggered.
+	MaxUnavailable int
+	// When a rollout stage fails, revert the clusters already updated in
+	// earlier stages to the last-known-good spec recorded on the federated
+	// ingress. Only used by RolloutStrategyStaggered.
+	RollbackOnFailure bool
+}
+
+// ClusterStatusExtractor returns the loadbalancer status a cluster copy of an
+// ingress reports, for clouds whose ingress controllers encode status in a
+// nonstandard place.
+type ClusterStatusExtractor func(cluster string, ingress *extensionsv1beta1.Ingress) v1.LoadBalancerStatus
+
 type IngressController struct {
 	sync.Mutex // Lock used for leader election
 	// For triggering single ingress reconcilation. This is used when there is an
@@ -72,6 +361,10 @@ type IngressController struct {
 	// in some member of the federation.
 	ingressDeliverer *util.DelayingDeliverer
 
+	// Orders delivered ingresses by their reconcile-priority annotation
+	// before they reach the reconciliation worker.
+	reconcileBuffer *reconcileBuffer
+
 	// For triggering reconcilation of cluster ingress controller configmap and
 	// all ingresses. This is used when a new cluster becomes available.
 	clusterDeliverer *util.DelayingDeliverer
@@ -84,14 +377,28 @@ type IngressController struct {
 	ingressFederatedInformer util.FederatedInformer
 	// Contains ingress controller configmaps present in members of federation.
 	configMapFederatedInformer util.FederatedInformer
+	// Contains the services of members of the federation, for the backend
+	// NodePort consistency check.
+	serviceFederatedInformer util.FederatedInformer
+	// If true, the ingress-uid ConfigMap machinery and the UID/first-cluster/
+	// static IP coordination built on it are skipped entirely.
+	uidReconciliationDisabled bool
 	// For updating ingresses in members of federation.
 	federatedIngressUpdater util.FederatedUpdater
 	// For updating configmaps in members of federation.
 	federatedConfigMapUpdater util.FederatedUpdater
+	// For rewriting backend service NodePorts in members of federation,
+	// behind the FederationNodePortRewrite gate.
+	federatedServiceUpdater util.FederatedUpdater
 	// Definitions of ingresses that should be federated.
 	ingressInformerStore cache.Store
 	// Informer controller for ingresses that should be federated.
 	ingressInformerController cache.ControllerInterface
+	// Federated namespaces, used to skip propagation into namespaces that
+	// are being deleted.
+	namespaceInformerStore cache.Store
+	// Informer controller for federated namespaces.
+	namespaceInformerController cache.ControllerInterface
 
 	// Client to federated api server.
 	federatedApiClient federationclientset.Interface
@@ -100,99 +407,928 @@ type IngressController struct {
 	ingressBackoff *flowcontrol.Backoff
 	// Backoff manager for configmaps
 	configMapBackoff *flowcontrol.Backoff
+	// Backoff manager for clusters whose api servers respond with 429.
+	clusterThrottleBackoff *flowcontrol.Backoff
+
+	// Backoff for retries after federation api server errors, kept separate
+	// from the per-object and per-cluster backoffs so an unavailable
+	// federation api server does not distort cluster retry state.
+	federationBackoff *flowcontrol.Backoff
 
 	// For events
 	eventRecorder record.EventRecorder
 
+	// Scoped logger carrying the controller name; per-cluster log sites
+	// derive key- and cluster-scoped loggers from it so every line about a
+	// member cluster carries a grep-friendly cluster token.
+	logger *util.ScopedLogger
+
 	deletionHelper *deletionhelper.DeletionHelper
 
+	// Number of workers draining coalesced finalizer removals during mass
+	// deletions. Zero keeps the historical inline removal path.
+	finalizerRemovalWorkers int
+	// Non-nil only when finalizerRemovalWorkers > 0.
+	finalizerBatcher *finalizerRemovalBatcher
+
 	ingressReviewDelay    time.Duration
 	configMapReviewDelay  time.Duration
 	clusterAvailableDelay time.Duration
 	smallDelay            time.Duration
 	updateTimeout         time.Duration
+
+	// How long to wait before re-checking a cluster whose ingress UID
+	// ConfigMap does not exist yet. The controller never creates this
+	// configmap - the cluster's ingress controller owns it - so absence is
+	// expected on new clusters. Zero falls back to configMapReviewDelay.
+	missingConfigMapRequeue time.Duration
+
+	// Warm-up period after Run during which delete actions are deferred, so
+	// that a transiently-empty informer cache on startup cannot trigger a
+	// cascade delete of cluster ingresses.
+	deleteWarmupPeriod time.Duration
+	// Relist interval for member clusters whose api servers forbid the watch
+	// verb, where the per-cluster informers run in poll-only mode.
+	watchPollInterval time.Duration
+	// Time at which Run was called. Zero until the controller is started.
+	startTime time.Time
+
+	// Controls how spec updates propagate across the member clusters.
+	rolloutStrategy RolloutStrategy
+
+	// Feature gates consulted before alpha, annotation-driven behaviors
+	// (progressive rollout, adoption, canary) take effect.
+	featureGates utilconfig.FeatureGate
+
+	// Additional federation API versions whose ingresses are merged into the
+	// federated ingress informer, canonicalized to extensions/v1beta1.
+	additionalIngressSources []IngressVersionSource
+
+	// Spreads the initial reconciliation backlog over time. Nil means no
+	// throttling; watch-derived deliveries always bypass it.
+	startupRamp *util.StartupRamp
+
+	// Set to 1 (via sync/atomic) once the federated ingress informer has
+	// processed its initial listing; deliveries after that bypass the
+	// startup ramp. Flipped by a poller in Run: the delivery trigger runs
+	// under the informer queue lock, so it must not call HasSynced itself.
+	startupBacklogDrained int32
+
+	// Ingress/annotation pairs for which a gate-disabled event was already
+	// emitted, so each is reported once. Guarded by statusLock.
+	gatedAnnotationWarned sets.String
+
+	// Controls how annotation conflicts with adopted cluster ingresses are
+	// resolved.
+	adoptionConflictPolicy AdoptionConflictPolicy
+
+	// Per-cluster ingress class overrides, keyed by cluster name. Takes
+	// precedence over the ingress-class label on the Cluster object.
+	ingressClassOverrides map[string]string
+
+	// Controller-wide label/annotation propagation policy, overridable per
+	// object via the util.PropagationPolicyAnnotation annotation. Nil means
+	// pass everything through.
+	propagationPolicy *util.PropagationPolicy
+
+	// Per-operation-type retry and timeout budgets used when writing to
+	// member clusters. Zero timeouts fall back to updateTimeout.
+	retryPolicies util.RetryPolicies
+
+	// When true, the lb-pending annotation is maintained on federated
+	// ingresses whose clusters all report an empty loadbalancer status.
+	markLoadBalancerPending bool
+	// Ordering applied when merging per-cluster loadbalancer addresses into
+	// the federated status.
+	lbStatusMergeOrder LBStatusMergeOrder
+	// Maximum number of loadbalancer addresses the federated status carries
+	// after the merge; 0 means unlimited. The first entries in merge order
+	// are kept, so the subset is deterministic.
+	maxStatusIngressEntries int
+
+	// When true, annotations that only exist on a cluster copy, were not
+	// written by federation and are not pending deletion are treated as
+	// cluster-owned and excluded from the equivalence comparison, so
+	// cloud-injected annotations cannot cause update loops.
+	ignoreClusterOwnedAnnotations bool
+
+	// When non-empty, only federated ingresses carrying this annotation key
+	// (with the required value, when one is configured) are managed at all;
+	// the rest are ignored entirely, so that another federation-like system
+	// can own them without the two fighting.
+	ownershipAnnotationKey   string
+	ownershipAnnotationValue string
+
+	// Names of the ingress spec subfields ("backend", "tls", "rules") that
+	// federation manages. Unmanaged subfields are neither propagated nor
+	// reconciled: cluster-local values are preserved. Empty means all
+	// subfields are managed.
+	managedSpecSubfields sets.String
+
+	// How long a first-cluster drain waits for the replacement cluster's
+	// copy to report a loadbalancer address before the leaving cluster's
+	// copy is removed anyway.
+	drainTimeout time.Duration
+	// In-flight first-cluster drains by federated ingress key. Guarded by
+	// drainsLock. The state is in-memory only: after a controller restart a
+	// remaining stale copy is cleaned up by the regular unjoin cleanup.
+	drainsLock    sync.Mutex
+	ingressDrains map[string]ingressDrain
+
+	// Time source for propagation deadline tracking; a fake clock in tests.
+	clock clock.Clock
+	// Tracks when the current generation of each federated ingress was
+	// first seen incompletely propagated, keyed by namespace/name. Kept in
+	// memory only: a restart simply restarts the deadline timers.
+	propagationDeadlinesLock sync.Mutex
+	propagationDeadlines     map[string]propagationDeadlineState
+
+	// Budget within which an out-of-sync ingress must converge on Ready
+	// clusters before the controller alerts; zero disables the check.
+	convergenceBudget time.Duration
+	// Tracks since when each federated ingress has been out of sync with at
+	// least one Ready cluster, keyed by namespace/name.
+	convergenceLock   sync.Mutex
+	convergenceStalls map[string]propagationDeadlineState
+
+	// UID each federated ingress was last reconciled with, keyed by
+	// namespace/name. A changed UID under the same key means the object was
+	// deleted and recreated; the auxiliary state of the previous incarnation
+	// (backoffs, deadline timers, last error) is dropped so the new object
+	// starts fresh instead of inheriting its predecessor's penalties.
+	observedIngressUIDs map[string]types.UID
+
+	// Tolerance applied when ordering wall-clock timestamps stamped by other
+	// processes (cluster condition probe times): timestamps within the
+	// tolerance of each other are treated as concurrent. Zero means exact
+	// comparison. Purely controller-local timing is unaffected; it runs on
+	// the controller's own clock.
+	clockSkewTolerance time.Duration
+
+	// Restricts which member clusters this instance manages. Nil means all
+	// clusters (the unsharded, single-instance mode). See
+	// IngressControllerConfig.ClusterShardSelector.
+	clusterShardSelector labels.Selector
+	// Whether this instance may write to the federated objects themselves:
+	// staged metadata updates (finalizers, coordination annotations) and
+	// status. True unless the instance is a non-writer shard. Cluster-side
+	// writes are unaffected.
+	federatedWritesAllowed bool
+
+	// Hook for reading the loadbalancer status off a cluster copy. Nil means
+	// Status.LoadBalancer is read directly.
+	clusterStatusExtractor ClusterStatusExtractor
+
+	// Decides when a designated primary cluster's copy is established enough
+	// for the secondary clusters to proceed. Nil requires the copy to report
+	// at least one loadbalancer address.
+	primaryReadyPredicate func(*extensionsv1beta1.Ingress) bool
+
+	// Annotation keys whose change forces the cluster's loadbalancer to be
+	// recreated. Updates changing one of these are propagated ahead of any
+	// staggered rollout and announced with a warning event.
+	disruptiveAnnotations sets.String
+
+	// Annotation keys whose values on the existing cluster copy are
+	// re-applied onto the desired object before every update, so values
+	// maintained by the cluster's own ingress controller (e.g. backend
+	// health check configuration) survive federation updates.
+	preservedAnnotations sets.String
+
+	// When true, an ingress-class change on the federated object also strips
+	// the known GCE provider annotations from the cluster copies, so the new
+	// class's controller does not inherit stale GLBC state. Off by default
+	// because the stripped names are the only record of the GCE resources
+	// and removing them can orphan those resources.
+	gcProviderAnnotationsOnClassChange bool
+
+	// Per-cluster circuit breaker consulted by the federated updaters. Nil
+	// when disabled.
+	clusterCircuitBreaker *util.CircuitBreaker
+
+	// If true, a finalizer is kept on every cluster that hosts at least one
+	// federation-managed ingress, preventing accidental cluster deletion from
+	// orphaning cloud load balancers.
+	clusterFinalizerEnabled bool
+	// If true, the delete-from-underlying-clusters finalizer is only added to
+	// a federated ingress once it has successfully been created in at least
+	// one cluster, so an ingress that never propagates cannot get stuck in
+	// deletion. The orphan finalizer is still added up front.
+	deferDeleteFinalizer bool
+	// Normalized api endpoints under which the federation control plane
+	// itself is reachable. Member clusters pointing at one of these are
+	// self-references and excluded from propagation.
+	hostClusterEndpoints sets.String
+
+	// Ring buffer of the most recent reconcile decisions, exposed through
+	// RecentReconciles for post-mortem analysis.
+	reconcileReplayLog *reconcileLog
+
+	// Resource versions of the federated object and its cluster copy at the
+	// last reconciliation that found them equivalent, keyed by federated key
+	// and cluster name. When both versions still match the stored objects,
+	// the per-cluster spec construction and deep comparison are skipped
+	// entirely. In-memory only, so a controller restart starts cold; any
+	// reconcile error invalidates the key. Guarded by syncedVersionsLock.
+	syncedVersionsLock sync.Mutex
+	syncedVersionCache map[string]map[string]syncedVersions
+
+	// The spec federation last wrote to each cluster, keyed by federated key
+	// and cluster name. Read through LastAppliedSpec to diagnose drift:
+	// a live cluster copy whose spec differs from the recorded one was
+	// edited from outside federation. In-memory only, so a controller
+	// restart starts cold. Guarded by lastAppliedLock.
+	lastAppliedLock  sync.Mutex
+	lastAppliedSpecs map[string]map[string]*extensionsv1beta1.IngressSpec
+
+	// Manages per-namespace anchor ConfigMaps in member clusters that
+	// federation-created ingresses point at via ownerReferences.
+	anchorManager *util.AnchorManager
+	// If true, ingresses created in member clusters get an ownerReference to
+	// the per-namespace anchor ConfigMap so that cluster-level GC can chain
+	// dependents off them.
+	ownerReferencesEnabled bool
+
+	// If true the authoritative ingress UID is chosen by explicit pin >
+	// provider-annotated ingresses > oldest cluster creationTimestamp, instead
+	// of whichever annotated cluster happens to be listed first. This protects
+	// the cluster actually holding production load balancer resources after a
+	// federation rebuild.
+	preferOldestClusterUID bool
+
+	// If true (the default), create and update operations for ingresses whose
+	// namespace is Terminating are skipped; the namespace deletion cascade is
+	// left to clean up the per-cluster copies.
+	skipTerminatingNamespaces bool
+
+	// If true, the per-cluster comparison also covers the loadbalancer
+	// status: a cluster copy whose status was externally wiped causes the
+	// federated status to be re-derived from the remaining cluster copies.
+	includeStatusInComparison bool
+
+	// If true, clusters whose latest Ready condition is not ConditionTrue are
+	// a hard stop for writes: queued operations targeting them are dropped
+	// rather than attempted. Reads from the cached cluster state continue.
+	strictReadinessGate bool
+	// Tracks the last time a "skipping not-ready cluster" message was logged
+	// per cluster, to log at most once per notReadySkipLogInterval.
+	notReadySkipLogLock  sync.Mutex
+	notReadySkipLogTimes map[string]time.Time
+
+	// Guards quotaBlocked and quotaBackoff.
+	quotaBlockLock sync.Mutex
+	// (cluster, namespace) pairs whose propagation writes are currently
+	// rejected by an exhausted resource quota, keyed "cluster/namespace";
+	// the value is when the next probe write may go through. While the pair
+	// is inside its window, creates and updates into it are short-circuited;
+	// after the window a single write is let through as a probe, and a
+	// further quota rejection re-arms the block with a longer window.
+	quotaBlocked map[string]time.Time
+	// Spaces the quota probes per (cluster, namespace).
+	quotaBackoff *flowcontrol.Backoff
+
+	// Counts of reconciles deferred because an informer had not synced yet,
+	// keyed by reason. Guarded by unsyncedSkipLock.
+	unsyncedSkipLock sync.Mutex
+	unsyncedSkips    map[string]int64
+
+	// Externally readable reconciliation state, guarded by statusLock.
+	//
+	// Locking strategy: the reconcile loop takes statusLock only for short
+	// assignments, never across an API call, so accessors cannot stall
+	// reconciliation. Accessors copy the data out under the lock, so callers
+	// never share memory with the loop. The informer-backed state exposed by
+	// ManagedClusters needs no extra locking - the informer stores are
+	// thread-safe - only the snapshot is assembled here.
+	statusLock sync.Mutex
+	// Error recorded by the most recent reconciliation that failed to apply
+	// its operations; cleared by the next clean pass.
+	lastReconcileError error
+	// Result of the most recent write per cluster: "ok: <action>" or the
+	// error text.
+	clusterStatuses map[string]string
+	// Federated ingresses currently in retry backoff. Only maintained for
+	// the state store, which persists the set across restarts.
+	backoffKeys sets.String
+	// One-line summary produced by the most recent graceful shutdown, empty
+	// while the controller is running.
+	shutdownSummaryText string
+	// Counts of configmap reconciles requeued because the cluster's ingress
+	// UID ConfigMap did not exist yet, keyed by cluster name.
+	missingConfigMapRequeues map[string]int64
+
+	// stateStore, when set, persists the auxiliary reconcile state across
+	// restarts: loaded once on Run, saved every stateSaveInterval.
+	stateStore        StateStore
+	stateSaveInterval time.Duration
+
+	// PrePropagationValidator, when set, is consulted before every per-cluster
+	// create or update of an ingress. It receives the object as it would be
+	// written to the cluster; a non-nil error blocks propagation to that
+	// cluster only and is surfaced as an event on the federated ingress.
+	PrePropagationValidator func(ingress *extensionsv1beta1.Ingress, cluster string) error
+
+	// When true, cluster ingress creates and updates are written with
+	// server-side apply on clusters whose api server supports it, falling
+	// back to plain create/update elsewhere.
+	useServerSideApply bool
+	// ServerSideApplySupported, when set, overrides the api server version
+	// probe that decides whether a member cluster supports server-side
+	// apply. Must be set before Run; mainly for tests.
+	ServerSideApplySupported func(clientset kubeclientset.Interface) bool
+	// Cached per-clientset results of the server-side apply capability
+	// probe, so each cluster is probed once.
+	ssaSupportLock sync.Mutex
+	ssaSupport     map[kubeclientset.Interface]bool
+
+	// stopChan is the stop channel owned by Start; nil when the controller is
+	// driven through Run with an externally owned channel.
+	stopChan chan struct{}
+	// shutdownDone is closed once the teardown triggered by the stop channel
+	// has finished stopping the informers and deliverers.
+	shutdownDone chan struct{}
+}
+
+// IngressControllerConfig tunes the delays and strategies of the ingress
+// controller. Zero fields keep the defaults.
+type IngressControllerConfig struct {
+	IngressReviewDelay     time.Duration
+	ConfigMapReviewDelay   time.Duration
+	ClusterAvailableDelay  time.Duration
+	SmallDelay             time.Duration
+	UpdateTimeout          time.Duration
+	DeleteWarmupPeriod     time.Duration
+	RolloutStrategy        RolloutStrategy
+	AdoptionConflictPolicy AdoptionConflictPolicy
+	// MissingConfigMapRequeue is how long the controller waits before
+	// re-checking a cluster whose ingress UID ConfigMap does not exist yet.
+	// Zero follows ConfigMapReviewDelay.
+	MissingConfigMapRequeue time.Duration
+	// WatchPollInterval is the relist interval used for member clusters whose
+	// api servers forbid the watch verb. Zero keeps the default.
+	WatchPollInterval time.Duration
+	// ReconcileLogSize sets how many recent reconcile decisions are kept for
+	// RecentReconciles. Zero keeps the default.
+	ReconcileLogSize int
+	// IgnoreClusterOwnedAnnotations excludes annotations that only the
+	// cluster copy carries (and federation never wrote) from the equivalence
+	// comparison.
+	IgnoreClusterOwnedAnnotations bool
+	// ManagedSpecSubfields lists the ingress spec subfields ("backend",
+	// "tls", "rules") federation manages. Unmanaged subfields are neither
+	// propagated nor reconciled, so per-cluster automation (e.g. a local
+	// certificate manager filling in spec.tls) keeps its values. Empty
+	// manages all subfields.
+	ManagedSpecSubfields []string
+	// ConvergenceBudget, when non-zero, is how long a federated ingress may
+	// stay out of sync with a Ready member cluster before the controller
+	// emits a ConvergenceBudgetExceeded warning event and counts it in the
+	// corresponding metric. Unlike the per-object propagation deadline this
+	// targets sync stalls on healthy clusters.
+	ConvergenceBudget time.Duration
+	// DrainTimeout bounds how long a first-cluster drain waits for the
+	// replacement cluster to pick up a loadbalancer address before the
+	// leaving cluster's copy is deleted anyway. Zero keeps the default.
+	DrainTimeout time.Duration
+	// ClockSkewTolerance is applied when comparing wall-clock timestamps
+	// written by other processes, such as cluster condition probe times:
+	// timestamps within the tolerance are treated as concurrent and broken
+	// by append order instead. Zero compares timestamps exactly. Set this
+	// when the hosts running the federation controllers are not tightly
+	// clock-synchronized.
+	ClockSkewTolerance time.Duration
+	// UseServerSideApply writes cluster ingress creates and updates with
+	// server-side apply on clusters whose api server supports it (probed
+	// once per cluster), falling back to plain create/update elsewhere.
+	// Apply lets the server merge by field ownership, so fields owned by
+	// other managers in the cluster are not clobbered - preserve-local
+	// semantics without client-side merging.
+	UseServerSideApply bool
+	// ClusterShardSelector, when non-empty, is a label selector restricting
+	// which member clusters this controller instance manages: informers,
+	// clientsets and propagation only cover matching clusters. Several
+	// controller-manager instances with disjoint selectors can split a very
+	// large federation between them. Exactly one of the instances must run
+	// with ClusterShardFederatedWriter set; the others leave all writes to
+	// the federated object (finalizers, status, coordination annotations)
+	// to it. Malformed selectors are logged and ignored, keeping the
+	// instance unsharded.
+	ClusterShardSelector string
+	// ClusterShardFederatedWriter marks this instance as the shard that
+	// writes to the federated objects themselves. Meaningful only together
+	// with ClusterShardSelector; unsharded instances always write.
+	ClusterShardFederatedWriter bool
+	// FinalizerRemovalWorkers, when positive, coalesces the finalizer
+	// removals of a deletion burst: removals are queued and drained by this
+	// many workers sharing a single backoff, instead of being written inline
+	// by the reconciliation loop. Zero keeps the inline path.
+	FinalizerRemovalWorkers int
+	// FeatureGates overrides the global default gates consulted before the
+	// alpha behaviors above take effect. Mostly useful in tests.
+	FeatureGates utilconfig.FeatureGate
+	// AdditionalIngressSources lists further federation API versions to
+	// watch for federated ingresses, merged with the primary version during
+	// upgrades of the federation control plane. Must be set at construction
+	// time (via IngressControllerOptions.Config) to take effect.
+	AdditionalIngressSources []IngressVersionSource
+	// InitialSyncKeysPerSecond, when positive, throttles the initial
+	// reconciliation backlog to at most this many federated ingresses per
+	// second, in deterministic namespace/name order. Watch-derived events
+	// bypass the ramp. Must be set at construction time to take effect.
+	InitialSyncKeysPerSecond int
+	// IngressClassOverrides maps cluster names to the ingress class stamped
+	// on that cluster's copies, for clusters whose default class differs.
+	IngressClassOverrides map[string]string
+	// PropagationPolicy restricts which label and annotation keys are
+	// propagated onto cluster copies. Nil passes everything through.
+	PropagationPolicy *util.PropagationPolicy
+	// OwnershipAnnotation, when non-empty, limits federation to ingresses
+	// carrying the given annotation, expressed as "key" or "key=value".
+	// Ingresses without it are ignored entirely - no finalizers are added
+	// and nothing is propagated - so another federation-like system can
+	// manage them without interference.
+	OwnershipAnnotation string
+	// EnableExpvarMetrics additionally publishes the cluster action counters
+	// via expvar, for deployments that do not scrape Prometheus.
+	EnableExpvarMetrics bool
+	// PropagateToTerminatingNamespaces disables the default skipping of
+	// create/update operations for ingresses whose namespace is Terminating.
+	PropagateToTerminatingNamespaces bool
+	// IncludeStatusInComparison extends the per-cluster comparison to the
+	// loadbalancer status, re-deriving the federated status when a cluster
+	// copy's status was externally wiped.
+	IncludeStatusInComparison bool
+	// RetryPolicies sets per-operation-type retry and timeout budgets for
+	// writes to member clusters. Zero timeouts fall back to UpdateTimeout.
+	RetryPolicies util.RetryPolicies
+	// MarkLoadBalancerPending maintains the lb-pending annotation on
+	// federated ingresses whose clusters all report an empty loadbalancer
+	// status.
+	MarkLoadBalancerPending bool
+	// LBStatusMergeOrder selects the ordering of merged loadbalancer
+	// addresses in the federated status. Empty keeps the default (Sorted).
+	LBStatusMergeOrder LBStatusMergeOrder
+	// MaxStatusIngressEntries caps how many loadbalancer addresses the
+	// merged federated status carries, keeping the first entries in merge
+	// order; 0 means unlimited. Truncation is noted on the federated object
+	// via the status-truncated annotation. Protects the federated object
+	// size in setups with very many clusters.
+	MaxStatusIngressEntries int
+	// DeferDeleteFinalizer delays adding the delete-from-underlying-clusters
+	// finalizer until the ingress has been created in at least one cluster.
+	DeferDeleteFinalizer bool
+	// HostClusterEndpoints lists api endpoints under which the federation
+	// control plane itself is reachable. A member cluster whose server
+	// address matches one of them would make the controller propagate into
+	// the API surface it watches; such clusters are marked with the
+	// SelfReference condition and skipped.
+	HostClusterEndpoints []string
+	// ClusterStatusExtractor normalizes the loadbalancer status reported by
+	// cluster copies before aggregation, for clouds that encode status in a
+	// nonstandard place. Nil reads Status.LoadBalancer directly.
+	ClusterStatusExtractor ClusterStatusExtractor
+	// PrimaryReadyPredicate decides when the copy in a cluster designated by
+	// the primary-cluster annotation is established enough for the secondary
+	// clusters' copies to be created or updated. Nil requires the primary
+	// copy to report at least one loadbalancer address.
+	PrimaryReadyPredicate func(*extensionsv1beta1.Ingress) bool
+	// DisruptiveAnnotations lists annotation keys whose change forces the
+	// cluster's loadbalancer to be recreated (e.g. an SSL policy). Updates
+	// changing one of them bypass the staggered rollout deferral and are
+	// announced with a warning event.
+	DisruptiveAnnotations []string
+	// PreservedAnnotations lists annotation keys that are cluster-local:
+	// their values on the existing cluster copy are re-applied onto the
+	// desired object before every update, so per-cluster configuration set
+	// by the cluster's own ingress controller survives federation updates.
+	PreservedAnnotations []string
+	// GCProviderAnnotationsOnClassChange strips the known GCE provider
+	// annotations (forwarding rule, url-map etc.) from cluster copies when
+	// the ingress class of the federated object changes, so the new class's
+	// controller does not act on stale GLBC state. Off by default because
+	// removing the annotations can orphan the named GCE resources.
+	GCProviderAnnotationsOnClassChange bool
+	// CircuitBreakerFailureThreshold enables a per-cluster circuit breaker
+	// when positive: after that many consecutive failed writes to a cluster,
+	// further writes fail fast for CircuitBreakerCoolingPeriod before a
+	// probe is attempted.
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerCoolingPeriod    time.Duration
+
+	// DisableUIDReconciliation turns off the ingress-uid ConfigMap machinery
+	// entirely: the per-cluster ConfigMap informers are never started and the
+	// UID, first-cluster and static IP coordination is skipped, leaving pure
+	// spec propagation. For federations whose member clusters run ingress
+	// controllers (e.g. nginx) that have no such ConfigMap.
+	DisableUIDReconciliation bool
+
+	// StateStore, when set, persists auxiliary reconcile state (retry
+	// backoffs, the last error, quarantined clusters) outside the process so
+	// a restarted or newly elected leader resumes without a cold start.
+	// Nil keeps the state in memory only.
+	StateStore StateStore
+	// StateSaveInterval overrides how often the state store is written.
+	// Defaults to one minute.
+	StateSaveInterval time.Duration
+}
+
+// ingressesByKey orders ingresses by namespace, then name, for deterministic
+// replay of the initial sync backlog.
+type ingressesByKey []extensionsv1beta1.Ingress
+
+func (list ingressesByKey) Len() int      { return len(list) }
+func (list ingressesByKey) Swap(i, j int) { list[i], list[j] = list[j], list[i] }
+func (list ingressesByKey) Less(i, j int) bool {
+	if list[i].Namespace != list[j].Namespace {
+		return list[i].Namespace < list[j].Namespace
+	}
+	return list[i].Name < list[j].Name
+}
+
+// sortedIngressListerWatcher sorts every list result by namespace and name so
+// that the controller replays its startup backlog in a deterministic order
+// instead of random map order. Watches pass through untouched.
+type sortedIngressListerWatcher struct {
+	delegate cache.ListerWatcher
+}
+
+func newSortedIngressListerWatcher(delegate cache.ListerWatcher) cache.ListerWatcher {
+	return &sortedIngressListerWatcher{delegate: delegate}
+}
+
+func (sw *sortedIngressListerWatcher) List(options v1.ListOptions) (pkgruntime.Object, error) {
+	obj, err := sw.delegate.List(options)
+	if err != nil {
+		return nil, err
+	}
+	if list, ok := obj.(*extensionsv1beta1.IngressList); ok {
+		sort.Sort(ingressesByKey(list.Items))
+	}
+	return obj, nil
+}
+
+func (sw *sortedIngressListerWatcher) Watch(options v1.ListOptions) (watch.Interface, error) {
+	return sw.delegate.Watch(options)
+}
+
+// IngressVersionSource describes one additional federation API version under
+// which federated ingresses are served, typically while the control plane is
+// being upgraded. Objects from all sources are canonicalized to
+// extensions/v1beta1 and reconciled from a single informer.
+type IngressVersionSource struct {
+	// Version names the API version, used in log and error messages.
+	Version string
+	// ListerWatcher lists and watches the ingresses served under Version.
+	ListerWatcher cache.ListerWatcher
+	// Canonicalize converts an object returned by ListerWatcher to the
+	// extensions/v1beta1 form the controller reconciles. Nil accepts
+	// *extensionsv1beta1.Ingress objects as-is.
+	Canonicalize func(obj pkgruntime.Object) (*extensionsv1beta1.Ingress, error)
+}
+
+// newMultiVersionIngressListerWatcher merges the primary federated ingress
+// source with any additional version sources. With no additional sources the
+// primary is returned unwrapped.
+func newMultiVersionIngressListerWatcher(primary cache.ListerWatcher, sources []IngressVersionSource) cache.ListerWatcher {
+	if len(sources) == 0 {
+		return primary
+	}
+	return &multiVersionIngressListerWatcher{primary: primary, sources: sources}
+}
+
+// multiVersionIngressListerWatcher lists and watches federated ingresses
+// across several API versions of the same control plane. All versions are
+// backed by the same storage, so resource versions are comparable and an
+// object served under several versions at once only yields duplicate events
+// for the same canonical key, which reconciliation absorbs.
+type multiVersionIngressListerWatcher struct {
+	primary cache.ListerWatcher
+	sources []IngressVersionSource
+}
+
+func (mv *multiVersionIngressListerWatcher) List(options v1.ListOptions) (pkgruntime.Object, error) {
+	primaryObj, err := mv.primary.List(options)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := primaryObj.(*extensionsv1beta1.IngressList)
+	if !ok {
+		return nil, fmt.Errorf("internal error: primary ingress list is a %T, not an IngressList", primaryObj)
+	}
+	seen := sets.NewString()
+	for i := range list.Items {
+		seen.Insert(types.NamespacedName{Namespace: list.Items[i].Namespace, Name: list.Items[i].Name}.String())
+	}
+	for _, source := range mv.sources {
+		sourceObj, err := source.ListerWatcher.List(options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list federated ingresses from version %s: %v", source.Version, err)
+		}
+		items, err := meta.ExtractList(sourceObj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract ingress list from version %s: %v", source.Version, err)
+		}
+		for _, item := range items {
+			ingress, err := canonicalizeIngress(source, item)
+			if err != nil {
+				return nil, err
+			}
+			key := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}.String()
+			if seen.Has(key) {
+				// The primary version serves this object already.
+				continue
+			}
+			seen.Insert(key)
+			list.Items = append(list.Items, *ingress)
+		}
+	}
+	return list, nil
+}
+
+func (mv *multiVersionIngressListerWatcher) Watch(options v1.ListOptions) (watch.Interface, error) {
+	watches := make([]watch.Interface, 0, len(mv.sources)+1)
+	stopAll := func() {
+		for _, w := range watches {
+			w.Stop()
+		}
+	}
+	primaryWatch, err := mv.primary.Watch(options)
+	if err != nil {
+		return nil, err
+	}
+	watches = append(watches, primaryWatch)
+	mux := &ingressWatchMux{
+		result:  make(chan watch.Event),
+		stop:    make(chan struct{}),
+		watches: watches,
+	}
+	mux.forward(IngressVersionSource{}, primaryWatch)
+	for _, source := range mv.sources {
+		sourceWatch, err := source.ListerWatcher.Watch(options)
+		if err != nil {
+			stopAll()
+			return nil, fmt.Errorf("failed to watch federated ingresses from version %s: %v", source.Version, err)
+		}
+		mux.watches = append(mux.watches, sourceWatch)
+		mux.forward(source, sourceWatch)
+	}
+	go func() {
+		mux.wg.Wait()
+		close(mux.result)
+	}()
+	return mux, nil
+}
+
+// canonicalizeIngress applies a source's conversion to one of its objects.
+func canonicalizeIngress(source IngressVersionSource, obj pkgruntime.Object) (*extensionsv1beta1.Ingress, error) {
+	if source.Canonicalize != nil {
+		ingress, err := source.Canonicalize(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to canonicalize ingress from version %s: %v", source.Version, err)
+		}
+		return ingress, nil
+	}
+	ingress, ok := obj.(*extensionsv1beta1.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("version %s served a %T, not an Ingress, and has no Canonicalize function", source.Version, obj)
+	}
+	return ingress, nil
+}
+
+// ingressWatchMux funnels the events of several per-version watches into a
+// single watch.Interface, canonicalizing objects as they pass through.
+type ingressWatchMux struct {
+	result chan watch.Event
+	stop   chan struct{}
+
+	stopOnce sync.Once
+	watches  []watch.Interface
+	wg       sync.WaitGroup
+}
+
+func (m *ingressWatchMux) forward(source IngressVersionSource, w watch.Interface) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				if event.Type != watch.Error && event.Object != nil && (source.Canonicalize != nil || source.Version != "") {
+					ingress, err := canonicalizeIngress(source, event.Object)
+					if err != nil {
+						glog.Errorf("Dropping watch event: %v", err)
+						continue
+					}
+					event.Object = ingress
+				}
+				select {
+				case m.result <- event:
+				case <-m.stop:
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (m *ingressWatchMux) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		for _, w := range m.watches {
+			w.Stop()
+		}
+	})
+}
+
+func (m *ingressWatchMux) ResultChan() <-chan watch.Event {
+	return m.result
+}
+
+// IngressControllerOptions carries the dependencies of the ingress controller
+// for embedding it in other binaries.
+type IngressControllerOptions struct {
+	// FedClient is the federation API client. Required.
+	FedClient federationclientset.Interface
+	// ClientFactory, when non-nil, overrides how per-cluster clientsets are
+	// built from Cluster objects (e.g. to inject fakes or custom transports).
+	ClientFactory func(*federationapi.Cluster) (kubeclientset.Interface, error)
+	// Config, when non-nil, overrides the default delays and strategies.
+	Config *IngressControllerConfig
+	// Clock, when non-nil, overrides the controller's time source, e.g.
+	// with a fake clock in tests.
+	Clock clock.Clock
 }
 
-// NewIngressController returns a new ingress controller
+// NewIngressController returns a new ingress controller with the default
+// configuration. It is a convenience wrapper around
+// NewIngressControllerWithOptions.
 func NewIngressController(client federationclientset.Interface) *IngressController {
+	return NewIngressControllerWithOptions(IngressControllerOptions{FedClient: client})
+}
+
+// NewIngressControllerWithOptions returns a new ingress controller built from
+// pre-supplied dependencies. The controller does not spawn any goroutines
+// until Run or Start is called.
+func NewIngressControllerWithOptions(options IngressControllerOptions) *IngressController {
+	client := options.FedClient
 	glog.V(4).Infof("->NewIngressController V(4)")
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartRecordingToSink(eventsink.NewFederatedEventSink(client))
 	recorder := broadcaster.NewRecorder(v1.EventSource{Component: "federated-ingress-controller"})
 	ic := &IngressController{
-		federatedApiClient:    client,
-		ingressReviewDelay:    time.Second * 10,
-		configMapReviewDelay:  time.Second * 10,
-		clusterAvailableDelay: time.Second * 20,
-		smallDelay:            time.Second * 3,
-		updateTimeout:         time.Second * 30,
-		ingressBackoff:        flowcontrol.NewBackOff(5*time.Second, time.Minute),
-		eventRecorder:         recorder,
-		configMapBackoff:      flowcontrol.NewBackOff(5*time.Second, time.Minute),
+		federatedApiClient:        client,
+		ingressReviewDelay:        time.Second * 10,
+		configMapReviewDelay:      time.Second * 10,
+		clusterAvailableDelay:     time.Second * 20,
+		smallDelay:                time.Second * 3,
+		updateTimeout:             time.Second * 30,
+		deleteWarmupPeriod:        time.Second * 30,
+		ingressBackoff:            flowcontrol.NewBackOff(5*time.Second, time.Minute),
+		stateSaveInterval:         time.Minute,
+		eventRecorder:             recorder,
+		logger:                    util.NewScopedLogger(ControllerName),
+		configMapBackoff:          flowcontrol.NewBackOff(5*time.Second, time.Minute),
+		clusterThrottleBackoff:    flowcontrol.NewBackOff(5*time.Second, 5*time.Minute),
+		federationBackoff:         flowcontrol.NewBackOff(5*time.Second, time.Minute),
+		watchPollInterval:         time.Minute,
+		lbStatusMergeOrder:        LBStatusMergeSorted,
+		reconcileReplayLog:        newReconcileLog(defaultReconcileLogSize),
+		managedSpecSubfields:      sets.NewString(),
+		drainTimeout:              5 * time.Minute,
+		ingressDrains:             make(map[string]ingressDrain),
+		clock:                     clock.RealClock{},
+		propagationDeadlines:      make(map[string]propagationDeadlineState),
+		convergenceStalls:         make(map[string]propagationDeadlineState),
+		rolloutStrategy:           RolloutStrategy{Type: RolloutStrategyImmediate},
+		featureGates:              utilconfig.DefaultFeatureGate,
+		adoptionConflictPolicy:    AdoptionConflictOverwrite,
+		federatedWritesAllowed:    true,
+		strictReadinessGate:       true,
+		skipTerminatingNamespaces: true,
+		notReadySkipLogTimes:      make(map[string]time.Time),
+		quotaBlocked:              make(map[string]time.Time),
+		quotaBackoff:              flowcontrol.NewBackOff(30*time.Second, 10*time.Minute),
+		syncedVersionCache:        make(map[string]map[string]syncedVersions),
+		lastAppliedSpecs:          make(map[string]map[string]*extensionsv1beta1.IngressSpec),
+		observedIngressUIDs:       make(map[string]types.UID),
+	}
+	if options.Config != nil {
+		ic.applyConfig(options.Config)
+	}
+	if options.Clock != nil {
+		ic.clock = options.Clock
 	}
 
 	// Build deliverers for triggering reconciliations.
 	ic.ingressDeliverer = util.NewDelayingDeliverer()
+	ic.reconcileBuffer = newReconcileBuffer()
 	ic.clusterDeliverer = util.NewDelayingDeliverer()
 	ic.configMapDeliverer = util.NewDelayingDeliverer()
 
 	// Start informer in federated API servers on ingresses that should be federated.
 	ic.ingressInformerStore, ic.ingressInformerController = cache.NewInformer(
-		&cache.ListWatch{
+		newSortedIngressListerWatcher(newMultiVersionIngressListerWatcher(&cache.ListWatch{
 			ListFunc: func(options v1.ListOptions) (pkgruntime.Object, error) {
 				return client.Extensions().Ingresses(api.NamespaceAll).List(options)
 			},
 			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 				return client.Extensions().Ingresses(api.NamespaceAll).Watch(options)
 			},
-		},
+		}, ic.additionalIngressSources)),
 		&extensionsv1beta1.Ingress{},
 		controller.NoResyncPeriodFunc(),
 		util.NewTriggerOnAllChanges(
 			func(obj pkgruntime.Object) {
-				ic.deliverIngressObj(obj, 0, false)
+				delay := time.Duration(0)
+				// Spread the initial backlog; once the informer has synced,
+				// deliveries are watch-derived and bypass the ramp. The
+				// informer queue lock is held while this trigger runs, so
+				// the synced check reads a flag flipped by Run rather than
+				// calling HasSynced, which takes the same lock.
+				if atomic.LoadInt32(&ic.startupBacklogDrained) == 0 {
+					delay = ic.startupRamp.NextDelay()
+				}
+				ic.deliverIngressObj(obj, delay, false)
 			},
 		))
 
-	// Federated informer on ingresses in members of federation.
-	ic.ingressFederatedInformer = util.NewFederatedInformer(
+	// Informer on federated namespaces, to observe Terminating namespaces
+	// without issuing live reads on every reconciliation.
+	ic.namespaceInformerStore, ic.namespaceInformerController = cache.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (pkgruntime.Object, error) {
+				return client.Core().Namespaces().List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				return client.Core().Namespaces().Watch(options)
+			},
+		},
+		&v1.Namespace{},
+		controller.NoResyncPeriodFunc(),
+		&cache.ResourceEventHandlerFuncs{})
+
+	// Federated informer on ingresses in members of federation. A nil shard
+	// selector covers all clusters.
+	ic.ingressFederatedInformer = util.NewShardedFederatedInformer(
 		client,
 		func(cluster *federationapi.Cluster, targetClient kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+			clusterName := cluster.Name
 			return cache.NewInformer(
-				&cache.ListWatch{
+				// Restricted clusters may forbid the watch verb for the
+				// federation credentials; those degrade to poll-only mode
+				// instead of a relist loop with errors.
+				util.NewWatchFallbackListerWatcher("ingresses", clusterName, &cache.ListWatch{
 					ListFunc: func(options v1.ListOptions) (pkgruntime.Object, error) {
 						return targetClient.Extensions().Ingresses(api.NamespaceAll).List(options)
 					},
 					WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
 						return targetClient.Extensions().Ingresses(api.NamespaceAll).Watch(options)
 					},
-				},
+				}, ic.watchPollInterval, func(pollOnly bool) {
+					ic.setClusterWatchDegradedCondition(clusterName, pollOnly)
+				}),
 				&extensionsv1beta1.Ingress{},
 				controller.NoResyncPeriodFunc(),
 				// Trigger reconciliation whenever something in federated cluster is changed. In most cases it
 				// would be just confirmation that some ingress operation succeeded.
-				util.NewTriggerOnAllChanges(
-					func(obj pkgruntime.Object) {
-						ic.deliverIngressObj(obj, ic.ingressReviewDelay, false)
-					},
-				))
+				util.InstrumentResourceEventHandlers("ingresses", cluster.Name,
+					util.NewTriggerOnAllChanges(
+						func(obj pkgruntime.Object) {
+							ic.deliverIngressObj(obj, ic.ingressReviewDelay, false)
+						},
+					)))
 		},
-
+		ic.clusterShardSelector,
 		&util.ClusterLifecycleHandlerFuncs{
 			ClusterAvailable: func(cluster *federationapi.Cluster) {
 				// When new cluster becomes available process all the ingresses again, and configure it's ingress controller's configmap with the correct UID
 				ic.clusterDeliverer.DeliverAfter(cluster.Name, cluster, ic.clusterAvailableDelay)
 			},
+			ClusterUnavailable: func(cluster *federationapi.Cluster, _ []interface{}) {
+				util.DeleteStoreMetricsForCluster("ingresses", cluster.Name)
+			},
 		},
 	)
 
+	// Index cluster ingress copies by presence of the provider static IP
+	// annotation, so that UID authority election does not have to scan every
+	// cached object.
+	if err := ic.ingressFederatedInformer.GetTargetStore().AddIndexer(providerStaticIPIndex, providerStaticIPIndexFunc); err != nil {
+		glog.Errorf("Failed to register the provider static IP index: %v", err)
+	}
+
 	// Federated informer on configmaps for ingress controllers in members of the federation.
-	ic.configMapFederatedInformer = util.NewFederatedInformer(
+	ic.configMapFederatedInformer = util.NewShardedFederatedInformer(
 		client,
 		func(cluster *federationapi.Cluster, targetClient kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
 			glog.V(4).Infof("Returning new informer for cluster %q", cluster.Name)
 			return cache.NewInformer(
-				&cache.ListWatch{
+				util.NewWatchFallbackListerWatcher("configmaps", cluster.Name, &cache.ListWatch{
 					ListFunc: func(options v1.ListOptions) (pkgruntime.Object, error) {
 						if targetClient == nil {
 							glog.Errorf("Internal error: targetClient is nil")
@@ -205,29 +1341,82 @@ func NewIngressController(client federationclientset.Interface) *IngressControll
 						}
 						return targetClient.Core().ConfigMaps(uidConfigMapNamespace).Watch(options) // as above
 					},
-				},
+				}, ic.watchPollInterval, nil),
 				&v1.ConfigMap{},
 				controller.NoResyncPeriodFunc(),
 				// Trigger reconcilation whenever the ingress controller's configmap in a federated cluster is changed. In most cases it
 				// would be just confirmation that the configmap for the ingress controller is correct.
-				util.NewTriggerOnAllChanges(
-					func(obj pkgruntime.Object) {
-						ic.deliverConfigMapObj(cluster.Name, obj, ic.configMapReviewDelay, false)
-					},
-				))
+				util.InstrumentResourceEventHandlers("configmaps", cluster.Name,
+					util.NewTriggerOnAllChanges(
+						func(obj pkgruntime.Object) {
+							ic.deliverConfigMapObj(cluster.Name, obj, ic.configMapReviewDelay, false)
+						},
+					)))
 		},
-
+		ic.clusterShardSelector,
 		&util.ClusterLifecycleHandlerFuncs{
 			ClusterAvailable: func(cluster *federationapi.Cluster) {
 				ic.clusterDeliverer.DeliverAfter(cluster.Name, cluster, ic.clusterAvailableDelay)
 			},
+			ClusterUnavailable: func(cluster *federationapi.Cluster, _ []interface{}) {
+				util.DeleteStoreMetricsForCluster("configmaps", cluster.Name)
+			},
+		},
+	)
+
+	// Federated informer on the backend services referenced by federated
+	// ingresses, for the GLBC NodePort consistency check. Deliberately not
+	// part of syncedState: the check is advisory and a not-yet-synced
+	// service store merely means no mismatch is detected yet.
+	ic.serviceFederatedInformer = util.NewShardedFederatedInformer(
+		client,
+		func(cluster *federationapi.Cluster, targetClient kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+			glog.V(4).Infof("Returning new service informer for cluster %q", cluster.Name)
+			return cache.NewInformer(
+				util.NewWatchFallbackListerWatcher("services", cluster.Name, &cache.ListWatch{
+					ListFunc: func(options v1.ListOptions) (pkgruntime.Object, error) {
+						if targetClient == nil {
+							return nil, fmt.Errorf("no clientset for cluster %q", cluster.Name)
+						}
+						return targetClient.Core().Services(v1.NamespaceAll).List(options)
+					},
+					WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+						if targetClient == nil {
+							return nil, fmt.Errorf("no clientset for cluster %q", cluster.Name)
+						}
+						return targetClient.Core().Services(v1.NamespaceAll).Watch(options)
+					},
+				}, ic.watchPollInterval, nil),
+				&v1.Service{},
+				controller.NoResyncPeriodFunc(),
+				// Re-check the ingresses routing to the service whenever it
+				// changes, so a NodePort edit in one cluster surfaces (or
+				// clears) the mismatch condition promptly.
+				util.InstrumentResourceEventHandlers("services", cluster.Name,
+					util.NewTriggerOnAllChanges(
+						func(obj pkgruntime.Object) {
+							ic.deliverIngressesReferencingService(obj)
+						},
+					)))
+		},
+		ic.clusterShardSelector,
+		&util.ClusterLifecycleHandlerFuncs{
+			ClusterUnavailable: func(cluster *federationapi.Cluster, _ []interface{}) {
+				util.DeleteStoreMetricsForCluster("services", cluster.Name)
+			},
 		},
 	)
 
 	// Federated ingress updater along with Create/Update/Delete operations.
-	ic.federatedIngressUpdater = util.NewFederatedUpdater(ic.ingressFederatedInformer,
+	ic.federatedIngressUpdater = util.NewFederatedUpdaterWithCircuitBreaker(ic.ingressFederatedInformer, ic.clusterCircuitBreaker,
 		func(client kubeclientset.Interface, obj pkgruntime.Object) error {
 			ingress := obj.(*extensionsv1beta1.Ingress)
+			if handled, err := ic.applyIngress(client, ingress); handled {
+				if err != nil {
+					glog.Errorf("Error applying ingress %q: %v", types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}, err)
+				}
+				return err
+			}
 			glog.V(4).Infof("Attempting to create Ingress: %v", ingress)
 			_, err := client.Extensions().Ingresses(ingress.Namespace).Create(ingress)
 			if err != nil {
@@ -239,6 +1428,12 @@ func NewIngressController(client federationclientset.Interface) *IngressControll
 		},
 		func(client kubeclientset.Interface, obj pkgruntime.Object) error {
 			ingress := obj.(*extensionsv1beta1.Ingress)
+			if handled, err := ic.applyIngress(client, ingress); handled {
+				if err != nil {
+					glog.V(4).Infof("Failed to apply Ingress: %v", err)
+				}
+				return err
+			}
 			glog.V(4).Infof("Attempting to update Ingress: %v", ingress)
 			_, err := client.Extensions().Ingresses(ingress.Namespace).Update(ingress)
 			if err != nil {
@@ -256,7 +1451,7 @@ func NewIngressController(client federationclientset.Interface) *IngressControll
 		})
 
 	// Federated configmap updater along with Create/Update/Delete operations.  Only Update should ever be called.
-	ic.federatedConfigMapUpdater = util.NewFederatedUpdater(ic.configMapFederatedInformer,
+	ic.federatedConfigMapUpdater = util.NewFederatedUpdaterWithCircuitBreaker(ic.configMapFederatedInformer, ic.clusterCircuitBreaker,
 		func(client kubeclientset.Interface, obj pkgruntime.Object) error {
 			configMap := obj.(*v1.ConfigMap)
 			configMapName := types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}
@@ -284,9 +1479,42 @@ func NewIngressController(client federationclientset.Interface) *IngressControll
 			return err
 		})
 
+	// Federated service updater used only to align backend service NodePorts
+	// behind the FederationNodePortRewrite gate. Only Update should ever be called.
+	ic.federatedServiceUpdater = util.NewFederatedUpdaterWithCircuitBreaker(ic.serviceFederatedInformer, ic.clusterCircuitBreaker,
+		func(client kubeclientset.Interface, obj pkgruntime.Object) error {
+			service := obj.(*v1.Service)
+			glog.Errorf("Internal error: Incorrectly attempting to create Service: %q", types.NamespacedName{Name: service.Name, Namespace: service.Namespace})
+			_, err := client.Core().Services(service.Namespace).Create(service)
+			return err
+		},
+		func(client kubeclientset.Interface, obj pkgruntime.Object) error {
+			service := obj.(*v1.Service)
+			serviceName := types.NamespacedName{Name: service.Name, Namespace: service.Namespace}
+			glog.V(4).Infof("Attempting to update Service: %v", service)
+			_, err := client.Core().Services(service.Namespace).Update(service)
+			if err == nil {
+				glog.V(4).Infof("Successfully updated Service %q", serviceName)
+			} else {
+				glog.V(4).Infof("Failed to update Service %q: %v", serviceName, err)
+			}
+			return err
+		},
+		func(client kubeclientset.Interface, obj pkgruntime.Object) error {
+			service := obj.(*v1.Service)
+			glog.Errorf("Internal error: Incorrectly attempting to delete Service: %q", types.NamespacedName{Name: service.Name, Namespace: service.Namespace})
+			err := client.Core().Services(service.Namespace).Delete(service.Name, &v1.DeleteOptions{})
+			return err
+		})
+
+	ic.anchorManager = util.NewAnchorManager(ic.ingressFederatedInformer)
+
+	if ic.finalizerRemovalWorkers > 0 {
+		ic.finalizerBatcher = newFinalizerRemovalBatcher(ic.finalizerRemovalWorkers, ic.clock, ic.removeFederationFinalizers)
+	}
 	ic.deletionHelper = deletionhelper.NewDeletionHelper(
 		ic.hasFinalizerFunc,
-		ic.removeFinalizerFunc,
+		ic.batchedRemoveFinalizerFunc,
 		ic.addFinalizerFunc,
 		// objNameFunc
 		func(obj pkgruntime.Object) string {
@@ -298,82 +1526,534 @@ func NewIngressController(client federationclientset.Interface) *IngressControll
 		ic.ingressFederatedInformer,
 		ic.federatedIngressUpdater,
 	)
+	if options.ClientFactory != nil {
+		ic.setClientFactory(options.ClientFactory)
+	}
 	return ic
 }
 
-// Returns true if the given object has the given finalizer in its ObjectMeta.
-func (ic *IngressController) hasFinalizerFunc(obj pkgruntime.Object, finalizer string) bool {
-	ingress := obj.(*extensionsv1beta1.Ingress)
-	for i := range ingress.ObjectMeta.Finalizers {
-		if string(ingress.ObjectMeta.Finalizers[i]) == finalizer {
-			return true
-		}
+// applyConfig copies the non-zero fields of the given config onto the
+// controller. Must be called before the informers and helpers are built.
+func (ic *IngressController) applyConfig(config *IngressControllerConfig) {
+	if config.IngressReviewDelay != 0 {
+		ic.ingressReviewDelay = config.IngressReviewDelay
 	}
-	return false
-}
-
-// Removes the finalizer from the given objects ObjectMeta.
-// Assumes that the given object is a ingress.
-func (ic *IngressController) removeFinalizerFunc(obj pkgruntime.Object, finalizer string) (pkgruntime.Object, error) {
-	ingress := obj.(*extensionsv1beta1.Ingress)
-	newFinalizers := []string{}
-	hasFinalizer := false
-	for i := range ingress.ObjectMeta.Finalizers {
-		if string(ingress.ObjectMeta.Finalizers[i]) != finalizer {
-			newFinalizers = append(newFinalizers, ingress.ObjectMeta.Finalizers[i])
-		} else {
-			hasFinalizer = true
-		}
+	if config.ConfigMapReviewDelay != 0 {
+		ic.configMapReviewDelay = config.ConfigMapReviewDelay
 	}
-	if !hasFinalizer {
-		// Nothing to do.
-		return obj, nil
+	if config.MissingConfigMapRequeue != 0 {
+		ic.missingConfigMapRequeue = config.MissingConfigMapRequeue
 	}
-	ingress.ObjectMeta.Finalizers = newFinalizers
-	ingress, err := ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Update(ingress)
-	if err != nil {
-		return nil, fmt.Errorf("failed to remove finalizer %s from ingress %s: %v", finalizer, ingress.Name, err)
+	if config.ClusterAvailableDelay != 0 {
+		ic.clusterAvailableDelay = config.ClusterAvailableDelay
 	}
-	return ingress, nil
-}
-
-// Adds the given finalizer to the given objects ObjectMeta.
+	if config.SmallDelay != 0 {
+		ic.smallDelay = config.SmallDelay
+	}
+	if config.UpdateTimeout != 0 {
+		ic.updateTimeout = config.UpdateTimeout
+	}
+	if config.DeleteWarmupPeriod != 0 {
+		ic.deleteWarmupPeriod = config.DeleteWarmupPeriod
+	}
+	if config.WatchPollInterval != 0 {
+		ic.watchPollInterval = config.WatchPollInterval
+	}
+	if config.ReconcileLogSize > 0 {
+		ic.reconcileReplayLog = newReconcileLog(config.ReconcileLogSize)
+	}
+	if config.IgnoreClusterOwnedAnnotations {
+		ic.ignoreClusterOwnedAnnotations = true
+	}
+	if len(config.ManagedSpecSubfields) > 0 {
+		ic.managedSpecSubfields = sets.NewString(config.ManagedSpecSubfields...)
+	}
+	if config.ConvergenceBudget > 0 {
+		ic.convergenceBudget = config.ConvergenceBudget
+	}
+	if config.DrainTimeout > 0 {
+		ic.drainTimeout = config.DrainTimeout
+	}
+	if config.ClockSkewTolerance > 0 {
+		ic.clockSkewTolerance = config.ClockSkewTolerance
+	}
+	if config.UseServerSideApply {
+		ic.useServerSideApply = true
+	}
+	if config.ClusterShardSelector != "" {
+		if selector, err := labels.Parse(config.ClusterShardSelector); err != nil {
+			glog.Errorf("Ignoring malformed cluster shard selector %q, managing all clusters: %v", config.ClusterShardSelector, err)
+		} else {
+			ic.clusterShardSelector = selector
+			ic.federatedWritesAllowed = config.ClusterShardFederatedWriter
+		}
+	}
+	if config.FinalizerRemovalWorkers > 0 {
+		ic.finalizerRemovalWorkers = config.FinalizerRemovalWorkers
+	}
+	if config.RolloutStrategy.Type != "" {
+		ic.rolloutStrategy = config.RolloutStrategy
+	}
+	if config.AdoptionConflictPolicy != "" {
+		ic.adoptionConflictPolicy = config.AdoptionConflictPolicy
+	}
+	if config.FeatureGates != nil {
+		ic.featureGates = config.FeatureGates
+	}
+	if len(config.AdditionalIngressSources) > 0 {
+		ic.additionalIngressSources = config.AdditionalIngressSources
+	}
+	if config.InitialSyncKeysPerSecond > 0 {
+		ic.startupRamp = util.NewStartupRamp(config.InitialSyncKeysPerSecond, clock.RealClock{})
+	}
+	if len(config.IngressClassOverrides) > 0 {
+		ic.ingressClassOverrides = config.IngressClassOverrides
+	}
+	if config.PropagationPolicy != nil {
+		ic.propagationPolicy = config.PropagationPolicy
+	}
+	if config.OwnershipAnnotation != "" {
+		parts := strings.SplitN(config.OwnershipAnnotation, "=", 2)
+		ic.ownershipAnnotationKey = parts[0]
+		if len(parts) == 2 {
+			ic.ownershipAnnotationValue = parts[1]
+		}
+	}
+	if config.EnableExpvarMetrics {
+		EnableExpvarMetrics()
+	}
+	if config.PropagateToTerminatingNamespaces {
+		ic.skipTerminatingNamespaces = false
+	}
+	if config.IncludeStatusInComparison {
+		ic.includeStatusInComparison = true
+	}
+	if config.RetryPolicies != (util.RetryPolicies{}) {
+		ic.retryPolicies = config.RetryPolicies
+	}
+	if config.MarkLoadBalancerPending {
+		ic.markLoadBalancerPending = true
+	}
+	if config.LBStatusMergeOrder != "" {
+		ic.lbStatusMergeOrder = config.LBStatusMergeOrder
+	}
+	if config.MaxStatusIngressEntries > 0 {
+		ic.maxStatusIngressEntries = config.MaxStatusIngressEntries
+	}
+	if config.DeferDeleteFinalizer {
+		ic.deferDeleteFinalizer = true
+	}
+	if len(config.HostClusterEndpoints) > 0 {
+		ic.hostClusterEndpoints = sets.NewString()
+		for _, endpoint := range config.HostClusterEndpoints {
+			ic.hostClusterEndpoints.Insert(normalizeServerAddress(endpoint))
+		}
+	}
+	if config.ClusterStatusExtractor != nil {
+		ic.clusterStatusExtractor = config.ClusterStatusExtractor
+	}
+	if config.PrimaryReadyPredicate != nil {
+		ic.primaryReadyPredicate = config.PrimaryReadyPredicate
+	}
+	if len(config.DisruptiveAnnotations) > 0 {
+		ic.disruptiveAnnotations = sets.NewString(config.DisruptiveAnnotations...)
+	}
+	if len(config.PreservedAnnotations) > 0 {
+		ic.preservedAnnotations = sets.NewString(config.PreservedAnnotations...)
+	}
+	if config.GCProviderAnnotationsOnClassChange {
+		ic.gcProviderAnnotationsOnClassChange = true
+	}
+	if config.CircuitBreakerFailureThreshold > 0 {
+		ic.clusterCircuitBreaker = util.NewCircuitBreaker(config.CircuitBreakerFailureThreshold, config.CircuitBreakerCoolingPeriod)
+		ic.clusterCircuitBreaker.SetStateChangeHandler(recordCircuitState)
+	}
+	if config.DisableUIDReconciliation {
+		ic.uidReconciliationDisabled = true
+	}
+	if config.StateStore != nil {
+		ic.stateStore = config.StateStore
+	}
+	if config.StateSaveInterval != 0 {
+		ic.stateSaveInterval = config.StateSaveInterval
+	}
+}
+
+// ownsIngress returns true if federation manages the given federated
+// ingress. With no ownership annotation configured every ingress is managed;
+// with one, only ingresses carrying the annotation key (and the required
+// value, when one was configured) are touched at all.
+func (ic *IngressController) ownsIngress(baseIngress *extensionsv1beta1.Ingress) bool {
+	if ic.ownershipAnnotationKey == "" {
+		return true
+	}
+	value, found := baseIngress.ObjectMeta.Annotations[ic.ownershipAnnotationKey]
+	if !found {
+		return false
+	}
+	return ic.ownershipAnnotationValue == "" || value == ic.ownershipAnnotationValue
+}
+
+// propagationPolicyFor returns the propagation policy in effect for the given
+// federated ingress: the per-object annotation override if present and valid,
+// otherwise the controller-wide policy.
+func (ic *IngressController) propagationPolicyFor(baseIngress *extensionsv1beta1.Ingress) *util.PropagationPolicy {
+	if spec, found := baseIngress.ObjectMeta.Annotations[util.PropagationPolicyAnnotation]; found {
+		policy, err := util.ParsePropagationPolicy(spec)
+		if err == nil {
+			return policy
+		}
+		glog.Errorf("Invalid %q annotation on ingress %q, falling back to the controller-wide policy: %v", util.PropagationPolicyAnnotation, types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}, err)
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "InvalidPropagationPolicy",
+			"Invalid %q annotation: %v", util.PropagationPolicyAnnotation, err)
+	}
+	return ic.propagationPolicy
+}
+
+// setClientFactory replaces the clientset factory of the underlying federated
+// informers. Must be called before Run or Start.
+func (ic *IngressController) setClientFactory(factory func(*federationapi.Cluster) (kubeclientset.Interface, error)) {
+	ic.ingressFederatedInformer.(util.FederatedInformerForTestOnly).SetClientFactory(factory)
+	ic.configMapFederatedInformer.(util.FederatedInformerForTestOnly).SetClientFactory(factory)
+	ic.serviceFederatedInformer.(util.FederatedInformerForTestOnly).SetClientFactory(factory)
+}
+
+// SetClientFactoryForTest replaces the clientset factory of the underlying
+// federated informers, so that tests (notably integration tests living outside
+// this package) can point the controller at fake member clusters. Must be
+// called before Run. Only for use in tests.
+func (ic *IngressController) SetClientFactoryForTest(factory func(*federationapi.Cluster) (kubeclientset.Interface, error)) {
+	ic.setClientFactory(factory)
+}
+
+// Per-probe time bound for SelfCheck.
+const selfCheckTimeout = 10 * time.Second
+
+// CheckResult is the outcome of a single self-check probe.
+type CheckResult struct {
+	// Name identifies the probe, e.g. "list-clusters" or
+	// "cluster/foo/ingress-api".
+	Name string
+	// Success is true if the probe passed.
+	Success bool
+	// Message carries details, notably the error of a failed probe.
+	Message string
+	// Duration is how long the probe took.
+	Duration time.Duration
+}
+
+// runCheck executes a single read-only probe, bounding it by the given
+// timeout. A timed-out probe's goroutine is abandoned; probes must therefore
+// be side-effect free.
+func runCheck(name string, timeout time.Duration, probe func() error) CheckResult {
+	start := time.Now()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- probe()
+	}()
+	select {
+	case err := <-errChan:
+		result := CheckResult{Name: name, Success: err == nil, Duration: time.Since(start)}
+		if err != nil {
+			result.Message = err.Error()
+		}
+		return result
+	case <-time.After(timeout):
+		return CheckResult{Name: name, Success: false, Message: fmt.Sprintf("timed out after %v", timeout), Duration: time.Since(start)}
+	}
+}
+
+// SelfCheck runs read-only diagnostic probes: it verifies that the federation
+// API serves the cluster list, and that each Ready cluster's ingress API and
+// ingress UID ConfigMap are reachable. Every probe is time-bounded, so the
+// method is safe to expose behind an operator-facing check mode.
+func (ic *IngressController) SelfCheck() []CheckResult {
+	results := []CheckResult{}
+	readyClusters := []*federationapi.Cluster{}
+	results = append(results, runCheck("list-clusters", selfCheckTimeout, func() error {
+		clusterList, err := ic.federatedApiClient.Federation().Clusters().List(v1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for i := range clusterList.Items {
+			cluster := &clusterList.Items[i]
+			for _, condition := range cluster.Status.Conditions {
+				if condition.Type == federationapi.ClusterReady && condition.Status == v1.ConditionTrue {
+					readyClusters = append(readyClusters, cluster)
+					break
+				}
+			}
+		}
+		return nil
+	}))
+	for _, cluster := range readyClusters {
+		if state := ic.clusterCircuitBreaker.State(cluster.Name); state != util.CircuitClosed {
+			results = append(results, CheckResult{
+				Name:    fmt.Sprintf("cluster/%s/circuit", cluster.Name),
+				Success: false,
+				Message: fmt.Sprintf("circuit breaker is %s", state),
+			})
+		}
+		client, err := ic.ingressFederatedInformer.GetClientsetForCluster(cluster.Name)
+		if err != nil {
+			results = append(results, CheckResult{
+				Name:    fmt.Sprintf("cluster/%s/client", cluster.Name),
+				Success: false,
+				Message: err.Error(),
+			})
+			continue
+		}
+		results = append(results, runCheck(fmt.Sprintf("cluster/%s/ingress-api", cluster.Name), selfCheckTimeout, func() error {
+			_, err := client.Extensions().Ingresses(api.NamespaceAll).List(v1.ListOptions{})
+			return err
+		}))
+		if !ic.uidReconciliationDisabled {
+			results = append(results, runCheck(fmt.Sprintf("cluster/%s/uid-configmap", cluster.Name), selfCheckTimeout, func() error {
+				_, err := client.Core().ConfigMaps(uidConfigMapNamespace).Get(uidConfigMapName, metav1.GetOptions{})
+				return err
+			}))
+		}
+		if lastSyncRV := ic.ingressFederatedInformer.LastSyncResourceVersion(cluster.Name); lastSyncRV != "" {
+			results = append(results, CheckResult{
+				Name:    fmt.Sprintf("cluster/%s/ingress-informer", cluster.Name),
+				Success: true,
+				Message: fmt.Sprintf("last synced to resource version %q at %s",
+					lastSyncRV, ic.ingressFederatedInformer.LastSyncTime(cluster.Name).Format(time.RFC3339)),
+			})
+		} else {
+			results = append(results, CheckResult{
+				Name:    fmt.Sprintf("cluster/%s/ingress-informer", cluster.Name),
+				Success: false,
+				Message: "ingress informer has not completed an initial list yet",
+			})
+		}
+	}
+	return results
+}
+
+// Returns true if the given object has the given finalizer in its ObjectMeta.
+func (ic *IngressController) hasFinalizerFunc(obj pkgruntime.Object, finalizer string) bool {
+	ingress := obj.(*extensionsv1beta1.Ingress)
+	for i := range ingress.ObjectMeta.Finalizers {
+		if string(ingress.ObjectMeta.Finalizers[i]) == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes the given finalizer from the given objects ObjectMeta. Only the
+// named finalizer is removed; finalizers added by users or other controllers
+// are preserved.
 // Assumes that the given object is a ingress.
-func (ic *IngressController) addFinalizerFunc(obj pkgruntime.Object, finalizer string) (pkgruntime.Object, error) {
+func (ic *IngressController) removeFinalizerFunc(obj pkgruntime.Object, finalizer string) (pkgruntime.Object, error) {
+	if !ic.federatedWritesAllowed {
+		// Federation finalizers belong to the writer shard; a non-writer
+		// shard reports success and leaves the object unchanged.
+		return obj, nil
+	}
 	ingress := obj.(*extensionsv1beta1.Ingress)
+	newFinalizers := []string{}
+	hasFinalizer := false
+	for i := range ingress.ObjectMeta.Finalizers {
+		if string(ingress.ObjectMeta.Finalizers[i]) != finalizer {
+			newFinalizers = append(newFinalizers, ingress.ObjectMeta.Finalizers[i])
+		} else {
+			hasFinalizer = true
+		}
+	}
+	if !hasFinalizer {
+		// Nothing to do.
+		return obj, nil
+	}
+	ingressObj, err := api.Scheme.DeepCopy(ingress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy ingress %s before removing finalizer %s: %v", ingress.Name, finalizer, err)
+	}
+	// Don't mutate the informer cache.
+	ingress = ingressObj.(*extensionsv1beta1.Ingress)
+	ingress.ObjectMeta.Finalizers = newFinalizers
+	ingress, err = ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Update(ingress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove finalizer %s from ingress %s: %v", finalizer, ingress.Name, err)
+	}
+	return ingress, nil
+}
+
+// batchedRemoveFinalizerFunc is the finalizer removal hook handed to the
+// deletion helper. Without a batcher it removes the finalizer inline; with one
+// it queues the ingress for removal of both federation finalizers and reports
+// success, leaving the object terminating until a pool worker performs the
+// write. The api server completes the deletion once the finalizers are gone,
+// so deferring the write only lengthens the terminating window.
+func (ic *IngressController) batchedRemoveFinalizerFunc(obj pkgruntime.Object, finalizer string) (pkgruntime.Object, error) {
+	if ic.finalizerBatcher == nil || !ic.federatedWritesAllowed {
+		return ic.removeFinalizerFunc(obj, finalizer)
+	}
+	ic.finalizerBatcher.enqueue(obj.(*extensionsv1beta1.Ingress))
+	return obj, nil
+}
+
+// removeFederationFinalizers strips both federation finalizers from the given
+// ingress with a single federation api update, where the deletion helper's
+// inline path issues one update per finalizer. Used by the finalizer batcher.
+// A NotFound from the update means the object completed deletion already and
+// counts as success.
+func (ic *IngressController) removeFederationFinalizers(ingress *extensionsv1beta1.Ingress) error {
+	newFinalizers := []string{}
+	changed := false
+	for _, value := range ingress.ObjectMeta.Finalizers {
+		if value == string(v1.FinalizerOrphan) || value == deletionhelper.FinalizerDeleteFromUnderlyingClusters {
+			changed = true
+			continue
+		}
+		newFinalizers = append(newFinalizers, value)
+	}
+	if !changed {
+		return nil
+	}
+	ingressObj, err := api.Scheme.DeepCopy(ingress)
+	if err != nil {
+		return fmt.Errorf("failed to copy ingress %s/%s before removing federation finalizers: %v", ingress.Namespace, ingress.Name, err)
+	}
+	// Don't mutate the informer cache.
+	updated := ingressObj.(*extensionsv1beta1.Ingress)
+	updated.ObjectMeta.Finalizers = newFinalizers
+	_, err = ic.federatedApiClient.Extensions().Ingresses(updated.Namespace).Update(updated)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to remove federation finalizers from ingress %s/%s: %v", ingress.Namespace, ingress.Name, err)
+	}
+	return nil
+}
+
+// Adds the given finalizer to the given objects ObjectMeta, leaving the
+// finalizers already present (including user-owned ones) untouched. No-op if
+// the finalizer is already there.
+// Assumes that the given object is a ingress.
+func (ic *IngressController) addFinalizerFunc(obj pkgruntime.Object, finalizer string) (pkgruntime.Object, error) {
+	if ic.hasFinalizerFunc(obj, finalizer) {
+		return obj, nil
+	}
+	if !ic.federatedWritesAllowed {
+		// The writer shard adds the federation finalizers; a non-writer
+		// shard reports success and leaves the object unchanged.
+		return obj, nil
+	}
+	ingressObj, err := api.Scheme.DeepCopy(obj.(*extensionsv1beta1.Ingress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy ingress before adding finalizer %s: %v", finalizer, err)
+	}
+	// Don't mutate the informer cache.
+	ingress := ingressObj.(*extensionsv1beta1.Ingress)
 	ingress.ObjectMeta.Finalizers = append(ingress.ObjectMeta.Finalizers, finalizer)
-	ingress, err := ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Update(ingress)
+	ingress, err = ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Update(ingress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to add finalizer %s to ingress %s: %v", finalizer, ingress.Name, err)
 	}
 	return ingress, nil
 }
 
+// Start launches the controller's background processes with a stop channel
+// owned by the controller. All goroutines spawned by the controller (the
+// federated informers, the deliverers and the backoff GCs) are stopped by
+// Stop; WaitForShutdown blocks until the teardown has completed. Embedders
+// that prefer to own the stop channel themselves can keep calling Run.
+func (ic *IngressController) Start() {
+	ic.stopChan = make(chan struct{})
+	ic.Run(ic.stopChan)
+}
+
+// Stop requests shutdown of a controller launched with Start.
+func (ic *IngressController) Stop() {
+	close(ic.stopChan)
+}
+
+// WaitForShutdown blocks until a controller stopped via Stop (or via closing
+// the channel given to Run) has stopped its informers and deliverers.
+func (ic *IngressController) WaitForShutdown() {
+	<-ic.shutdownDone
+}
+
+// Run launches the controller's background processes, stopping them when the
+// given channel is closed. The controller owns every goroutine it spawns;
+// none of them outlive the teardown triggered by the stop channel.
 func (ic *IngressController) Run(stopChan <-chan struct{}) {
 	glog.Infof("Starting Ingress Controller")
+	ic.shutdownDone = make(chan struct{})
+	ic.startTime = ic.clock.Now()
+	if ic.stateStore != nil {
+		if state, err := ic.stateStore.Load(); err != nil {
+			glog.Errorf("Failed to load controller state from the state store: %v", err)
+		} else {
+			ic.restoreState(state)
+		}
+		go wait.Until(func() {
+			if err := ic.stateStore.Save(ic.snapshotState()); err != nil {
+				glog.Errorf("Failed to save controller state to the state store: %v", err)
+			}
+		}, ic.stateSaveInterval, stopChan)
+	}
 	go ic.ingressInformerController.Run(stopChan)
+	go func() {
+		// See the delivery trigger in NewIngressControllerWithOptions:
+		// HasSynced cannot be called from there, so the backlog-drained
+		// flag for the startup ramp is flipped here instead.
+		wait.PollUntil(100*time.Millisecond, func() (bool, error) {
+			return ic.ingressInformerController.HasSynced(), nil
+		}, stopChan)
+		atomic.StoreInt32(&ic.startupBacklogDrained, 1)
+	}()
+	go ic.namespaceInformerController.Run(stopChan)
 	glog.Infof("... Starting Ingress Federated Informer")
 	ic.ingressFederatedInformer.Start()
-	glog.Infof("... Starting ConfigMap Federated Informer")
-	ic.configMapFederatedInformer.Start()
+	if ic.uidReconciliationDisabled {
+		glog.Infof("... Ingress UID reconciliation is disabled; not starting the ConfigMap Federated Informer")
+	} else {
+		glog.Infof("... Starting ConfigMap Federated Informer")
+		ic.configMapFederatedInformer.Start()
+	}
+	glog.Infof("... Starting Service Federated Informer")
+	ic.serviceFederatedInformer.Start()
 	go func() {
 		<-stopChan
+		// Gathered before the informers stop: the summary reads their stores.
+		summary := ic.buildShutdownSummary()
 		glog.Infof("Stopping Ingress Federated Informer")
 		ic.ingressFederatedInformer.Stop()
-		glog.Infof("Stopping ConfigMap Federated Informer")
-		ic.configMapFederatedInformer.Stop()
+		if !ic.uidReconciliationDisabled {
+			glog.Infof("Stopping ConfigMap Federated Informer")
+			ic.configMapFederatedInformer.Stop()
+		}
+		glog.Infof("Stopping Service Federated Informer")
+		ic.serviceFederatedInformer.Stop()
 		glog.Infof("Stopoing ingress deliverer")
 		ic.ingressDeliverer.Stop()
 		glog.Infof("Stopping configmap deliverer")
 		ic.configMapDeliverer.Stop()
 		glog.Infof("Stopping cluster deliverer")
 		ic.clusterDeliverer.Stop()
+		ic.reconcileBuffer.stop()
+		glog.Infof("Ingress controller shutdown summary: %s", summary)
+		ic.statusLock.Lock()
+		ic.shutdownSummaryText = summary
+		ic.statusLock.Unlock()
+		close(ic.shutdownDone)
 	}()
 	ic.ingressDeliverer.StartWithHandler(func(item *util.DelayingDelivererItem) {
 		ingress := item.Value.(types.NamespacedName)
-		glog.V(4).Infof("Ingress change delivered, reconciling: %v", ingress)
-		ic.reconcileIngress(ingress)
+		ic.reconcileBuffer.add(ic.reconcilePriorityFor(ingress), ingress)
 	})
+	go func() {
+		for {
+			ingress, ok := ic.reconcileBuffer.pop()
+			if !ok {
+				return
+			}
+			glog.V(4).Infof("Ingress change delivered, reconciling: %v", ingress)
+			ic.reconcileIngress(ingress)
+			ic.reconcileClusterFinalizers()
+		}
+	}()
 	ic.clusterDeliverer.StartWithHandler(func(item *util.DelayingDelivererItem) {
 		clusterName := item.Key
 		if clusterName != allClustersKey {
@@ -394,6 +2074,12 @@ func (ic *IngressController) Run(stopChan <-chan struct{}) {
 		ic.reconcileConfigMapForCluster(clusterName)
 	})
 
+	go ic.logUnsyncedSkipSummary(stopChan)
+
+	if ic.finalizerBatcher != nil {
+		ic.finalizerBatcher.run(stopChan)
+	}
+
 	util.StartBackoffGC(ic.ingressBackoff, stopChan)
 	util.StartBackoffGC(ic.configMapBackoff, stopChan)
 }
@@ -409,8 +2095,10 @@ func (ic *IngressController) deliverIngress(ingress types.NamespacedName, delay
 	if failed {
 		ic.ingressBackoff.Next(key, time.Now())
 		delay = delay + ic.ingressBackoff.Get(key)
+		ic.trackBackoffKey(key, true)
 	} else {
 		ic.ingressBackoff.Reset(key)
+		ic.trackBackoffKey(key, false)
 	}
 	ic.ingressDeliverer.DeliverAfter(key, ingress, delay)
 }
@@ -435,42 +2123,309 @@ func (ic *IngressController) deliverConfigMap(cluster string, configMap types.Na
 // Check whether all data stores are in sync. False is returned if any of the informer/stores is not yet
 // synced with the corresponding api server.
 func (ic *IngressController) isSynced() bool {
+	synced, _ := ic.syncedState()
+	return synced
+}
+
+// syncedState reports whether all informers backing the controller have
+// synced and, if not, which one is lagging (one of the unsyncedReason*
+// constants). Used by the reconcile entry points to classify deferrals.
+func (ic *IngressController) syncedState() (bool, string) {
 	if !ic.ingressFederatedInformer.ClustersSynced() {
 		glog.V(2).Infof("Cluster list not synced for ingress federated informer")
-		return false
+		return false, unsyncedReasonClusterList
 	}
 	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
 	if err != nil {
 		glog.Errorf("Failed to get ready clusters for ingress federated informer: %v", err)
-		return false
+		return false, unsyncedReasonClusterList
 	}
 	if !ic.ingressFederatedInformer.GetTargetStore().ClustersSynced(clusters) {
 		glog.V(2).Infof("Target store not synced for ingress federated informer")
-		return false
+		return false, unsyncedReasonIngressInformer
+	}
+	if !ic.uidReconciliationDisabled {
+		if !ic.configMapFederatedInformer.ClustersSynced() {
+			glog.V(2).Infof("Cluster list not synced for config map federated informer")
+			return false, unsyncedReasonClusterList
+		}
+		clusters, err = ic.configMapFederatedInformer.GetReadyClusters()
+		if err != nil {
+			glog.Errorf("Failed to get ready clusters for configmap federated informer: %v", err)
+			return false, unsyncedReasonClusterList
+		}
+		if !ic.configMapFederatedInformer.GetTargetStore().ClustersSynced(clusters) {
+			glog.V(2).Infof("Target store not synced for configmap federated informer")
+			return false, unsyncedReasonConfigMapInformer
+		}
+	}
+	glog.V(4).Infof("Cluster list is synced")
+	return true, ""
+}
+
+// recordUnsyncedSkip counts a reconcile that was deferred because the given
+// informer had not synced yet.
+func (ic *IngressController) recordUnsyncedSkip(reason string) {
+	recordUnsyncedReconcileSkip(reason)
+	ic.unsyncedSkipLock.Lock()
+	defer ic.unsyncedSkipLock.Unlock()
+	if ic.unsyncedSkips == nil {
+		ic.unsyncedSkips = make(map[string]int64)
+	}
+	ic.unsyncedSkips[reason]++
+}
+
+// UnsyncedReconcileSkips returns how many reconciles have been deferred since
+// startup because an informer had not synced yet, keyed by reason. Intended
+// for healthz-style introspection; a count that keeps growing long after
+// startup points at an informer that cannot complete its initial list.
+func (ic *IngressController) UnsyncedReconcileSkips() map[string]int64 {
+	ic.unsyncedSkipLock.Lock()
+	defer ic.unsyncedSkipLock.Unlock()
+	result := make(map[string]int64, len(ic.unsyncedSkips))
+	for reason, count := range ic.unsyncedSkips {
+		result[reason] = count
+	}
+	return result
+}
+
+// logUnsyncedSkipSummary periodically logs how many reconciles were deferred
+// on unsynced informers, so that slow post-restart convergence is visible
+// without metrics scraping.
+// setLastError records the outcome of a reconciliation pass for LastError.
+// warnGatedAnnotation emits a single explanatory event per ingress/annotation
+// pair when an annotation-driven alpha behavior is requested while its feature
+// gate is off. Returns true the first time, when the event was emitted.
+func (ic *IngressController) warnGatedAnnotation(baseIngress *extensionsv1beta1.Ingress, annotation, gate string) bool {
+	key := fmt.Sprintf("%s/%s|%s", baseIngress.Namespace, baseIngress.Name, annotation)
+	ic.statusLock.Lock()
+	if ic.gatedAnnotationWarned == nil {
+		ic.gatedAnnotationWarned = sets.NewString()
 	}
-	if !ic.configMapFederatedInformer.ClustersSynced() {
-		glog.V(2).Infof("Cluster list not synced for config map federated informer")
+	alreadyWarned := ic.gatedAnnotationWarned.Has(key)
+	ic.gatedAnnotationWarned.Insert(key)
+	ic.statusLock.Unlock()
+	if alreadyWarned {
 		return false
 	}
-	clusters, err = ic.configMapFederatedInformer.GetReadyClusters()
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "FeatureGateDisabled",
+		"Ignoring %s annotation: the %s feature gate is disabled", annotation, gate)
+	return true
+}
+
+func (ic *IngressController) setLastError(err error) {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	ic.lastReconcileError = err
+}
+
+// trackBackoffKey maintains the set of ingresses currently in retry backoff,
+// which the state store persists so a restarted leader keeps backing off.
+func (ic *IngressController) trackBackoffKey(key string, inBackoff bool) {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	if ic.backoffKeys == nil {
+		ic.backoffKeys = sets.NewString()
+	}
+	if inBackoff {
+		ic.backoffKeys.Insert(key)
+	} else {
+		ic.backoffKeys.Delete(key)
+	}
+}
+
+// recordMissingConfigMapRequeue counts a configmap reconcile that was
+// requeued because the cluster's ingress UID ConfigMap did not exist yet.
+func (ic *IngressController) recordMissingConfigMapRequeue(clusterName string) {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	if ic.missingConfigMapRequeues == nil {
+		ic.missingConfigMapRequeues = make(map[string]int64)
+	}
+	ic.missingConfigMapRequeues[clusterName]++
+}
+
+// MissingUIDConfigMapRequeues returns how many times each cluster's configmap
+// reconciliation was requeued because the ingress UID ConfigMap did not exist
+// yet. A count that keeps growing points at a cluster whose ingress
+// controller never created its uid configmap. Safe to call concurrently with
+// reconciliation.
+func (ic *IngressController) MissingUIDConfigMapRequeues() map[string]int64 {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	result := make(map[string]int64, len(ic.missingConfigMapRequeues))
+	for clusterName, count := range ic.missingConfigMapRequeues {
+		result[clusterName] = count
+	}
+	return result
+}
+
+// setClusterStatus records the result of the most recent write to a cluster
+// for ClusterStatuses.
+func (ic *IngressController) setClusterStatus(clusterName, status string) {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	if ic.clusterStatuses == nil {
+		ic.clusterStatuses = make(map[string]string)
+	}
+	ic.clusterStatuses[clusterName] = status
+}
+
+// LastError returns the error recorded by the most recent reconciliation
+// that failed to apply its writes, or nil after a clean pass. Errors recorded
+// by a reconciliation are of type *ReconcileError and carry the object key,
+// cluster and phase of the failed write. Safe to call concurrently with
+// reconciliation.
+func (ic *IngressController) LastError() error {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	return ic.lastReconcileError
+}
+
+// ClusterStatuses returns a copy of the per-cluster result of the most recent
+// write to each cluster. Safe to call concurrently with reconciliation.
+func (ic *IngressController) ClusterStatuses() map[string]string {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	result := make(map[string]string, len(ic.clusterStatuses))
+	for clusterName, status := range ic.clusterStatuses {
+		result[clusterName] = status
+	}
+	return result
+}
+
+// RecentReconciles returns the last reconcile decisions, oldest first, up to
+// the configured replay log size. Safe to call concurrently with
+// reconciliation.
+func (ic *IngressController) RecentReconciles() []ReconcileRecord {
+	return ic.reconcileReplayLog.snapshot()
+}
+
+// ReconcileCostEstimate reports the average number of cluster API calls per
+// reconcile, overall and per federated ingress, over the window of recent
+// reconciles kept in the replay log. Intended to guide rate limiter (QPS)
+// tuning: multiplying the per-reconcile average by the observed reconcile
+// rate approximates the API load the current ingress set generates.
+func (ic *IngressController) ReconcileCostEstimate() ReconcileCostEstimate {
+	return ic.reconcileReplayLog.costEstimate()
+}
+
+// ManagedClusters returns the names of the ready clusters currently hosting
+// at least one federated ingress. Safe to call concurrently with
+// reconciliation - the snapshot is assembled from the thread-safe informer
+// stores.
+func (ic *IngressController) ManagedClusters() []string {
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
 	if err != nil {
-		glog.Errorf("Failed to get ready clusters for configmap federated informer: %v", err)
-		return false
+		return []string{}
 	}
-	if !ic.configMapFederatedInformer.GetTargetStore().ClustersSynced(clusters) {
-		glog.V(2).Infof("Target store not synced for configmap federated informer")
-		return false
+	managed := []string{}
+	for _, cluster := range clusters {
+		if ic.clusterHasIngresses(cluster.Name) {
+			managed = append(managed, cluster.Name)
+		}
+	}
+	return managed
+}
+
+// ShutdownSummary returns the operational summary logged at the end of the
+// most recent graceful shutdown, or the empty string while the controller is
+// running. Safe to call concurrently.
+func (ic *IngressController) ShutdownSummary() string {
+	ic.statusLock.Lock()
+	defer ic.statusLock.Unlock()
+	return ic.shutdownSummaryText
+}
+
+// buildShutdownSummary assembles the one-line summary logged on graceful
+// shutdown: the managed clusters, how many federated ingresses have an
+// equivalent copy in every ready cluster, and the pending reconcile error if
+// any. Name lists are capped at maxShutdownSummaryNames entries. Must run
+// before the federated informers stop, since it reads their stores.
+func (ic *IngressController) buildShutdownSummary() string {
+	managed := ic.ManagedClusters()
+	sort.Strings(managed)
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
+	if err != nil {
+		clusters = nil
+	}
+	inSync := 0
+	outOfSync := []string{}
+	for _, obj := range ic.ingressInformerStore.List() {
+		baseIngress, ok := obj.(*extensionsv1beta1.Ingress)
+		if !ok {
+			continue
+		}
+		if ic.ingressInSyncEverywhere(baseIngress, clusters) {
+			inSync++
+		} else {
+			outOfSync = append(outOfSync, types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}.String())
+		}
+	}
+	sort.Strings(outOfSync)
+	summary := fmt.Sprintf("clusters managed: %s; ingresses in sync: %d, out of sync: %d",
+		summarizeNames(managed), inSync, len(outOfSync))
+	if len(outOfSync) > 0 {
+		summary = fmt.Sprintf("%s (%s)", summary, summarizeNames(outOfSync))
+	}
+	if lastError := ic.LastError(); lastError != nil {
+		summary = fmt.Sprintf("%s; last error: %v", summary, lastError)
+	}
+	return summary
+}
+
+// ingressInSyncEverywhere returns true if every given cluster holds a copy of
+// the federated ingress equivalent to it. Store read errors count as out of
+// sync - the shutdown summary is best effort.
+func (ic *IngressController) ingressInSyncEverywhere(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) bool {
+	for _, cluster := range clusters {
+		key := clusterIngressKey(cluster, types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name})
+		obj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, key)
+		if err != nil || !found {
+			return false
+		}
+		clusterIngress, ok := obj.(*extensionsv1beta1.Ingress)
+		if !ok || !ClusterCopyEquivalent(baseIngress, clusterIngress) {
+			return false
+		}
 	}
-	glog.V(4).Infof("Cluster list is synced")
 	return true
 }
 
+// summarizeNames renders a name list for the shutdown summary, listing at
+// most maxShutdownSummaryNames entries.
+func summarizeNames(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	if len(names) > maxShutdownSummaryNames {
+		return fmt.Sprintf("%s and %d more", strings.Join(names[:maxShutdownSummaryNames], ", "), len(names)-maxShutdownSummaryNames)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (ic *IngressController) logUnsyncedSkipSummary(stopChan <-chan struct{}) {
+	var lastTotal int64
+	wait.Until(func() {
+		skips := ic.UnsyncedReconcileSkips()
+		var total int64
+		for _, count := range skips {
+			total += count
+		}
+		if total > lastTotal {
+			glog.V(2).Infof("Reconciles deferred on unsynced informers since startup: %v", skips)
+		}
+		lastTotal = total
+	}, unsyncedSkipLogInterval, stopChan)
+}
+
 // The function triggers reconcilation of all federated ingresses.  clusterName is the name of the cluster that changed
 // but all ingresses in all clusters are reconciled
 func (ic *IngressController) reconcileIngressesOnClusterChange(clusterName string) {
 	glog.V(4).Infof("Reconciling ingresses on cluster change for cluster %q", clusterName)
-	if !ic.isSynced() {
+	if synced, reason := ic.syncedState(); !synced {
 		glog.V(4).Infof("Not synced, will try again later to reconcile ingresses.")
+		ic.recordUnsyncedSkip(reason)
 		ic.clusterDeliverer.DeliverAfter(clusterName, nil, ic.clusterAvailableDelay)
 	}
 	ingressList := ic.ingressInformerStore.List()
@@ -494,7 +2449,12 @@ func (ic *IngressController) reconcileIngressesOnClusterChange(clusterName strin
 func (ic *IngressController) reconcileConfigMapForCluster(clusterName string) {
 	glog.V(4).Infof("Reconciling ConfigMap for cluster(s) %q", clusterName)
 
-	if !ic.isSynced() {
+	if ic.uidReconciliationDisabled {
+		glog.V(4).Infof("Ingress UID reconciliation is disabled, ignoring reconcile config map.")
+		return
+	}
+	if synced, reason := ic.syncedState(); !synced {
+		ic.recordUnsyncedSkip(reason)
 		ic.configMapDeliverer.DeliverAfter(clusterName, nil, ic.clusterAvailableDelay)
 		return
 	}
@@ -526,11 +2486,25 @@ func (ic *IngressController) reconcileConfigMapForCluster(clusterName string) {
 		}
 		uidConfigMapNamespacedName := types.NamespacedName{Name: uidConfigMapName, Namespace: uidConfigMapNamespace}
 		configMapObj, found, err := ic.configMapFederatedInformer.GetTargetStore().GetByKey(cluster.Name, uidConfigMapNamespacedName.String())
-		if !found || err != nil {
+		if err != nil {
 			glog.Errorf("Failed to get ConfigMap %q for cluster %q.  Will try again later: %v", uidConfigMapNamespacedName, cluster.Name, err)
 			ic.configMapDeliverer.DeliverAfter(clusterName, nil, ic.configMapReviewDelay)
 			return
 		}
+		if !found {
+			// The configmap is created by the cluster's ingress controller,
+			// not by federation, so it is normal for it to be absent for a
+			// while on a new cluster. Requeue at a dedicated pace rather than
+			// treating the absence as an error.
+			requeue := ic.missingConfigMapRequeue
+			if requeue == 0 {
+				requeue = ic.configMapReviewDelay
+			}
+			glog.V(2).Infof("ConfigMap %q not found in cluster %q; expecting the cluster's ingress controller to create it.  Will check again in %v", uidConfigMapNamespacedName, cluster.Name, requeue)
+			ic.recordMissingConfigMapRequeue(cluster.Name)
+			ic.configMapDeliverer.DeliverAfter(clusterName, nil, requeue)
+			return
+		}
 		glog.V(4).Infof("Successfully got ConfigMap %q for cluster %q.", uidConfigMapNamespacedName, clusterName)
 		configMap, ok := configMapObj.(*v1.ConfigMap)
 		if !ok {
@@ -572,7 +2546,25 @@ func (ic *IngressController) reconcileConfigMap(cluster *federationapi.Cluster,
 		ic.updateClusterIngressUIDToMasters(cluster, configMapUID) // Second argument is the fallback, in case this is the only cluster, in which case it becomes the master
 		return
 	}
+	if configMap.ObjectMeta.Annotations[uidManagedByAnnotation] == uidManagedByCluster {
+		// The cluster intentionally pins its own uid (e.g. a manually managed
+		// GLBC). Never overwrite it; just surface a disagreement with the
+		// federation UID as a condition on the cluster.
+		conflicting := configMapUID != clusterIngressUID
+		if conflicting {
+			glog.V(2).Infof("ConfigMap %q in cluster %q is managed by the cluster and its uid %q conflicts with the federation uid %q - not overwriting", configMapNsName, cluster.Name, configMapUID, clusterIngressUID)
+		}
+		ic.setClusterCondition(cluster.Name, clusterUIDConflictCondition, conflicting,
+			"IngressUIDConflict", fmt.Sprintf("Cluster-managed ingress UID %q conflicts with federation UID %q", configMapUID, clusterIngressUID))
+		return
+	}
+	ic.setClusterCondition(cluster.Name, clusterUIDConflictCondition, false, "", "")
 	if configMapUID != clusterIngressUID { // An update is required
+		if ic.strictReadinessGate && !clusterWritesAllowed(cluster, ic.clockSkewTolerance) {
+			ic.logNotReadyClusterSkip(cluster.Name)
+			ic.configMapDeliverer.DeliverAfter(cluster.Name, nil, ic.clusterAvailableDelay)
+			return
+		}
 		glog.V(4).Infof("Ingress UID update is required: configMapUID %q not equal to clusterIngressUID %q", configMapUID, clusterIngressUID)
 		configMap.Data[uidKey] = clusterIngressUID
 		operations := []util.FederatedOperation{{
@@ -581,10 +2573,11 @@ func (ic *IngressController) reconcileConfigMap(cluster *federationapi.Cluster,
 			ClusterName: cluster.Name,
 		}}
 		glog.V(4).Infof("Calling federatedConfigMapUpdater.Update() - operations: %v", operations)
-		err := ic.federatedConfigMapUpdater.Update(operations, ic.updateTimeout)
+		err := ic.federatedConfigMapUpdater.UpdateWithPolicies(operations, ic.updateTimeout, ic.retryPolicies, nil)
 		if err != nil {
 			configMapName := types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}
 			glog.Errorf("Failed to execute update of ConfigMap %q on cluster %q: %v", configMapName, cluster.Name, err)
+			ic.setLastError(newReconcileError(configMapName.String(), cluster.Name, ReconcilePhaseConfigMap, err))
 			ic.configMapDeliverer.DeliverAfter(cluster.Name, nil, ic.configMapReviewDelay)
 		}
 	} else {
@@ -592,11 +2585,209 @@ func (ic *IngressController) reconcileConfigMap(cluster *federationapi.Cluster,
 	}
 }
 
+/*
+  HealUIDConfigMap immediately rewrites the UID ConfigMap of the named cluster
+  to the canonical federation ingress UID, bypassing the configmap review
+  delay. It is intended for targeted remediation: an operator can fix a single
+  divergent cluster without waiting for (or triggering) a full reconciliation
+  sweep. A configmap pinned by the cluster via the uidManagedByAnnotation is
+  never overwritten. An event is recorded on the cluster when the configmap
+  was actually rewritten.
+*/
+func (ic *IngressController) HealUIDConfigMap(clusterName string) error {
+	if ic.uidReconciliationDisabled {
+		return fmt.Errorf("ingress UID reconciliation is disabled")
+	}
+	if synced, reason := ic.syncedState(); !synced {
+		return fmt.Errorf("cannot heal the UID ConfigMap in cluster %q: %s", clusterName, reason)
+	}
+	cluster, found, err := ic.configMapFederatedInformer.GetReadyCluster(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster %q: %v", clusterName, err)
+	}
+	if !found {
+		return fmt.Errorf("cluster %q is not ready", clusterName)
+	}
+	_, masterUID, err := ic.getMasterCluster()
+	if err != nil {
+		return fmt.Errorf("cannot determine the canonical ingress UID: %v", err)
+	}
+
+	ic.Lock() // Same master election lock as reconcileConfigMap.
+	defer ic.Unlock()
+
+	uidConfigMapNamespacedName := types.NamespacedName{Name: uidConfigMapName, Namespace: uidConfigMapNamespace}
+	configMapObj, found, err := ic.configMapFederatedInformer.GetTargetStore().GetByKey(cluster.Name, uidConfigMapNamespacedName.String())
+	if err != nil || !found {
+		return fmt.Errorf("failed to get ConfigMap %q for cluster %q: %v", uidConfigMapNamespacedName, cluster.Name, err)
+	}
+	configMap, ok := configMapObj.(*v1.ConfigMap)
+	if !ok {
+		return fmt.Errorf("internal error: the object in the ConfigMap cache for cluster %q configmap %q is not a *ConfigMap", cluster.Name, uidConfigMapNamespacedName)
+	}
+	if configMap.ObjectMeta.Annotations[uidManagedByAnnotation] == uidManagedByCluster {
+		return fmt.Errorf("ConfigMap %q in cluster %q is managed by the cluster - refusing to overwrite it", uidConfigMapNamespacedName, cluster.Name)
+	}
+	if configMap.Data[uidKey] == masterUID {
+		glog.V(4).Infof("ConfigMap %q in cluster %q already has uid %q, nothing to heal", uidConfigMapNamespacedName, cluster.Name, masterUID)
+		return nil
+	}
+	configMapObj, err = api.Scheme.DeepCopy(configMap) // Don't mutate the informer cache.
+	configMap, ok = configMapObj.(*v1.ConfigMap)
+	if err != nil || !ok {
+		return fmt.Errorf("internal error: failed to clone ConfigMap %q for cluster %q: %v", uidConfigMapNamespacedName, cluster.Name, err)
+	}
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[uidKey] = masterUID
+	operations := []util.FederatedOperation{{
+		Type:        util.OperationTypeUpdate,
+		Obj:         configMap,
+		ClusterName: cluster.Name,
+	}}
+	if err := ic.federatedConfigMapUpdater.Update(operations, ic.updateTimeout); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %q in cluster %q: %v", uidConfigMapNamespacedName, cluster.Name, err)
+	}
+	ic.eventRecorder.Eventf(cluster, api.EventTypeNormal, "UIDConfigMapHealed",
+		"Rewrote ConfigMap %q with the canonical ingress uid %q", uidConfigMapNamespacedName, masterUID)
+	glog.V(2).Infof("Healed ConfigMap %q in cluster %q to the canonical ingress uid %q", uidConfigMapNamespacedName, cluster.Name, masterUID)
+	return nil
+}
+
 /*
   getMasterCluster returns the cluster which is the elected master w.r.t. ingress UID, and it's ingress UID.
   If there is no elected master cluster, an error is returned.
   All other clusters must use the ingress UID of the elected master.
 */
+// chooseFirstCluster deterministically picks the ready cluster in which the
+// initial copy of a federated ingress is created: the lexicographically
+// smallest cluster name. Every controller instance looking at the same set of
+// ready clusters elects the same cluster, so the election result does not
+// depend on map iteration order.
+func chooseFirstCluster(clusters []*federationapi.Cluster) (string, bool) {
+	chosen := ""
+	for _, cluster := range clusters {
+		if chosen == "" || cluster.Name < chosen {
+			chosen = cluster.Name
+		}
+	}
+	return chosen, chosen != ""
+}
+
+// ingressDrain tracks an in-flight migration of a federated ingress off a
+// cluster that is being unjoined.
+type ingressDrain struct {
+	// Name of the leaving cluster the ingress was anchored on.
+	cluster string
+	// When the drain started, for the drain timeout.
+	started time.Time
+}
+
+// reconcileClusterDrain migrates the first-cluster anchor of the given
+// ingress away from a cluster that is being deleted (unjoined) before its
+// copies are cleaned up: it re-elects the first cluster among the remaining
+// ready clusters, waits up to drainTimeout for the new anchor's copy to
+// report a loadbalancer address, and only then deletes the leaving cluster's
+// copy. Returns true when it has taken over the reconciliation round.
+func (ic *IngressController) reconcileClusterDrain(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster, recordAction func(action, clusterName string)) bool {
+	ingress := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}
+	key := ingress.String()
+	clustersByNameMap := make(map[string]*federationapi.Cluster, len(clusters))
+	for _, cluster := range clusters {
+		clustersByNameMap[cluster.Name] = cluster
+	}
+
+	ic.drainsLock.Lock()
+	drain, draining := ic.ingressDrains[key]
+	ic.drainsLock.Unlock()
+
+	firstClusterName := baseIngress.ObjectMeta.Annotations[firstClusterAnnotation]
+	if !draining {
+		leaving, found := clustersByNameMap[firstClusterName]
+		if !found || leaving.ObjectMeta.DeletionTimestamp == nil {
+			return false
+		}
+		// Elect the smallest remaining cluster that is not itself leaving.
+		replacement := ""
+		for _, cluster := range clusters {
+			if cluster.ObjectMeta.DeletionTimestamp != nil {
+				continue
+			}
+			if replacement == "" || cluster.Name < replacement {
+				replacement = cluster.Name
+			}
+		}
+		if replacement == "" {
+			glog.V(2).Infof("Cannot drain ingress %q off leaving cluster %s: no other ready cluster", ingress, leaving.Name)
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "DrainBlocked",
+				"Cluster %s is being removed but no other ready cluster can take over the ingress", leaving.Name)
+			ic.deliverIngress(ingress, ic.ingressReviewDelay, false)
+			return true
+		}
+		ic.drainsLock.Lock()
+		ic.ingressDrains[key] = ingressDrain{cluster: leaving.Name, started: ic.clock.Now()}
+		ic.drainsLock.Unlock()
+		glog.V(2).Infof("Draining ingress %q off leaving cluster %s: re-electing first cluster %s", ingress, leaving.Name, replacement)
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "DrainStarted",
+			"Cluster %s is being removed; migrating the ingress anchor to cluster %s", leaving.Name, replacement)
+		ic.updateAnnotationOnIngress(baseIngress, firstClusterAnnotation, replacement)
+		return true
+	}
+
+	forget := func() {
+		ic.drainsLock.Lock()
+		delete(ic.ingressDrains, key)
+		ic.drainsLock.Unlock()
+	}
+	if _, stillPresent := clustersByNameMap[drain.cluster]; !stillPresent {
+		// The leaving cluster is gone; nothing left to clean up here.
+		forget()
+		return false
+	}
+	newAnchorCluster, found := clustersByNameMap[firstClusterName]
+	hasAddress := false
+	if found {
+		if copyObj, copyFound, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(newAnchorCluster.Name, clusterIngressKey(newAnchorCluster, ingress)); err == nil && copyFound {
+			if clusterCopy, ok := copyObj.(*extensionsv1beta1.Ingress); ok {
+				hasAddress = len(ic.extractClusterStatus(newAnchorCluster.Name, clusterCopy).Ingress) > 0
+			}
+		}
+	}
+	if !hasAddress && ic.clock.Since(drain.started) < ic.drainTimeout {
+		// Let the normal pass below create/update the new anchor's copy and
+		// check back shortly.
+		glog.V(4).Infof("Drain of ingress %q off cluster %s: waiting for cluster %s to report an address", ingress, drain.cluster, firstClusterName)
+		ic.deliverIngress(ingress, ic.smallDelay, false)
+		return false
+	}
+	if !hasAddress {
+		glog.Warningf("Drain of ingress %q off cluster %s timed out waiting for cluster %s to report an address - removing the leaving copy anyway", ingress, drain.cluster, firstClusterName)
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "DrainTimeout",
+			"Timed out waiting for cluster %s to pick up a loadbalancer address; removing the copy in leaving cluster %s", firstClusterName, drain.cluster)
+	}
+	leavingCluster := clustersByNameMap[drain.cluster]
+	if copyObj, copyFound, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(drain.cluster, clusterIngressKey(leavingCluster, ingress)); err == nil && copyFound {
+		operations := []util.FederatedOperation{{
+			Type:        util.OperationTypeDelete,
+			Obj:         copyObj.(pkgruntime.Object),
+			ClusterName: drain.cluster,
+		}}
+		if err := ic.federatedIngressUpdater.Update(operations, ic.updateTimeout); err != nil {
+			glog.Errorf("Failed to delete drained copy of ingress %q in leaving cluster %s, will retry: %v", ingress, drain.cluster, err)
+			ic.deliverIngress(ingress, ic.smallDelay, true)
+			return true
+		}
+		recordAction(actionDelete, drain.cluster)
+	}
+	glog.V(2).Infof("Drain of ingress %q off cluster %s complete", ingress, drain.cluster)
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "DrainCompleted",
+		"Ingress is now anchored on cluster %s; removed the copy in leaving cluster %s", firstClusterName, drain.cluster)
+	forget()
+	ic.deliverIngress(ingress, ic.smallDelay, false)
+	return true
+}
+
 func (ic *IngressController) getMasterCluster() (master *federationapi.Cluster, ingressUID string, err error) {
 	clusters, err := ic.configMapFederatedInformer.GetReadyClusters()
 	if err != nil {
@@ -604,6 +2795,16 @@ func (ic *IngressController) getMasterCluster() (master *federationapi.Cluster,
 		return nil, "", err
 	}
 
+	if ic.preferOldestClusterUID {
+		master, ingressUID, reason := chooseMasterCluster(clusters, ic.clusterHasProviderAnnotatedIngress)
+		if master != nil {
+			glog.V(4).Infof("Found master cluster %q (%s) with annotation %q=%q", master.Name, reason, uidAnnotationKey, ingressUID)
+			ic.recordUIDElectionEvent(master.Name, ingressUID, reason)
+			return master, ingressUID, nil
+		}
+		return nil, "", fmt.Errorf("Failed to find master cluster with annotation %q", uidAnnotationKey)
+	}
+
 	for _, c := range clusters {
 		UID, exists := c.ObjectMeta.Annotations[uidAnnotationKey]
 		if exists && UID != "" { // Found the master cluster
@@ -614,6 +2815,77 @@ func (ic *IngressController) getMasterCluster() (master *federationapi.Cluster,
 	return nil, "", fmt.Errorf("Failed to find master cluster with annotation %q", uidAnnotationKey)
 }
 
+/*
+  chooseMasterCluster selects the cluster whose UID annotation is authoritative.
+  Precedence:
+  1. A cluster explicitly pinned via the uidAuthorityAnnotation.
+  2. A cluster with a non-empty provider IP annotation on one of its ingresses,
+     as it most likely holds the live cloud load balancer resources.
+  3. The cluster with the oldest creationTimestamp.
+  Only clusters that already carry a non-empty UID annotation are considered.
+  Returns the chosen cluster, its UID and a human readable reason, or nil if no
+  cluster qualifies.
+*/
+func chooseMasterCluster(clusters []*federationapi.Cluster, hasProviderAnnotatedIngress func(clusterName string) bool) (*federationapi.Cluster, string, string) {
+	var byProvider, oldest *federationapi.Cluster
+	for _, c := range clusters {
+		UID, exists := c.ObjectMeta.Annotations[uidAnnotationKey]
+		if !exists || UID == "" {
+			continue
+		}
+		if c.ObjectMeta.Annotations[uidAuthorityAnnotation] == "true" {
+			return c, UID, "explicitly pinned as UID authority"
+		}
+		if byProvider == nil && hasProviderAnnotatedIngress != nil && hasProviderAnnotatedIngress(c.Name) {
+			byProvider = c
+		}
+		if oldest == nil || c.ObjectMeta.CreationTimestamp.Before(oldest.ObjectMeta.CreationTimestamp) {
+			oldest = c
+		}
+	}
+	if byProvider != nil {
+		return byProvider, byProvider.ObjectMeta.Annotations[uidAnnotationKey], "has provider-annotated ingresses"
+	}
+	if oldest != nil {
+		return oldest, oldest.ObjectMeta.Annotations[uidAnnotationKey], "oldest cluster"
+	}
+	return nil, "", ""
+}
+
+// providerStaticIPIndexFunc indexes cluster ingress copies under "true" when
+// they carry a non-empty provider static IP annotation.
+func providerStaticIPIndexFunc(obj interface{}) ([]string, error) {
+	clusterIngress, ok := obj.(*extensionsv1beta1.Ingress)
+	if !ok || clusterIngress.ObjectMeta.Annotations[staticIPNameKeyReadonly] == "" {
+		return []string{}, nil
+	}
+	return []string{"true"}, nil
+}
+
+// clusterHasProviderAnnotatedIngress returns true if any cached ingress in the
+// given cluster carries a non-empty provider static IP annotation.
+func (ic *IngressController) clusterHasProviderAnnotatedIngress(clusterName string) bool {
+	items, err := ic.ingressFederatedInformer.GetTargetStore().ByIndex(clusterName, providerStaticIPIndex, "true")
+	if err != nil {
+		glog.Errorf("Failed to query the provider static IP index in cluster %q: %v", clusterName, err)
+		return false
+	}
+	return len(items) > 0
+}
+
+// recordUIDElectionEvent records the chosen UID authority on all federated
+// ingresses, so that operators can see which strategy picked the UID.
+func (ic *IngressController) recordUIDElectionEvent(clusterName, ingressUID, reason string) {
+	for _, obj := range ic.ingressInformerStore.List() {
+		baseIngress, ok := obj.(*extensionsv1beta1.Ingress)
+		if !ok {
+			continue
+		}
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "IngressUIDElected",
+			"Cluster %s elected as ingress UID authority (%s) with uid %q", clusterName, reason, ingressUID)
+	}
+}
+
 /*
   updateClusterIngressUIDToMasters takes the ingress UID annotation on the master cluster and applies it to cluster.
   If there is no master cluster, then fallbackUID is used (and hence this cluster becomes the master).
@@ -632,6 +2904,7 @@ func (ic *IngressController) updateClusterIngressUIDToMasters(cluster *federatio
 				cluster.ObjectMeta.Annotations = map[string]string{}
 			}
 			cluster.ObjectMeta.Annotations[uidAnnotationKey] = masterUID
+			cluster.ObjectMeta.Annotations[uidAnnotationOwnerKey] = uidAnnotationOwnerValue
 			if _, err = ic.federatedApiClient.Federation().Clusters().Update(cluster); err != nil {
 				glog.Errorf("Failed to add master ingress UID annotation (%q = %q) from master cluster %q to cluster %q, will try again later: %v", uidAnnotationKey, masterUID, masterCluster.Name, cluster.Name, err)
 				return
@@ -648,6 +2921,7 @@ func (ic *IngressController) updateClusterIngressUIDToMasters(cluster *federatio
 				cluster.ObjectMeta.Annotations = map[string]string{}
 			}
 			cluster.ObjectMeta.Annotations[uidAnnotationKey] = fallbackUID
+			cluster.ObjectMeta.Annotations[uidAnnotationOwnerKey] = uidAnnotationOwnerValue
 			if _, err = ic.federatedApiClient.Federation().Clusters().Update(cluster); err != nil {
 				glog.Errorf("Failed to add ingress UID annotation (%q = %q) to cluster %q. No master elected. Will try again later: %v", uidAnnotationKey, fallbackUID, cluster.Name, err)
 			} else {
@@ -659,265 +2933,3499 @@ func (ic *IngressController) updateClusterIngressUIDToMasters(cluster *federatio
 	}
 }
 
-func (ic *IngressController) isClusterReady(clusterName string) bool {
-	cluster, isReady, err := ic.ingressFederatedInformer.GetReadyCluster(clusterName)
-	return isReady && err == nil && cluster != nil
+// clusterNamespace returns the namespace the given cluster hosts federated
+// objects of the given namespace in, per the cluster's namespace-mapping
+// annotation. The federated object's own namespace stays canonical everywhere
+// else - only the cluster copies move.
+func clusterNamespace(cluster *federationapi.Cluster, namespace string) string {
+	template, found := cluster.ObjectMeta.Annotations[namespaceMappingAnnotation]
+	if !found || template == "" {
+		return namespace
+	}
+	if !strings.Contains(template, namespaceMappingPlaceholder) {
+		// Without the placeholder all namespaces would collapse into one
+		// target namespace. Ignore the broken mapping.
+		glog.Errorf("Ignoring invalid %s annotation %q on cluster %q: missing the %s placeholder", namespaceMappingAnnotation, template, cluster.Name, namespaceMappingPlaceholder)
+		return namespace
+	}
+	return strings.Replace(template, namespaceMappingPlaceholder, namespace, -1)
 }
 
-// updateAnnotationOnIngress updates the annotation with the given key on the given federated ingress.
-// Queues the ingress for resync when done.
-func (ic *IngressController) updateAnnotationOnIngress(ingress *extensionsv1beta1.Ingress, key, value string) {
-	if ingress.ObjectMeta.Annotations == nil {
-		ingress.ObjectMeta.Annotations = make(map[string]string)
+// clusterIngressKey returns the target store key of the given federated
+// ingress's copy in the given cluster, honoring the cluster's namespace
+// mapping.
+func clusterIngressKey(cluster *federationapi.Cluster, ingress types.NamespacedName) string {
+	return types.NamespacedName{Namespace: clusterNamespace(cluster, ingress.Namespace), Name: ingress.Name}.String()
+}
+
+// clusterHasIngresses returns true if any ingress that is federated (i.e.
+// present in the federated ingress store) also has a copy in the given cluster.
+func (ic *IngressController) clusterHasIngresses(clusterName string) bool {
+	cluster, _, err := ic.ingressFederatedInformer.GetReadyCluster(clusterName)
+	if err != nil || cluster == nil {
+		return false
 	}
-	ingress.ObjectMeta.Annotations[key] = value
-	ingressName := types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}
-	glog.V(4).Infof("Attempting to update annotation %s:%s on base federated ingress: %v", key, value, ingressName)
-	if updatedFedIngress, err := ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Update(ingress); err != nil {
-		glog.Errorf("Failed to update annotation %s:%s on federated ingress %q, will try again later: %v", key, value, ingressName, err)
-		ic.deliverIngress(ingressName, ic.ingressReviewDelay, true)
-		return
-	} else {
-		glog.V(4).Infof("Successfully updated annotation %s:%s on federated ingress %q, after update: %q", key, value, ingress, updatedFedIngress)
-		ic.deliverIngress(ingressName, ic.smallDelay, false)
-		return
+	for _, obj := range ic.ingressInformerStore.List() {
+		baseIngress, ok := obj.(*extensionsv1beta1.Ingress)
+		if !ok {
+			continue
+		}
+		key := clusterIngressKey(cluster, types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name})
+		if _, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(clusterName, key); found && err == nil {
+			return true
+		}
 	}
+	return false
 }
 
-func (ic *IngressController) reconcileIngress(ingress types.NamespacedName) {
-	glog.V(4).Infof("Reconciling ingress %q for all clusters", ingress)
-	if !ic.isSynced() {
-		ic.deliverIngress(ingress, ic.clusterAvailableDelay, false)
-		return
+// ingressBackends returns the distinct service backends the ingress routes
+// to: the default backend and every rule path backend.
+func ingressBackends(ingress *extensionsv1beta1.Ingress) []extensionsv1beta1.IngressBackend {
+	backends := []extensionsv1beta1.IngressBackend{}
+	seen := sets.NewString()
+	add := func(backend *extensionsv1beta1.IngressBackend) {
+		if backend == nil || backend.ServiceName == "" {
+			return
+		}
+		key := fmt.Sprintf("%s/%s", backend.ServiceName, backend.ServicePort.String())
+		if seen.Has(key) {
+			return
+		}
+		seen.Insert(key)
+		backends = append(backends, *backend)
+	}
+	add(ingress.Spec.Backend)
+	for i := range ingress.Spec.Rules {
+		if http := ingress.Spec.Rules[i].HTTP; http != nil {
+			for j := range http.Paths {
+				add(&http.Paths[j].Backend)
+			}
+		}
 	}
+	return backends
+}
 
-	key := ingress.String()
-	baseIngressObjFromStore, exist, err := ic.ingressInformerStore.GetByKey(key)
+// servicePortMatchesBackend returns true if the given service port is the one
+// the ingress backend routes to, by name or by port number.
+func servicePortMatchesBackend(port v1.ServicePort, backend extensionsv1beta1.IngressBackend) bool {
+	if backend.ServicePort.Type == intstr.String {
+		return port.Name == backend.ServicePort.StrVal
+	}
+	return port.Port == backend.ServicePort.IntVal
+}
+
+// backendNodePort returns the NodePort the service exposes for the ingress
+// backend's port, or 0 when it cannot be resolved (e.g. the service is not of
+// a NodePort-carrying type).
+func backendNodePort(service *v1.Service, backend extensionsv1beta1.IngressBackend) int32 {
+	for _, port := range service.Spec.Ports {
+		if servicePortMatchesBackend(port, backend) {
+			return port.NodePort
+		}
+	}
+	return 0
+}
+
+// canonicalNodePort picks the NodePort the clusters should agree on: the one
+// most clusters already use, ties broken towards the lowest port so the
+// choice is stable across reconciliations.
+func canonicalNodePort(nodePortClusters map[int32][]string) int32 {
+	var canonical int32
+	for nodePort, clusterNames := range nodePortClusters {
+		if canonical == 0 || len(clusterNames) > len(nodePortClusters[canonical]) ||
+			(len(clusterNames) == len(nodePortClusters[canonical]) && nodePort < canonical) {
+			canonical = nodePort
+		}
+	}
+	return canonical
+}
+
+// rewriteServiceNodePort returns a copy of the service with the port matching
+// the ingress backend rewritten to the given NodePort.
+func rewriteServiceNodePort(service *v1.Service, backend extensionsv1beta1.IngressBackend, nodePort int32) (*v1.Service, error) {
+	serviceObj, err := api.Scheme.DeepCopy(service)
 	if err != nil {
-		glog.Errorf("Failed to query main ingress store for %v: %v", ingress, err)
-		ic.deliverIngress(ingress, 0, true)
-		return
+		return nil, err
 	}
-	if !exist {
-		// Not federated ingress, ignoring.
-		glog.V(4).Infof("Ingress %q is not federated.  Ignoring.", ingress)
+	rewritten := serviceObj.(*v1.Service)
+	for i := range rewritten.Spec.Ports {
+		if servicePortMatchesBackend(rewritten.Spec.Ports[i], backend) {
+			rewritten.Spec.Ports[i].NodePort = nodePort
+		}
+	}
+	return rewritten, nil
+}
+
+// deliverIngressesReferencingService redelivers the federated ingresses that
+// route to the given service, so that a service change in a cluster re-runs
+// the NodePort consistency check for the affected ingresses only.
+func (ic *IngressController) deliverIngressesReferencingService(obj pkgruntime.Object) {
+	service, ok := obj.(*v1.Service)
+	if !ok {
 		return
 	}
-	baseIngressObj, err := api.Scheme.DeepCopy(baseIngressObjFromStore)
-	baseIngress, ok := baseIngressObj.(*extensionsv1beta1.Ingress)
-	if err != nil || !ok {
-		glog.Errorf("Internal Error %v : Object retrieved from ingressInformerStore with key %q is not of correct type *extensionsv1beta1.Ingress: %v", err, key, baseIngressObj)
-	} else {
-		glog.V(4).Infof("Base (federated) ingress: %v", baseIngress)
+	for _, ingObj := range ic.ingressInformerStore.List() {
+		baseIngress, ok := ingObj.(*extensionsv1beta1.Ingress)
+		if !ok || baseIngress.Namespace != service.Namespace {
+			continue
+		}
+		for _, backend := range ingressBackends(baseIngress) {
+			if backend.ServiceName == service.Name {
+				ic.deliverIngress(types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}, ic.smallDelay, false)
+				break
+			}
+		}
 	}
+}
 
-	if baseIngress.DeletionTimestamp != nil {
-		if err := ic.delete(baseIngress); err != nil {
-			glog.Errorf("Failed to delete %s: %v", ingress, err)
-			ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "DeleteFailed",
-				"Ingress delete failed: %v", err)
-			ic.deliverIngress(ingress, 0, true)
+/*
+  checkBackendNodePortConsistency verifies that every backend service of the
+  federated ingress exposes the same NodePort in all given clusters. GLBC
+  health-checks all clusters of a shared global loadbalancer on one NodePort,
+  so a cluster that disagrees serves permanently unhealthy backends without
+  any visible error. Clusters disagreeing with the canonical NodePort get a
+  condition and an event names them; behind the FederationNodePortRewrite
+  gate their services are additionally rewritten to the canonical NodePort.
+*/
+func (ic *IngressController) checkBackendNodePortConsistency(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) {
+	backends := ingressBackends(baseIngress)
+	offending := sets.NewString()
+	mismatches := []string{}
+	operations := []util.FederatedOperation{}
+	for _, backend := range backends {
+		key := types.NamespacedName{Namespace: baseIngress.Namespace, Name: backend.ServiceName}.String()
+		nodePortClusters := map[int32][]string{}
+		clusterServices := map[string]*v1.Service{}
+		for _, cluster := range clusters {
+			serviceObj, found, err := ic.serviceFederatedInformer.GetTargetStore().GetByKey(cluster.Name, key)
+			if err != nil || !found {
+				continue
+			}
+			service, ok := serviceObj.(*v1.Service)
+			if !ok {
+				continue
+			}
+			nodePort := backendNodePort(service, backend)
+			if nodePort == 0 {
+				continue
+			}
+			nodePortClusters[nodePort] = append(nodePortClusters[nodePort], cluster.Name)
+			clusterServices[cluster.Name] = service
+		}
+		if len(nodePortClusters) <= 1 {
+			continue
+		}
+		canonical := canonicalNodePort(nodePortClusters)
+		for nodePort, clusterNames := range nodePortClusters {
+			if nodePort == canonical {
+				continue
+			}
+			sort.Strings(clusterNames)
+			offending.Insert(clusterNames...)
+			mismatches = append(mismatches, fmt.Sprintf("service %s port %s: clusters %s use NodePort %d, expected %d",
+				backend.ServiceName, backend.ServicePort.String(), strings.Join(clusterNames, ","), nodePort, canonical))
+			if ic.featureGates.FederationNodePortRewrite() {
+				for _, clusterName := range clusterNames {
+					rewritten, err := rewriteServiceNodePort(clusterServices[clusterName], backend, canonical)
+					if err != nil {
+						glog.Errorf("Failed to copy service %s in cluster %s for NodePort rewrite: %v", key, clusterName, err)
+						continue
+					}
+					operations = append(operations, util.FederatedOperation{
+						Type:        util.OperationTypeUpdate,
+						Obj:         rewritten,
+						ClusterName: clusterName,
+					})
+				}
+			}
 		}
+	}
+	sort.Strings(mismatches)
+	message := strings.Join(mismatches, "; ")
+	for _, cluster := range clusters {
+		ic.setClusterCondition(cluster.Name, clusterNodePortMismatchCondition, offending.Has(cluster.Name),
+			"BackendNodePortMismatch", message)
+	}
+	if len(mismatches) == 0 {
 		return
 	}
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "NodePortMismatch",
+		"Backend service NodePorts differ across clusters: %s", message)
+	if len(operations) > 0 {
+		glog.V(2).Infof("Rewriting mismatched backend service NodePorts for ingress %s/%s: %v", baseIngress.Namespace, baseIngress.Name, message)
+		if err := ic.federatedServiceUpdater.Update(operations, ic.updateTimeout); err != nil {
+			glog.Errorf("Failed to rewrite mismatched backend service NodePorts for ingress %s/%s: %v", baseIngress.Namespace, baseIngress.Name, err)
+		}
+	}
+}
 
-	glog.V(3).Infof("Ensuring delete object from underlying clusters finalizer for ingress: %s",
-		baseIngress.Name)
-	// Add the required finalizers before creating a ingress in underlying clusters.
-	updatedIngressObj, err := ic.deletionHelper.EnsureFinalizers(baseIngress)
+// ensureClusterFinalizer adds the ingress cleanup finalizer to the given
+// cluster if targeted is true and removes it otherwise. No-op if the cluster
+// is already in the desired state.
+func (ic *IngressController) ensureClusterFinalizer(cluster *federationapi.Cluster, targeted bool) error {
+	hasFinalizer := false
+	for _, finalizer := range cluster.ObjectMeta.Finalizers {
+		if finalizer == clusterIngressFinalizer {
+			hasFinalizer = true
+			break
+		}
+	}
+	if hasFinalizer == targeted {
+		return nil
+	}
+	clusterObj, err := api.Scheme.DeepCopy(cluster)
 	if err != nil {
-		glog.Errorf("Failed to ensure delete object from underlying clusters finalizer in ingress %s: %v",
-			baseIngress.Name, err)
-		ic.deliverIngress(ingress, 0, true)
-		return
+		return err
+	}
+	cluster = clusterObj.(*federationapi.Cluster)
+	if targeted {
+		cluster.ObjectMeta.Finalizers = append(cluster.ObjectMeta.Finalizers, clusterIngressFinalizer)
+	} else {
+		newFinalizers := []string{}
+		for _, finalizer := range cluster.ObjectMeta.Finalizers {
+			if finalizer != clusterIngressFinalizer {
+				newFinalizers = append(newFinalizers, finalizer)
+			}
+		}
+		cluster.ObjectMeta.Finalizers = newFinalizers
 	}
-	baseIngress = updatedIngressObj.(*extensionsv1beta1.Ingress)
+	if _, err := ic.federatedApiClient.Federation().Clusters().Update(cluster); err != nil {
+		return fmt.Errorf("failed to update finalizer %s on cluster %s: %v", clusterIngressFinalizer, cluster.Name, err)
+	}
+	return nil
+}
 
-	glog.V(3).Infof("Syncing ingress %s in underlying clusters", baseIngress.Name)
+// cleanupClusterUIDAnnotation removes the ingress UID annotation from the
+// given cluster, but only if federation wrote it, as recorded by the owner
+// marker. A UID annotation set by the user (no marker) is left alone. No-op if
+// there is nothing to remove.
+func (ic *IngressController) cleanupClusterUIDAnnotation(cluster *federationapi.Cluster) error {
+	if cluster.ObjectMeta.Annotations[uidAnnotationOwnerKey] != uidAnnotationOwnerValue {
+		return nil
+	}
+	clusterObj, err := api.Scheme.DeepCopy(cluster)
+	if err != nil {
+		return err
+	}
+	cluster = clusterObj.(*federationapi.Cluster)
+	delete(cluster.ObjectMeta.Annotations, uidAnnotationKey)
+	delete(cluster.ObjectMeta.Annotations, uidAnnotationOwnerKey)
+	if _, err := ic.federatedApiClient.Federation().Clusters().Update(cluster); err != nil {
+		return fmt.Errorf("failed to remove annotation %s from cluster %s: %v", uidAnnotationKey, cluster.Name, err)
+	}
+	return nil
+}
 
+// reconcileClusterFinalizers syncs the ingress cleanup finalizer on all ready
+// clusters with the set of clusters that currently host federated ingresses.
+// Clusters that no longer host federated ingresses also get the federation
+// written ingress UID annotation removed.
+func (ic *IngressController) reconcileClusterFinalizers() {
+	if !ic.clusterFinalizerEnabled || !ic.isSynced() {
+		return
+	}
 	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
 	if err != nil {
-		glog.Errorf("Failed to get cluster list: %v", err)
-		ic.deliverIngress(ingress, ic.clusterAvailableDelay, false)
+		glog.Errorf("Failed to get ready clusters while reconciling cluster finalizers: %v", err)
 		return
-	} else {
-		glog.V(4).Infof("Found %d ready clusters across which to reconcile ingress %q", len(clusters), ingress)
 	}
-
-	operations := make([]util.FederatedOperation, 0)
-
 	for _, cluster := range clusters {
-		baseIPName, baseIPAnnotationExists := baseIngress.ObjectMeta.Annotations[staticIPNameKeyWritable]
-		firstClusterName, firstClusterExists := baseIngress.ObjectMeta.Annotations[firstClusterAnnotation]
-		clusterIngressObj, clusterIngressFound, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, key)
-		if err != nil {
-			glog.Errorf("Failed to get cached ingress %s for cluster %s, will retry: %v", ingress, cluster.Name, err)
-			ic.deliverIngress(ingress, 0, true)
-			return
+		targeted := ic.clusterHasIngresses(cluster.Name)
+		if err := ic.ensureClusterFinalizer(cluster, targeted); err != nil {
+			glog.Errorf("Failed to reconcile finalizer on cluster %s, will retry on next reconciliation: %v", cluster.Name, err)
 		}
-		desiredIngress := &extensionsv1beta1.Ingress{}
-		objMeta, err := api.Scheme.DeepCopy(&baseIngress.ObjectMeta)
-		if err != nil {
-			glog.Errorf("Error deep copying ObjectMeta: %v", err)
+		if !targeted {
+			if err := ic.cleanupClusterUIDAnnotation(cluster); err != nil {
+				glog.Errorf("Failed to clean up ingress UID annotation on cluster %s, will retry on next reconciliation: %v", cluster.Name, err)
+			}
 		}
-		objSpec, err := api.Scheme.DeepCopy(&baseIngress.Spec)
-		if err != nil {
-			glog.Errorf("Error deep copying Spec: %v", err)
+	}
+}
+
+func (ic *IngressController) isClusterReady(clusterName string) bool {
+	cluster, isReady, err := ic.ingressFederatedInformer.GetReadyCluster(clusterName)
+	return isReady && err == nil && cluster != nil
+}
+
+// clusterWritesAllowed returns true only if the most recent Ready condition
+// recorded on the cluster is ConditionTrue. Unlike the informer's readiness
+// check, which accepts any Ready=True condition in the history, this treats a
+// cluster whose latest probe found it not ready as a hard stop for writes.
+//
+// The probe timestamps are wall-clock times stamped by whichever cluster
+// controller process wrote the condition, so after a leader failover between
+// skewed hosts they need not be mutually comparable. Conditions whose probe
+// times lie within skewTolerance of the newest probe time are therefore
+// considered concurrent with it, and among those the one appended later to
+// the condition list wins: append order is process-local and unaffected by
+// clock skew. The tolerance is anchored at the newest probe time so that it
+// cannot chain - a condition older than the newest by more than the
+// tolerance never wins, no matter how the probe times in between are spaced.
+func clusterWritesAllowed(cluster *federationapi.Cluster, skewTolerance time.Duration) bool {
+	var maxProbeTime time.Time
+	foundReady := false
+	for i := range cluster.Status.Conditions {
+		condition := &cluster.Status.Conditions[i]
+		if condition.Type != federationapi.ClusterReady {
+			continue
 		}
-		objMetaCopy, ok := objMeta.(*v1.ObjectMeta)
-		if !ok {
-			glog.Errorf("Internal error: Failed to cast to *v1.ObjectMeta: %v", objMeta)
+		if !foundReady || condition.LastProbeTime.Time.After(maxProbeTime) {
+			maxProbeTime = condition.LastProbeTime.Time
+			foundReady = true
 		}
-		desiredIngress.ObjectMeta = *objMetaCopy
-		objSpecCopy, ok := objSpec.(*extensionsv1beta1.IngressSpec)
-		if !ok {
-			glog.Errorf("Internal error: Failed to cast to extensionsv1beta1.Ingressespec: %v", objSpec)
+	}
+	if !foundReady {
+		return false
+	}
+	var latest *federationapi.ClusterCondition
+	for i := range cluster.Status.Conditions {
+		condition := &cluster.Status.Conditions[i]
+		if condition.Type != federationapi.ClusterReady {
+			continue
 		}
-		desiredIngress.Spec = *objSpecCopy
-		glog.V(4).Infof("Desired Ingress: %v", desiredIngress)
-
-		if !clusterIngressFound {
-			glog.V(4).Infof("No existing Ingress %s in cluster %s - checking if appropriate to queue a create operation", ingress, cluster.Name)
-			// We can't supply server-created fields when creating a new object.
-			desiredIngress.ObjectMeta = util.DeepCopyRelevantObjectMeta(baseIngress.ObjectMeta)
-			ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "CreateInCluster",
-				"Creating ingress in cluster %s", cluster.Name)
+		if !condition.LastProbeTime.Time.Before(maxProbeTime.Add(-skewTolerance)) {
+			latest = condition
+		}
+	}
+	return latest != nil && latest.Status == v1.ConditionTrue
+}
 
-			// We always first create an ingress in the first available cluster. Once that ingress
-			// has been created and allocated a global IP (visible via an annotation),
-			// we record that annotation on the federated ingress, and create all other cluster
-			// ingresses with that same global IP.
-			// Note: If the first cluster becomes (e.g. temporarily) unavailable, the
-			// second cluster will become the first cluster, but eventually all ingresses
-			// will share the single global IP recorded in the annotation of the
-			// federated ingress.
-			haveFirstCluster := firstClusterExists && firstClusterName != "" && ic.isClusterReady(firstClusterName)
-			if !haveFirstCluster {
-				glog.V(4).Infof("No cluster has been chosen as the first cluster. Electing cluster %s as the first cluster to create ingress in", cluster.Name)
-				ic.updateAnnotationOnIngress(baseIngress, firstClusterAnnotation, cluster.Name)
-				return
+// logNotReadyClusterSkip logs that writes to the given cluster are being
+// skipped, at most once per notReadySkipLogInterval per cluster.
+func (ic *IngressController) logNotReadyClusterSkip(clusterName string) {
+	ic.notReadySkipLogLock.Lock()
+	defer ic.notReadySkipLogLock.Unlock()
+	if last, found := ic.notReadySkipLogTimes[clusterName]; !found || ic.clock.Since(last) >= notReadySkipLogInterval {
+		ic.logger.WithCluster(clusterName).Warningf("Skipping writes to not-ready cluster")
+		ic.notReadySkipLogTimes[clusterName] = ic.clock.Now()
+	}
+}
+
+// filterValidatedOperations consults the optional PrePropagationValidator for
+// every queued create and update, dropping the operations it rejects so that
+// propagation to the remaining clusters proceeds normally.
+func (ic *IngressController) filterValidatedOperations(baseIngress *extensionsv1beta1.Ingress, operations []util.FederatedOperation) []util.FederatedOperation {
+	if ic.PrePropagationValidator == nil {
+		return operations
+	}
+	result := make([]util.FederatedOperation, 0, len(operations))
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeAdd || operation.Type == util.OperationTypeUpdate {
+			if desiredIngress, ok := operation.Obj.(*extensionsv1beta1.Ingress); ok {
+				if err := ic.PrePropagationValidator(desiredIngress, operation.ClusterName); err != nil {
+					ic.logger.WithKey(types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}.String()).WithCluster(operation.ClusterName).V(2).Infof("Propagation blocked by validator: %v", err)
+					ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "PropagationBlocked",
+						"Propagation to cluster %s blocked by validator: %v", operation.ClusterName, err)
+					continue
+				}
 			}
-			if baseIPAnnotationExists || firstClusterName == cluster.Name {
-				if baseIPAnnotationExists {
-					glog.V(4).Infof("No existing Ingress %s in cluster %s and static IP annotation (%q) exists on base ingress - queuing a create operation", ingress, cluster.Name, staticIPNameKeyWritable)
-				} else {
-					glog.V(4).Infof("No existing Ingress %s in cluster %s and no static IP annotation (%q) on base ingress - queuing a create operation in first cluster", ingress, cluster.Name, staticIPNameKeyWritable)
+		}
+		result = append(result, operation)
+	}
+	return result
+}
+
+// serverSideApplyAvailable reports whether cluster ingress writes through the
+// given client may use server-side apply. It is false unless UseServerSideApply
+// is configured; otherwise the cluster's api server is probed once (via the
+// ServerSideApplySupported hook when set, else by its reported version) and
+// the result is cached for the lifetime of the client.
+func (ic *IngressController) serverSideApplyAvailable(client kubeclientset.Interface) bool {
+	if !ic.useServerSideApply {
+		return false
+	}
+	ic.ssaSupportLock.Lock()
+	defer ic.ssaSupportLock.Unlock()
+	if supported, found := ic.ssaSupport[client]; found {
+		return supported
+	}
+	var supported bool
+	if ic.ServerSideApplySupported != nil {
+		supported = ic.ServerSideApplySupported(client)
+	} else {
+		supported = serverVersionSupportsApply(client)
+	}
+	if ic.ssaSupport == nil {
+		ic.ssaSupport = make(map[kubeclientset.Interface]bool)
+	}
+	ic.ssaSupport[client] = supported
+	return supported
+}
+
+// serverVersionSupportsApply probes the api server version behind the given
+// client and reports whether it is recent enough to understand server-side
+// apply. Probe failures count as unsupported, so writes fall back to plain
+// create/update rather than failing.
+func serverVersionSupportsApply(client kubeclientset.Interface) bool {
+	version, err := client.Discovery().ServerVersion()
+	if err != nil {
+		glog.V(2).Infof("Failed to probe server version for server-side apply support, assuming unsupported: %v", err)
+		return false
+	}
+	major, err := strconv.Atoi(strings.TrimSuffix(version.Major, "+"))
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(strings.TrimSuffix(version.Minor, "+"))
+	if err != nil {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= serverSideApplyMinMinorVersion)
+}
+
+// applyIngress writes the desired ingress to the cluster behind the given
+// client with server-side apply, returning whether the write was handled this
+// way. When apply is unavailable for the cluster it returns (false, nil) and
+// the caller falls back to plain create/update. The generated 1.5 clients
+// cannot pass a field manager with the patch, so federation's manager
+// identity is recorded in the log line for now.
+func (ic *IngressController) applyIngress(client kubeclientset.Interface, ingress *extensionsv1beta1.Ingress) (bool, error) {
+	if !ic.serverSideApplyAvailable(client) {
+		return false, nil
+	}
+	data, err := json.Marshal(ingress)
+	if err != nil {
+		return true, err
+	}
+	glog.V(4).Infof("Applying ingress %q as field manager %q",
+		types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}, serverSideApplyFieldManager)
+	_, err = client.Extensions().Ingresses(ingress.Namespace).Patch(ingress.Name, serverSideApplyPatchType, data)
+	return true, err
+}
+
+// aggregatedLoadBalancerStatus combines the loadbalancer statuses currently
+// reported by the cluster copies of the given ingress into a single federated
+// status. Clusters are visited in name order and duplicate addresses are
+// dropped; the configured merge order decides whether the result is
+// additionally sorted by address or left in first-seen order. When the
+// federated ingress names a ready status source cluster, that cluster's
+// status is returned verbatim instead of the merge. The result is capped to
+// MaxStatusIngressEntries when a cap is configured.
+func (ic *IngressController) aggregatedLoadBalancerStatus(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) v1.LoadBalancerStatus {
+	status := ic.uncappedLoadBalancerStatus(baseIngress, clusters)
+	ic.capLoadBalancerStatus(&status)
+	return status
+}
+
+// uncappedLoadBalancerStatus is aggregatedLoadBalancerStatus without the
+// MaxStatusIngressEntries cap, used where the number of dropped addresses
+// must be known.
+func (ic *IngressController) uncappedLoadBalancerStatus(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) v1.LoadBalancerStatus {
+	ingress := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}
+	if sourceCluster, ok := statusSourceCluster(baseIngress, clusters); ok {
+		clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(sourceCluster.Name, clusterIngressKey(sourceCluster, ingress))
+		if err == nil && found {
+			if clusterIngress, ok := clusterIngressObj.(*extensionsv1beta1.Ingress); ok {
+				status := ic.extractClusterStatus(sourceCluster.Name, clusterIngress)
+				return v1.LoadBalancerStatus{Ingress: append([]v1.LoadBalancerIngress{}, status.Ingress...)}
+			}
+		}
+		return v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{}}
+	}
+	sortedClusters := make([]*federationapi.Cluster, len(clusters))
+	copy(sortedClusters, clusters)
+	sort.Sort(clustersByName(sortedClusters))
+	aggregated := v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{}}
+	seen := sets.NewString()
+	for _, cluster := range sortedClusters {
+		clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, clusterIngressKey(cluster, ingress))
+		if err != nil || !found {
+			continue
+		}
+		if clusterIngress, ok := clusterIngressObj.(*extensionsv1beta1.Ingress); ok {
+			status := ic.extractClusterStatus(cluster.Name, clusterIngress)
+			for _, address := range status.Ingress {
+				key := address.IP + "|" + address.Hostname
+				if seen.Has(key) {
+					continue
 				}
-				operations = append(operations, util.FederatedOperation{
-					Type:        util.OperationTypeAdd,
-					Obj:         desiredIngress,
-					ClusterName: cluster.Name,
-				})
-			} else {
-				glog.V(4).Infof("No annotation %q exists on ingress %q in federation and waiting for ingress in cluster %s. Not queueing create operation for ingress until annotation exists", staticIPNameKeyWritable, ingress, firstClusterName)
+				seen.Insert(key)
+				aggregated.Ingress = append(aggregated.Ingress, address)
+			}
+		}
+	}
+	if ic.lbStatusMergeOrder != LBStatusMergePreserveFirstSeen {
+		sort.Sort(lbIngressByAddress(aggregated.Ingress))
+	}
+	return aggregated
+}
+
+// capLoadBalancerStatus truncates the given status to MaxStatusIngressEntries
+// addresses, keeping the first entries so the subset is deterministic for a
+// deterministically ordered input. It returns the number of addresses
+// dropped; 0 when no cap is configured or the status already fits.
+func (ic *IngressController) capLoadBalancerStatus(status *v1.LoadBalancerStatus) int {
+	if ic.maxStatusIngressEntries <= 0 || len(status.Ingress) <= ic.maxStatusIngressEntries {
+		return 0
+	}
+	dropped := len(status.Ingress) - ic.maxStatusIngressEntries
+	status.Ingress = status.Ingress[:ic.maxStatusIngressEntries]
+	return dropped
+}
+
+// statusSourceCluster returns the cluster named by the status-source-cluster
+// annotation on the given federated ingress, provided that cluster is among
+// the given (ready) clusters. It returns false when the annotation is absent
+// or names a cluster that is not currently ready, in which case the federated
+// status is derived from the merge as usual.
+func statusSourceCluster(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) (*federationapi.Cluster, bool) {
+	name, found := baseIngress.ObjectMeta.Annotations[statusSourceClusterAnnotation]
+	if !found || name == "" {
+		return nil, false
+	}
+	for _, cluster := range clusters {
+		if cluster.Name == name {
+			return cluster, true
+		}
+	}
+	return nil, false
+}
+
+// clustersByName sorts clusters lexicographically by name.
+type clustersByName []*federationapi.Cluster
+
+func (c clustersByName) Len() int           { return len(c) }
+func (c clustersByName) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c clustersByName) Less(i, j int) bool { return c[i].Name < c[j].Name }
+
+// lbIngressByAddress sorts loadbalancer addresses by IP, then hostname.
+type lbIngressByAddress []v1.LoadBalancerIngress
+
+func (l lbIngressByAddress) Len() int      { return len(l) }
+func (l lbIngressByAddress) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l lbIngressByAddress) Less(i, j int) bool {
+	if l[i].IP != l[j].IP {
+		return l[i].IP < l[j].IP
+	}
+	return l[i].Hostname < l[j].Hostname
+}
+
+// extractClusterStatus returns the loadbalancer status the given cluster copy
+// reports, running it through the configured ClusterStatusExtractor when one
+// is set.
+func (ic *IngressController) extractClusterStatus(clusterName string, clusterIngress *extensionsv1beta1.Ingress) v1.LoadBalancerStatus {
+	if ic.clusterStatusExtractor != nil {
+		return ic.clusterStatusExtractor(clusterName, clusterIngress)
+	}
+	return clusterIngress.Status.LoadBalancer
+}
+
+// primaryCopyReady reports whether the copy of the ingress in the designated
+// primary cluster satisfies the readiness predicate. A primary cluster that
+// is not among the clusters being reconciled (not ready, or excluded) does
+// not hold the others back.
+func (ic *IngressController) primaryCopyReady(baseIngress *extensionsv1beta1.Ingress, primaryClusterName string, clusters []*federationapi.Cluster) bool {
+	var primaryCluster *federationapi.Cluster
+	for _, cluster := range clusters {
+		if cluster.Name == primaryClusterName {
+			primaryCluster = cluster
+			break
+		}
+	}
+	if primaryCluster == nil {
+		return true
+	}
+	ingressName := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}
+	obj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(primaryClusterName, clusterIngressKey(primaryCluster, ingressName))
+	if err != nil || !found {
+		return false
+	}
+	primaryCopy, ok := obj.(*extensionsv1beta1.Ingress)
+	if !ok {
+		return false
+	}
+	if ic.primaryReadyPredicate != nil {
+		return ic.primaryReadyPredicate(primaryCopy)
+	}
+	return len(ic.extractClusterStatus(primaryClusterName, primaryCopy).Ingress) > 0
+}
+
+// namespaceTerminating returns true if the federated namespace of the given
+// name is known to be in the Terminating phase (or carries a deletion
+// timestamp). Unknown namespaces are reported as not terminating, as the
+// namespace informer may simply not have seen them yet.
+func (ic *IngressController) namespaceTerminating(namespace string) bool {
+	namespaceObj, exists, err := ic.namespaceInformerStore.GetByKey(namespace)
+	if err != nil || !exists {
+		return false
+	}
+	ns, ok := namespaceObj.(*v1.Namespace)
+	if !ok {
+		return false
+	}
+	return ns.Status.Phase == v1.NamespaceTerminating || ns.ObjectMeta.DeletionTimestamp != nil
+}
+
+// deleteProtected returns true if the given ingress, or its federated
+// namespace, carries the cascading delete protection annotation.
+func (ic *IngressController) deleteProtected(baseIngress *extensionsv1beta1.Ingress) bool {
+	if baseIngress.ObjectMeta.Annotations[deleteProtectionAnnotation] == deleteProtectionCascading {
+		return true
+	}
+	namespaceObj, exists, err := ic.namespaceInformerStore.GetByKey(baseIngress.Namespace)
+	if err != nil || !exists {
+		return false
+	}
+	if ns, ok := namespaceObj.(*v1.Namespace); ok {
+		return ns.ObjectMeta.Annotations[deleteProtectionAnnotation] == deleteProtectionCascading
+	}
+	return false
+}
+
+// cascadingDeleteBlocked returns true if a cascading delete is in progress for
+// a protected ingress without the required confirmation annotation. Orphaning
+// deletes (the orphan finalizer is still present) are never blocked.
+func (ic *IngressController) cascadingDeleteBlocked(baseIngress *extensionsv1beta1.Ingress) bool {
+	if !ic.deleteProtected(baseIngress) {
+		return false
+	}
+	if _, confirmed := baseIngress.ObjectMeta.Annotations[confirmCascadingDeleteAnnotation]; confirmed {
+		return false
+	}
+	// The apiserver removes the orphan finalizer for cascading deletes; if it
+	// is still present the deletion helper will orphan the copies anyway.
+	return ic.hasFinalizerFunc(baseIngress, deletionhelper.FinalizerDeleteFromUnderlyingClusters) &&
+		!ic.hasFinalizerFunc(baseIngress, v1.FinalizerOrphan)
+}
+
+// orphan removes the remaining finalizers from the given ingress without
+// touching its cluster copies, letting the federated object be deleted while
+// the copies keep serving traffic.
+func (ic *IngressController) orphan(baseIngress *extensionsv1beta1.Ingress) error {
+	if !ic.federatedWritesAllowed {
+		// Orphaning only touches the federated object - leave it entirely to
+		// the writer shard.
+		return nil
+	}
+	obj, err := ic.removeFinalizerFunc(baseIngress, deletionhelper.FinalizerDeleteFromUnderlyingClusters)
+	if err != nil {
+		return err
+	}
+	baseIngress = obj.(*extensionsv1beta1.Ingress)
+	err = ic.federatedApiClient.Extensions().Ingresses(baseIngress.Namespace).Delete(baseIngress.Name, nil)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ingress: %v", err)
+	}
+	return nil
+}
+
+// filterTerminatingNamespaceOperations drops create and update operations for
+// ingresses whose namespace is being deleted; the namespace deletion cascade
+// in the member clusters takes care of the cleanup.
+func (ic *IngressController) filterTerminatingNamespaceOperations(baseIngress *extensionsv1beta1.Ingress, operations []util.FederatedOperation) []util.FederatedOperation {
+	if !ic.namespaceTerminating(baseIngress.Namespace) {
+		return operations
+	}
+	logger := ic.logger.WithKey(types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}.String())
+	result := make([]util.FederatedOperation, 0, len(operations))
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeAdd || operation.Type == util.OperationTypeUpdate {
+			logger.WithCluster(operation.ClusterName).V(4).Infof("Skipping %s: namespace is terminating", operation.Type)
+			continue
+		}
+		result = append(result, operation)
+	}
+	return result
+}
+
+// filterNotReadyClusterOperations drops queued operations that target clusters
+// whose latest Ready condition is not ConditionTrue. Reads from the cached
+// cluster state are unaffected - only writes are stopped.
+func (ic *IngressController) filterNotReadyClusterOperations(operations []util.FederatedOperation) []util.FederatedOperation {
+	result := make([]util.FederatedOperation, 0, len(operations))
+	for _, operation := range operations {
+		cluster, found, err := ic.ingressFederatedInformer.GetReadyCluster(operation.ClusterName)
+		if err != nil || !found || !clusterWritesAllowed(cluster, ic.clockSkewTolerance) {
+			ic.logNotReadyClusterSkip(operation.ClusterName)
+			continue
+		}
+		result = append(result, operation)
+	}
+	return result
+}
+
+// tlsSecretNames returns the names of the TLS secrets referenced by the given
+// ingress spec, in spec order.
+func tlsSecretNames(ingress *extensionsv1beta1.Ingress) []string {
+	names := []string{}
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" {
+			names = append(names, tls.SecretName)
+		}
+	}
+	return names
+}
+
+// missingTLSSecrets probes every targeted cluster for the TLS secrets the
+// federated ingress references and returns a "cluster/secret" entry for each
+// one that is absent. The secrets are read live - federation runs no secret
+// informers against the member clusters - which is acceptable because the
+// probe only runs for ingresses that opt into the TLS gate and only while
+// creates are pending.
+func (ic *IngressController) missingTLSSecrets(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) []string {
+	secretNames := tlsSecretNames(baseIngress)
+	missing := []string{}
+	for _, cluster := range clusters {
+		client, err := ic.ingressFederatedInformer.GetClientsetForCluster(cluster.Name)
+		if err != nil {
+			missing = append(missing, fmt.Sprintf("%s: failed to get client: %v", cluster.Name, err))
+			continue
+		}
+		for _, secretName := range secretNames {
+			if _, err := client.Core().Secrets(baseIngress.Namespace).Get(secretName, metav1.GetOptions{}); err != nil {
+				missing = append(missing, fmt.Sprintf("%s/%s", cluster.Name, secretName))
+			}
+		}
+	}
+	return missing
+}
+
+// filterTLSGatedOperations implements the all-or-nothing TLS gate: for a
+// federated ingress carrying the require-tls-secret annotation, creates are
+// withheld from every cluster until each referenced TLS secret exists in
+// every targeted cluster. This is distinct from (and stricter than) checking
+// the secret only in the cluster being written to: it prevents a subset of
+// clusters from serving the hostname before TLS material is in place
+// everywhere. Updates of existing copies pass through - the exposure decision
+// was already made when the copy was created.
+func (ic *IngressController) filterTLSGatedOperations(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster, operations []util.FederatedOperation) []util.FederatedOperation {
+	if baseIngress.ObjectMeta.Annotations[requireTLSSecretAnnotation] != "true" || len(tlsSecretNames(baseIngress)) == 0 {
+		return operations
+	}
+	hasCreates := false
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeAdd {
+			hasCreates = true
+			break
+		}
+	}
+	if !hasCreates {
+		return operations
+	}
+	missing := ic.missingTLSSecrets(baseIngress, clusters)
+	if len(missing) == 0 {
+		return operations
+	}
+	glog.V(2).Infof("Withholding ingress %s/%s from all clusters: TLS secrets missing: %s",
+		baseIngress.Namespace, baseIngress.Name, strings.Join(missing, ", "))
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "TLSSecretGatePending",
+		"Withholding ingress from all clusters until the referenced TLS secrets exist everywhere; missing: %s", strings.Join(missing, ", "))
+	result := make([]util.FederatedOperation, 0, len(operations))
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeAdd {
+			continue
+		}
+		result = append(result, operation)
+	}
+	// No watch surfaces the secrets appearing; poll.
+	ic.deliverIngress(types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}, ic.ingressReviewDelay, false)
+	return result
+}
+
+// isQuotaExceededError returns true if the given error is a quota rejection
+// from a member cluster's api server. Quota rejections come back as Forbidden
+// with a message naming the exceeded quota.
+func isQuotaExceededError(err error) bool {
+	return err != nil && errors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
+}
+
+// quotaBlockKey builds the key under which a (cluster, namespace) quota block
+// is tracked.
+func quotaBlockKey(clusterName, namespace string) string {
+	return clusterName + "/" + namespace
+}
+
+// markQuotaBlocked records a quota rejection for the given cluster and
+// namespace: further creates and updates into the pair are short-circuited
+// until the escalating backoff window expires, the cluster's QuotaBlocked
+// condition is refreshed and the block is published as a metric. Returns how
+// long writes into the pair stay blocked.
+func (ic *IngressController) markQuotaBlocked(clusterName, namespace string) time.Duration {
+	key := quotaBlockKey(clusterName, namespace)
+	ic.quotaBlockLock.Lock()
+	ic.quotaBackoff.Next(key, ic.clock.Now())
+	delay := ic.quotaBackoff.Get(key)
+	ic.quotaBlocked[key] = ic.clock.Now().Add(delay)
+	blocked := ic.blockedNamespacesLocked(clusterName)
+	recordQuotaBlockedNamespaces(clusterName, len(blocked))
+	ic.quotaBlockLock.Unlock()
+	ic.setClusterCondition(clusterName, clusterQuotaBlockedCondition, true, "QuotaExceeded",
+		fmt.Sprintf("Ingress propagation blocked by exhausted resource quota in namespaces: %s", strings.Join(blocked, ", ")))
+	return delay
+}
+
+// clearQuotaBlock clears the quota block of the given cluster and namespace
+// after a successful write, resetting its backoff and withdrawing the
+// cluster's QuotaBlocked condition once no blocked namespaces remain.
+func (ic *IngressController) clearQuotaBlock(clusterName, namespace string) {
+	key := quotaBlockKey(clusterName, namespace)
+	ic.quotaBlockLock.Lock()
+	if _, found := ic.quotaBlocked[key]; !found {
+		ic.quotaBlockLock.Unlock()
+		return
+	}
+	delete(ic.quotaBlocked, key)
+	ic.quotaBackoff.Reset(key)
+	blocked := ic.blockedNamespacesLocked(clusterName)
+	recordQuotaBlockedNamespaces(clusterName, len(blocked))
+	ic.quotaBlockLock.Unlock()
+	glog.V(2).Infof("Quota block for namespace %q in cluster %q cleared after a successful write", namespace, clusterName)
+	if len(blocked) == 0 {
+		ic.setClusterCondition(clusterName, clusterQuotaBlockedCondition, false, "QuotaExceeded", "")
+	}
+}
+
+// blockedNamespacesLocked returns the sorted namespaces of the given cluster
+// currently quota-blocked. Callers must hold quotaBlockLock.
+func (ic *IngressController) blockedNamespacesLocked(clusterName string) []string {
+	namespaces := []string{}
+	prefix := clusterName + "/"
+	for key := range ic.quotaBlocked {
+		if strings.HasPrefix(key, prefix) {
+			namespaces = append(namespaces, strings.TrimPrefix(key, prefix))
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// QuotaBlockedNamespaces returns the currently quota-blocked namespaces per
+// cluster, for introspection by embedders and tests.
+func (ic *IngressController) QuotaBlockedNamespaces() map[string][]string {
+	ic.quotaBlockLock.Lock()
+	defer ic.quotaBlockLock.Unlock()
+	result := make(map[string][]string)
+	for key := range ic.quotaBlocked {
+		parts := strings.SplitN(key, "/", 2)
+		result[parts[0]] = append(result[parts[0]], parts[1])
+	}
+	for _, namespaces := range result {
+		sort.Strings(namespaces)
+	}
+	return result
+}
+
+// filterQuotaBlockedOperations drops creates and updates into (cluster,
+// namespace) pairs whose quota block window has not expired yet, scheduling a
+// redelivery for when the earliest probe is due. A pair whose window has
+// expired lets its operation through as the probe: a single write that either
+// clears the block or re-arms it with a longer window.
+func (ic *IngressController) filterQuotaBlockedOperations(baseIngress *extensionsv1beta1.Ingress, operations []util.FederatedOperation) []util.FederatedOperation {
+	logger := ic.logger.WithKey(types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}.String())
+	var probeDelay time.Duration
+	result := make([]util.FederatedOperation, 0, len(operations))
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeAdd || operation.Type == util.OperationTypeUpdate {
+			ic.quotaBlockLock.Lock()
+			until, blocked := ic.quotaBlocked[quotaBlockKey(operation.ClusterName, baseIngress.Namespace)]
+			ic.quotaBlockLock.Unlock()
+			if blocked {
+				if remaining := until.Sub(ic.clock.Now()); remaining > 0 {
+					logger.WithCluster(operation.ClusterName).V(4).Infof("Skipping %s: namespace is quota-blocked for another %v", operation.Type, remaining)
+					if probeDelay == 0 || remaining < probeDelay {
+						probeDelay = remaining
+					}
+					continue
+				}
+				logger.WithCluster(operation.ClusterName).V(4).Infof("Probing the quota-blocked namespace with a single %s", operation.Type)
+			}
+		}
+		result = append(result, operation)
+	}
+	if probeDelay > 0 {
+		ic.deliverIngress(types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}, probeDelay, false)
+	}
+	return result
+}
+
+// maxFederatedUpdateAttempts bounds the refetch-and-replay retries of the
+// consolidated federated ingress update on resource version conflicts.
+const maxFederatedUpdateAttempts = 3
+
+// federatedIngressMutation is one staged metadata change to the federated
+// ingress, replayable onto a refetched object after a version conflict.
+type federatedIngressMutation struct {
+	description string
+	apply       func(*extensionsv1beta1.Ingress) bool
+}
+
+/*
+  stagedIngressUpdate collects the federation-side metadata mutations of one
+  reconciliation pass - finalizers, the first-cluster election, static IP and
+  marker annotations - so that the pass issues a single federation api update
+  for all of them instead of one write each. Mutations are applied to the
+  in-memory ingress immediately, so the remainder of the pass observes them;
+  flushStagedIngressUpdate makes them durable.
+*/
+type stagedIngressUpdate struct {
+	ingress   *extensionsv1beta1.Ingress
+	mutations []federatedIngressMutation
+}
+
+func newStagedIngressUpdate(ingress *extensionsv1beta1.Ingress) *stagedIngressUpdate {
+	return &stagedIngressUpdate{ingress: ingress}
+}
+
+// stage applies the mutation to the staged ingress and records it for replay
+// on conflict. Mutations that report no change are dropped, so staging is
+// idempotent and an unchanged ingress costs no write.
+func (s *stagedIngressUpdate) stage(description string, apply func(*extensionsv1beta1.Ingress) bool) {
+	if !apply(s.ingress) {
+		return
+	}
+	s.mutations = append(s.mutations, federatedIngressMutation{description: description, apply: apply})
+}
+
+// setAnnotation stages setting the annotation; no-op if the value is already set.
+func (s *stagedIngressUpdate) setAnnotation(key, value string) {
+	s.stage(fmt.Sprintf("annotation %s=%s", key, value), func(ingress *extensionsv1beta1.Ingress) bool {
+		if ingress.ObjectMeta.Annotations[key] == value {
+			return false
+		}
+		if ingress.ObjectMeta.Annotations == nil {
+			ingress.ObjectMeta.Annotations = make(map[string]string)
+		}
+		ingress.ObjectMeta.Annotations[key] = value
+		return true
+	})
+}
+
+// removeAnnotation stages removal of the annotation; no-op if it is absent.
+func (s *stagedIngressUpdate) removeAnnotation(key string) {
+	s.stage(fmt.Sprintf("remove annotation %s", key), func(ingress *extensionsv1beta1.Ingress) bool {
+		if _, found := ingress.ObjectMeta.Annotations[key]; !found {
+			return false
+		}
+		delete(ingress.ObjectMeta.Annotations, key)
+		return true
+	})
+}
+
+// addFinalizer stages addition of the finalizer; no-op if already present.
+func (s *stagedIngressUpdate) addFinalizer(finalizer string) {
+	s.stage(fmt.Sprintf("finalizer %s", finalizer), func(ingress *extensionsv1beta1.Ingress) bool {
+		for _, existing := range ingress.ObjectMeta.Finalizers {
+			if existing == finalizer {
+				return false
+			}
+		}
+		ingress.ObjectMeta.Finalizers = append(ingress.ObjectMeta.Finalizers, finalizer)
+		return true
+	})
+}
+
+func (s *stagedIngressUpdate) empty() bool {
+	return len(s.mutations) == 0
+}
+
+func (s *stagedIngressUpdate) descriptions() string {
+	parts := make([]string, 0, len(s.mutations))
+	for _, mutation := range s.mutations {
+		parts = append(parts, mutation.description)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// flushStagedIngressUpdate issues the single federation api write for all
+// mutations staged during a reconciliation pass, or none when nothing was
+// staged. Version conflicts are retried by refetching the latest federated
+// ingress and replaying the staged mutations onto it, so consolidating the
+// writes does not clobber concurrent changes. Returns the ingress the rest
+// of the pass should continue with.
+func (ic *IngressController) flushStagedIngressUpdate(staged *stagedIngressUpdate) (*extensionsv1beta1.Ingress, error) {
+	if staged.empty() {
+		return staged.ingress, nil
+	}
+	if !ic.federatedWritesAllowed {
+		// A non-writer shard leaves the federated object to the writer shard
+		// and continues the pass against the unmodified ingress.
+		glog.V(4).Infof("Not updating federated ingress %s/%s (%s): this instance is a non-writer shard", staged.ingress.Namespace, staged.ingress.Name, staged.descriptions())
+		return staged.ingress, nil
+	}
+	ingressName := types.NamespacedName{Namespace: staged.ingress.Namespace, Name: staged.ingress.Name}
+	current := staged.ingress
+	for attempt := 1; ; attempt++ {
+		glog.V(4).Infof("Updating federated ingress %q: %s", ingressName, staged.descriptions())
+		updated, err := ic.federatedApiClient.Extensions().Ingresses(current.Namespace).Update(current)
+		if err == nil {
+			return updated, nil
+		}
+		if !errors.IsConflict(err) || attempt >= maxFederatedUpdateAttempts {
+			return nil, err
+		}
+		glog.V(4).Infof("Conflict updating federated ingress %q (attempt %d) - refetching and replaying the staged changes", ingressName, attempt)
+		latest, getErr := ic.federatedApiClient.Extensions().Ingresses(staged.ingress.Namespace).Get(staged.ingress.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return nil, getErr
+		}
+		for _, mutation := range staged.mutations {
+			mutation.apply(latest)
+		}
+		current = latest
+	}
+}
+
+// updateAnnotationOnIngress updates the annotation with the given key on the given federated ingress.
+// Queues the ingress for resync when done.
+func (ic *IngressController) updateAnnotationOnIngress(ingress *extensionsv1beta1.Ingress, key, value string) {
+	if !ic.federatedWritesAllowed {
+		return
+	}
+	if ingress.ObjectMeta.Annotations == nil {
+		ingress.ObjectMeta.Annotations = make(map[string]string)
+	}
+	ingress.ObjectMeta.Annotations[key] = value
+	ingressName := types.NamespacedName{Name: ingress.Name, Namespace: ingress.Namespace}
+	glog.V(4).Infof("Attempting to update annotation %s:%s on base federated ingress: %v", key, value, ingressName)
+	if updatedFedIngress, err := ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Update(ingress); err != nil {
+		glog.Errorf("Failed to update annotation %s:%s on federated ingress %q, will try again later: %v", key, value, ingressName, err)
+		ic.deliverIngressAfterFederationError(ingressName, "annotation-update", err)
+		return
+	} else {
+		glog.V(4).Infof("Successfully updated annotation %s:%s on federated ingress %q, after update: %q", key, value, ingress, updatedFedIngress)
+		ic.deliverIngress(ingressName, ic.smallDelay, false)
+		return
+	}
+}
+
+// deliverIngressAfterFederationError counts a federation api error and
+// schedules a retry under the federation backoff. The controller keeps all
+// in-memory state and simply comes back later; the backoff resets on the
+// next reconciliation that reaches the federation api server successfully.
+func (ic *IngressController) deliverIngressAfterFederationError(ingress types.NamespacedName, operation string, err error) {
+	recordFederationAPIError(operation)
+	key := ingress.String()
+	ic.federationBackoff.Next(key, time.Now())
+	delay := ic.federationBackoff.Get(key)
+	glog.V(2).Infof("Federation api error during %s for ingress %q, retrying in %v: %v", operation, ingress, delay, err)
+	ic.deliverIngress(ingress, delay, false)
+}
+
+// reconcileLBPendingMarker stages adding the lb-pending annotation to the
+// federated ingress while neither it nor any cluster copy reports a
+// loadbalancer address, and stages its removal once one does. The change is
+// written together with the rest of the pass's staged mutations.
+func (ic *IngressController) reconcileLBPendingMarker(staged *stagedIngressUpdate, baseIngress *extensionsv1beta1.Ingress, lbStatusSeen bool) {
+	_, marked := baseIngress.ObjectMeta.Annotations[lbPendingAnnotation]
+	if !lbStatusSeen && !marked {
+		staged.setAnnotation(lbPendingAnnotation, "true")
+	}
+	if lbStatusSeen && marked {
+		glog.V(4).Infof("Removing annotation %s from federated ingress %s/%s: a cluster reported a loadbalancer address", lbPendingAnnotation, baseIngress.Namespace, baseIngress.Name)
+		staged.removeAnnotation(lbPendingAnnotation)
+	}
+}
+
+// propagationDeadlineState tracks an escalation timer for one federated
+// ingress; used by both the propagation deadline and the convergence budget.
+type propagationDeadlineState struct {
+	// The generation the timer was started for; a new generation restarts
+	// the timer.
+	generation int64
+	// When this generation was first seen incompletely propagated.
+	firstSeen time.Time
+	// Whether the deadline-exceeded event has already been emitted for this
+	// generation, so it fires only once.
+	escalated bool
+}
+
+// propagationDeadlineFor returns the propagation deadline requested on the
+// given ingress via the propagationDeadlineAnnotation, or false when none is
+// set or the value does not parse as a positive duration.
+func (ic *IngressController) propagationDeadlineFor(baseIngress *extensionsv1beta1.Ingress) (time.Duration, bool) {
+	value, found := baseIngress.ObjectMeta.Annotations[propagationDeadlineAnnotation]
+	if !found {
+		return 0, false
+	}
+	deadline, err := time.ParseDuration(value)
+	if err != nil || deadline <= 0 {
+		glog.Errorf("Ignoring invalid %s annotation %q on ingress %s/%s: %v", propagationDeadlineAnnotation, value, baseIngress.Namespace, baseIngress.Name, err)
+		return 0, false
+	}
+	return deadline, true
+}
+
+// checkPropagationDeadline enforces the optional per-ingress propagation
+// deadline. While the ingress is incompletely propagated it tracks when the
+// current generation was first seen in that state; once the deadline passes
+// it emits a single PropagationDeadlineExceeded warning event carrying the
+// per-cluster failure reasons and marks the ingress degraded via the
+// degradedAnnotation. The timer restarts when the spec changes and the
+// degraded marker is removed once the ingress converges.
+func (ic *IngressController) checkPropagationDeadline(baseIngress *extensionsv1beta1.Ingress, converged bool, failureReasons []string) {
+	if !ic.federatedWritesAllowed {
+		// The degraded marker is a federated-object write; the writer shard
+		// tracks the deadline for the clusters it manages.
+		return
+	}
+	ingress := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}
+	key := ingress.String()
+	deadline, found := ic.propagationDeadlineFor(baseIngress)
+	if !found || converged {
+		ic.propagationDeadlinesLock.Lock()
+		delete(ic.propagationDeadlines, key)
+		ic.propagationDeadlinesLock.Unlock()
+		if _, marked := baseIngress.ObjectMeta.Annotations[degradedAnnotation]; marked && converged {
+			delete(baseIngress.ObjectMeta.Annotations, degradedAnnotation)
+			if _, err := ic.federatedApiClient.Extensions().Ingresses(baseIngress.Namespace).Update(baseIngress); err != nil {
+				glog.Errorf("Failed to remove annotation %s from federated ingress %q, will try again later: %v", degradedAnnotation, ingress, err)
+				ic.deliverIngressAfterFederationError(ingress, "annotation-update", err)
+				return
+			}
+			glog.V(4).Infof("Removed annotation %s from federated ingress %q: the ingress has converged", degradedAnnotation, ingress)
+			ic.deliverIngress(ingress, ic.smallDelay, false)
+		}
+		return
+	}
+	now := ic.clock.Now()
+	ic.propagationDeadlinesLock.Lock()
+	state, tracked := ic.propagationDeadlines[key]
+	if !tracked || state.generation != baseIngress.Generation {
+		// First time this generation is seen incompletely propagated; the
+		// timer (re)starts here.
+		state = propagationDeadlineState{generation: baseIngress.Generation, firstSeen: now}
+	}
+	elapsed := now.Sub(state.firstSeen)
+	escalate := elapsed >= deadline && !state.escalated
+	if escalate {
+		state.escalated = true
+	}
+	ic.propagationDeadlines[key] = state
+	ic.propagationDeadlinesLock.Unlock()
+	if elapsed < deadline {
+		// Make sure the deadline is checked once it passes, even if no
+		// cluster or federation event redelivers the ingress earlier.
+		ic.deliverIngress(ingress, deadline-elapsed, false)
+		return
+	}
+	if !escalate {
+		return
+	}
+	details := "no per-cluster failures recorded"
+	if len(failureReasons) > 0 {
+		details = strings.Join(failureReasons, "; ")
+	}
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "PropagationDeadlineExceeded",
+		"Ingress was not propagated to all clusters within %v: %s", deadline, details)
+	if baseIngress.ObjectMeta.Annotations[degradedAnnotation] != "PropagationDeadlineExceeded" {
+		ic.updateAnnotationOnIngress(baseIngress, degradedAnnotation, "PropagationDeadlineExceeded")
+	}
+}
+
+// checkConvergenceBudget enforces the controller-wide convergence budget:
+// when an ingress stays out of sync with a Ready cluster beyond the budget,
+// the controller emits a single ConvergenceBudgetExceeded warning event per
+// generation and counts the stall in the convergence metric. All writes this
+// controller issues target Ready clusters, so a stall seen here indicates a
+// persistent per-object problem (e.g. rejected writes) rather than a cluster
+// outage. The timer restarts when the spec changes and is forgotten once the
+// ingress converges.
+func (ic *IngressController) checkConvergenceBudget(baseIngress *extensionsv1beta1.Ingress, converged bool, failureReasons []string) {
+	if ic.convergenceBudget == 0 {
+		return
+	}
+	ingress := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}
+	key := ingress.String()
+	if converged {
+		ic.convergenceLock.Lock()
+		delete(ic.convergenceStalls, key)
+		ic.convergenceLock.Unlock()
+		return
+	}
+	now := ic.clock.Now()
+	ic.convergenceLock.Lock()
+	state, tracked := ic.convergenceStalls[key]
+	if !tracked || state.generation != baseIngress.Generation {
+		state = propagationDeadlineState{generation: baseIngress.Generation, firstSeen: now}
+	}
+	elapsed := now.Sub(state.firstSeen)
+	escalate := elapsed >= ic.convergenceBudget && !state.escalated
+	if escalate {
+		state.escalated = true
+	}
+	ic.convergenceStalls[key] = state
+	ic.convergenceLock.Unlock()
+	if elapsed < ic.convergenceBudget {
+		ic.deliverIngress(ingress, ic.convergenceBudget-elapsed, false)
+		return
+	}
+	if !escalate {
+		return
+	}
+	details := "no per-cluster failures recorded"
+	if len(failureReasons) > 0 {
+		details = strings.Join(failureReasons, "; ")
+	}
+	recordConvergenceBudgetExceeded(key)
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "ConvergenceBudgetExceeded",
+		"Ingress has been out of sync with Ready clusters for more than %v: %s", ic.convergenceBudget, details)
+}
+
+func (ic *IngressController) reconcileIngress(ingress types.NamespacedName) {
+	glog.V(4).Infof("Reconciling ingress %q for all clusters", ingress)
+	record := ReconcileRecord{Key: ingress.String(), Timestamp: time.Now(), Result: reconcileResultDeferred}
+	defer func() {
+		ic.reconcileReplayLog.add(record)
+		recordCallsPerReconcile(ic.reconcileReplayLog.costEstimate().CallsPerReconcile)
+	}()
+	recordAction := func(action, clusterName string) {
+		recordClusterAction(action, clusterName)
+		record.Actions = append(record.Actions, clusterName+":"+action)
+	}
+	if synced, reason := ic.syncedState(); !synced {
+		ic.recordUnsyncedSkip(reason)
+		ic.deliverIngress(ingress, ic.clusterAvailableDelay, false)
+		return
+	}
+
+	key := ingress.String()
+	baseIngressObjFromStore, exist, err := ic.ingressInformerStore.GetByKey(key)
+	if err != nil {
+		glog.Errorf("Failed to query main ingress store for %v: %v", ingress, err)
+		record.Result, record.Error = reconcileResultError, err.Error()
+		ic.deliverIngress(ingress, 0, true)
+		return
+	}
+	if !exist {
+		// Not federated ingress, ignoring. Any auxiliary state left behind by
+		// a deleted federated ingress of this name is garbage collected here,
+		// so a later recreation under the same key starts fresh.
+		glog.V(4).Infof("Ingress %q is not federated.  Ignoring.", ingress)
+		record.Result = reconcileResultNotFederated
+		if _, seen := ic.observedIngressUIDs[key]; seen {
+			delete(ic.observedIngressUIDs, key)
+			ic.dropIngressState(key)
+		}
+		return
+	}
+	baseIngressObj, err := api.Scheme.DeepCopy(baseIngressObjFromStore)
+	baseIngress, ok := baseIngressObj.(*extensionsv1beta1.Ingress)
+	if err != nil || !ok {
+		glog.Errorf("Internal Error %v : Object retrieved from ingressInformerStore with key %q is not of correct type *extensionsv1beta1.Ingress: %v", err, key, baseIngressObj)
+	} else {
+		glog.V(4).Infof("Base (federated) ingress: %v", baseIngress)
+	}
+
+	if !ic.ownsIngress(baseIngress) {
+		// Owned by another system - leave it completely alone, including its
+		// deletion: without federation finalizers there is nothing to unwind.
+		glog.V(4).Infof("Ingress %q does not carry the required ownership annotation %q.  Ignoring.", ingress, ic.ownershipAnnotationKey)
+		record.Result = reconcileResultUnowned
+		return
+	}
+
+	ic.resetIngressStateOnUIDChange(key, baseIngress.ObjectMeta.UID)
+
+	if baseIngress.DeletionTimestamp != nil {
+		record.Result = reconcileResultDeleted
+		ic.invalidateSyncedVersions(key)
+		if remaining := ic.deleteDeferralRemaining(); remaining > 0 {
+			// The caches are synced (checked above), but we are still inside
+			// the warm-up period - hold off destructive actions.
+			glog.V(2).Infof("Deferring delete of ingress %q for %v until the warm-up period expires", ingress, remaining)
+			record.Result = reconcileResultDeferred
+			ic.deliverIngress(ingress, remaining, false)
+			return
+		}
+		if ic.cascadingDeleteBlocked(baseIngress) {
+			glog.Warningf("Refusing cascading delete of protected ingress %q: %s was not set before deletion; orphaning cluster copies", ingress, confirmCascadingDeleteAnnotation)
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "CascadingDeleteBlocked",
+				"Refusing cascading delete: %s is set but %s was not present before deletion. Cluster copies are orphaned and keep serving traffic.",
+				deleteProtectionAnnotation, confirmCascadingDeleteAnnotation)
+			if err := ic.orphan(baseIngress); err != nil {
+				glog.Errorf("Failed to orphan %s: %v", ingress, err)
+				record.Result, record.Error = reconcileResultError, err.Error()
+				ic.deliverIngress(ingress, 0, true)
+			}
+			return
+		}
+		if err := ic.delete(baseIngress); err != nil {
+			glog.Errorf("Failed to delete %s: %v", ingress, err)
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "DeleteFailed",
+				"Ingress delete failed: %v", err)
+			record.Result, record.Error = reconcileResultError, err.Error()
+			ic.deliverIngress(ingress, 0, true)
+		}
+		return
+	}
+
+	// All federation-side metadata mutations of this pass are staged here and
+	// written with a single consolidated update just before propagation, so a
+	// reconciliation no longer costs one federation write per finalizer and
+	// annotation.
+	staged := newStagedIngressUpdate(baseIngress)
+
+	glog.V(3).Infof("Ensuring delete object from underlying clusters finalizer for ingress: %s",
+		baseIngress.Name)
+	// Stage the required finalizers before creating a ingress in underlying clusters.
+	if ic.deferDeleteFinalizer {
+		// Only the orphan finalizer goes on up front; the delete finalizer
+		// follows once a cluster actually holds a copy, so an ingress that
+		// never propagates stays deletable.
+		staged.addFinalizer(v1.FinalizerOrphan)
+		if copies, copiesErr := ic.ingressFederatedInformer.GetTargetStore().GetFromAllClusters(key); copiesErr == nil && len(copies) > 0 {
+			staged.addFinalizer(deletionhelper.FinalizerDeleteFromUnderlyingClusters)
+		}
+	} else {
+		staged.addFinalizer(deletionhelper.FinalizerDeleteFromUnderlyingClusters)
+		staged.addFinalizer(v1.FinalizerOrphan)
+	}
+
+	glog.V(3).Infof("Syncing ingress %s in underlying clusters", baseIngress.Name)
+
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
+	if err != nil {
+		glog.Errorf("Failed to get cluster list: %v", err)
+		ic.deliverIngress(ingress, ic.clusterAvailableDelay, false)
+		return
+	} else {
+		glog.V(4).Infof("Found %d ready clusters across which to reconcile ingress %q", len(clusters), ingress)
+	}
+	// Per-cluster lines below go through the scoped logger so that each one
+	// carries the federated key and the cluster it is about.
+	logger := ic.logger.WithKey(key)
+	clusters, selfClusters := ic.splitSelfReferenceClusters(clusters)
+	for _, cluster := range selfClusters {
+		logger.WithCluster(cluster.Name).Warningf("Skipping cluster: its api endpoint is the federation's own")
+		ic.setClusterSelfReferenceCondition(cluster.Name, true)
+	}
+	for _, cluster := range clusters {
+		ic.setClusterSelfReferenceCondition(cluster.Name, false)
+	}
+	clusters, lbIncapableClusters := splitLBCapableClusters(clusters)
+	for _, cluster := range lbIncapableClusters {
+		logger.WithCluster(cluster.Name).V(4).Infof("Skipping cluster: labeled as unable to provision load balancers (%s=%q)", lbCapableLabel, cluster.ObjectMeta.Labels[lbCapableLabel])
+		ic.setClusterStatus(cluster.Name, "excluded from ingress placement: no load balancer capability")
+	}
+
+	canaryClusterName, canaryWeight, canaryActive := "", 0, false
+	if _, requested := baseIngress.ObjectMeta.Annotations[canaryAnnotation]; requested && !ic.featureGates.FederationCanary() {
+		ic.warnGatedAnnotation(baseIngress, canaryAnnotation, "FederationCanary")
+	} else {
+		var err error
+		canaryClusterName, canaryWeight, canaryActive, err = canaryTarget(baseIngress, clusters)
+		if err != nil {
+			// Propagate the stable config everywhere rather than blocking the
+			// reconciliation on a malformed canary request.
+			glog.Errorf("Ignoring invalid canary annotation on ingress %q: %v", ingress, err)
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "InvalidCanary",
+				"Ignoring invalid %s annotation: %v", canaryAnnotation, err)
+		}
+	}
+
+	propagationPolicy := ic.propagationPolicyFor(baseIngress)
+
+	// Elect the first cluster before any cluster copy exists. Doing this as a
+	// dedicated pre-pass, deterministically, closes the window in which GLBC
+	// instances in several clusters could race on IP allocation for a fresh
+	// ingress (see https://github.com/kubernetes/kubernetes/issues/36540):
+	// creation in all other clusters is held until the annotation exists.
+	// A pre-declared static IP name applies to all clusters verbatim: stamp
+	// it into the writable static IP annotation so that every cluster's GLBC
+	// attaches to the same named global IP, with no election or IP transfer.
+	// With UID reconciliation disabled there is no provider-side IP sharing
+	// to coordinate, so neither pre-pass applies.
+	if !ic.uidReconciliationDisabled {
+		if ipName, found := baseIngress.ObjectMeta.Annotations[preallocatedStaticIPAnnotation]; found && ipName != "" {
+			if baseIngress.ObjectMeta.Annotations[staticIPNameKeyWritable] != ipName {
+				glog.V(4).Infof("Stamping pre-declared static IP name %q on ingress %q", ipName, ingress)
+				staged.setAnnotation(staticIPNameKeyWritable, ipName)
+			}
+		}
+
+		_, baseIPFound := baseIngress.ObjectMeta.Annotations[staticIPNameKeyWritable]
+		if _, found := baseIngress.ObjectMeta.Annotations[firstClusterAnnotation]; !found && !baseIPFound {
+			if firstClusterName, ok := chooseFirstCluster(clusters); ok {
+				glog.V(4).Infof("No first cluster elected for ingress %q yet - electing cluster %s before any propagation", ingress, firstClusterName)
+				staged.setAnnotation(firstClusterAnnotation, firstClusterName)
+			}
+		}
+	}
+
+	// Active/passive setups can pin the federated status to a single
+	// cluster's loadbalancer. Reconcile it before the per-cluster pass so
+	// that the status transfer below never merges in addresses from the
+	// other clusters.
+	statusSource, statusSourceActive := statusSourceCluster(baseIngress, clusters)
+	if statusSourceActive && ic.federatedWritesAllowed {
+		desiredStatus := ic.aggregatedLoadBalancerStatus(baseIngress, clusters)
+		if !reflect.DeepEqual(baseIngress.Status.LoadBalancer, desiredStatus) {
+			logger.WithCluster(statusSource.Name).V(4).Infof("Updating federated ingress status from status source cluster")
+			baseIngress.Status.LoadBalancer = desiredStatus
+			if _, err := ic.federatedApiClient.Extensions().Ingresses(baseIngress.Namespace).UpdateStatus(baseIngress); err != nil {
+				logger.WithCluster(statusSource.Name).Errorf("Failed to update federated ingress status from status source cluster, will try again later: %v", err)
+				ic.setLastError(newReconcileError(key, statusSource.Name, ReconcilePhaseStatus, err))
+				ic.deliverIngressAfterFederationError(ingress, "status-update", err)
+				return
+			}
+			recordAction(actionStatusUpdate, statusSource.Name)
+			ic.deliverIngress(ingress, ic.smallDelay, false)
+			return
+		}
+	}
+
+	if ic.maxStatusIngressEntries > 0 {
+		// Note on the federated object how many addresses the cap dropped
+		// from the merged status, so consumers know they are looking at a
+		// subset. The annotation rides the consolidated staged update.
+		fullStatus := ic.uncappedLoadBalancerStatus(baseIngress, clusters)
+		if dropped := ic.capLoadBalancerStatus(&fullStatus); dropped > 0 {
+			staged.setAnnotation(statusTruncatedAnnotation, strconv.Itoa(dropped))
+		} else {
+			staged.removeAnnotation(statusTruncatedAnnotation)
+		}
+	}
+
+	// A cluster being unjoined while holding the first-cluster anchor would
+	// break traffic until failover: migrate the anchor first and keep the
+	// leaving cluster's copy alive until the new anchor has an address.
+	if ic.reconcileClusterDrain(baseIngress, clusters, recordAction) {
+		return
+	}
+
+	primaryClusterName, primaryDesignated := baseIngress.ObjectMeta.Annotations[primaryClusterAnnotation]
+	primaryHoldsSecondaries := false
+	if primaryDesignated && primaryClusterName != "" {
+		primaryHoldsSecondaries = !ic.primaryCopyReady(baseIngress, primaryClusterName, clusters)
+	}
+
+	operations := make([]util.FederatedOperation, 0)
+	disruptiveClusters := sets.NewString()
+	conflictPolicy := ic.adoptionConflictPolicy
+	if !ic.featureGates.FederationIngressAdoption() {
+		// With the gate off, adopted copies are overwritten as they always
+		// were before the configurable policies existed.
+		conflictPolicy = AdoptionConflictOverwrite
+	}
+
+	for _, cluster := range clusters {
+		clusterLogger := logger.WithCluster(cluster.Name)
+		baseIPName, baseIPAnnotationExists := baseIngress.ObjectMeta.Annotations[staticIPNameKeyWritable]
+		firstClusterName, firstClusterExists := baseIngress.ObjectMeta.Annotations[firstClusterAnnotation]
+		clusterIngressObj, clusterIngressFound, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, clusterIngressKey(cluster, ingress))
+		if err != nil {
+			clusterLogger.Errorf("Failed to get cached ingress, will retry: %v", err)
+			ic.invalidateSyncedVersions(key)
+			record.Result, record.Error = reconcileResultError, err.Error()
+			ic.deliverIngress(ingress, 0, true)
+			return
+		}
+		var clusterIngress *extensionsv1beta1.Ingress
+		if clusterIngressFound {
+			clusterIngress = clusterIngressObj.(*extensionsv1beta1.Ingress)
+		}
+		if !clusterIngressFound && !ic.ingressFederatedInformer.GetTargetStore().ClusterSynced(cluster.Name) {
+			// The cluster's informer has not completed its initial list yet
+			// (the cluster may have just been added), so the absence of the
+			// copy can mean a partial list rather than a missing object.
+			// Creating now could duplicate an ingress the list simply has not
+			// delivered yet; come back once the informer reports a full sync.
+			clusterLogger.V(2).Infof("Ingress informer has not completed its initial list - deferring create/delete decisions")
+			ic.setClusterStatus(cluster.Name, "awaiting initial ingress list")
+			ic.deliverIngress(ingress, ic.clusterAvailableDelay, false)
+			continue
+		}
+		if clusterIngressFound && clusterIngress.ObjectMeta.DeletionTimestamp != nil {
+			// The copy is terminating but held back by an in-cluster
+			// finalizer. Creating now would fail with AlreadyExists and an
+			// update would race the deletion, so treat the cluster as
+			// pending deletion for this key and come back with backoff until
+			// the object is fully gone.
+			clusterLogger.V(2).Infof("Cluster copy is still terminating (deletionTimestamp %v) - deferring propagation until the copy is gone", clusterIngress.ObjectMeta.DeletionTimestamp)
+			ic.setClusterStatus(cluster.Name, "pending deletion")
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "ClusterCopyTerminating",
+				"Ingress in cluster %s is still terminating; waiting for the deletion to complete before propagating", cluster.Name)
+			ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
+			continue
+		}
+		if clusterIngressFound && ic.isClusterSynced(key, cluster.Name, baseIngress.ObjectMeta.ResourceVersion, clusterIngress.ObjectMeta.ResourceVersion) {
+			// Neither side changed since the last pass that found them
+			// equivalent - skip the spec construction and deep comparison.
+			clusterLogger.V(4).Infof("Cluster copy is unchanged since the last successful sync - skipping comparison")
+			recordAction(actionNoop, cluster.Name)
+			continue
+		}
+		if primaryHoldsSecondaries && cluster.Name != primaryClusterName {
+			// A primary cluster is designated and its copy is not established
+			// yet - hold this cluster's copy back so the primary's DNS and
+			// certificate automation finishes first.
+			clusterLogger.V(4).Infof("Holding back the cluster copy until the copy in primary cluster %s is ready", primaryClusterName)
+			ic.setClusterStatus(cluster.Name, fmt.Sprintf("waiting for primary cluster %s", primaryClusterName))
+			ic.deliverIngress(ingress, ic.ingressReviewDelay, false)
+			continue
+		}
+		desiredIngress, err := computeDesiredIngress(baseIngress, clusterIngress, conflictPolicy, propagationPolicy)
+		if err != nil {
+			if conflict, ok := err.(*adoptionConflictError); ok {
+				clusterLogger.V(2).Infof("Not adopting the cluster copy: %v", conflict)
+				ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "AdoptionConflict",
+					"Not adopting ingress in cluster %s: %v", cluster.Name, conflict)
+				continue
+			}
+			clusterLogger.Errorf("Failed to compute desired ingress, will retry: %v", err)
+			ic.deliverIngress(ingress, 0, true)
+			return
+		}
+		// Honor the cluster's namespace mapping: the copy lives in the
+		// mapped namespace while the federated object stays canonical.
+		desiredIngress.ObjectMeta.Namespace = clusterNamespace(cluster, baseIngress.Namespace)
+		applyCanaryAnnotation(desiredIngress, cluster.Name, canaryClusterName, canaryWeight, canaryActive)
+		ic.applyIngressClassOverride(desiredIngress, cluster)
+		applyHostTemplate(desiredIngress, cluster.Name)
+		ic.applyManagedSubfields(desiredIngress, clusterIngress)
+		ic.applyPreservedAnnotations(desiredIngress, clusterIngress)
+		ic.stripProviderAnnotationsOnClassChange(desiredIngress, clusterIngress, baseIngress, cluster.Name)
+		clusterLogger.V(4).Infof("Desired Ingress: %v", desiredIngress)
+
+		if !clusterIngressFound {
+			clusterLogger.V(4).Infof("No existing cluster copy - checking if appropriate to queue a create operation")
+			if ic.ownerReferencesEnabled {
+				if anchor, err := ic.anchorManager.EnsureAnchor(cluster.Name, desiredIngress.Namespace); err == nil {
+					util.AddAnchorOwnerReference(&desiredIngress.ObjectMeta, anchor)
+				} else {
+					clusterLogger.Errorf("Failed to ensure anchor ConfigMap: %v", err)
+				}
+			}
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "CreateInCluster",
+				"Creating ingress in cluster %s", cluster.Name)
+
+			// We always first create an ingress in the first available cluster. Once that ingress
+			// has been created and allocated a global IP (visible via an annotation),
+			// we record that annotation on the federated ingress, and create all other cluster
+			// ingresses with that same global IP.
+			// Note: If the first cluster becomes (e.g. temporarily) unavailable, the
+			// second cluster will become the first cluster, but eventually all ingresses
+			// will share the single global IP recorded in the annotation of the
+			// federated ingress.
+			haveFirstCluster := firstClusterExists && firstClusterName != "" && ic.isClusterReady(firstClusterName)
+			if !haveFirstCluster && !baseIPAnnotationExists && !ic.uidReconciliationDisabled {
+				// With a static IP name already on the federated ingress
+				// (pre-declared or transferred) there is nothing to elect
+				// for: every cluster creates against the same named IP.
+				// The recorded first cluster went away - re-elect, with the
+				// same deterministic choice as the pre-pass.
+				electedClusterName, ok := chooseFirstCluster(clusters)
+				if !ok {
+					electedClusterName = cluster.Name
+				}
+				logger.V(4).Infof("No cluster has been chosen as the first cluster. Electing cluster %s as the first cluster to create ingress in", electedClusterName)
+				staged.setAnnotation(firstClusterAnnotation, electedClusterName)
+				// The desired object for this cluster was computed before the
+				// election; revisit it on the redelivery that follows the
+				// consolidated update. Later clusters in this pass already see
+				// the annotation.
+				ic.deliverIngress(ingress, ic.smallDelay, false)
+				continue
+			}
+			// Without UID reconciliation there is no shared IP to wait for:
+			// every cluster gets its copy immediately.
+			if baseIPAnnotationExists || firstClusterName == cluster.Name || ic.uidReconciliationDisabled {
+				if baseIPAnnotationExists {
+					clusterLogger.V(4).Infof("No existing cluster copy and static IP annotation (%q) exists on base ingress - queuing a create operation", staticIPNameKeyWritable)
+				} else {
+					clusterLogger.V(4).Infof("No existing cluster copy and no static IP annotation (%q) on base ingress - queuing a create operation in first cluster", staticIPNameKeyWritable)
+				}
+				operations = append(operations, util.FederatedOperation{
+					Type:        util.OperationTypeAdd,
+					Obj:         desiredIngress,
+					ClusterName: cluster.Name,
+				})
+			} else {
+				clusterLogger.V(4).Infof("No annotation %q exists on the federated ingress and waiting for ingress in cluster %s. Not queueing create operation for ingress until annotation exists", staticIPNameKeyWritable, firstClusterName)
+			}
+		} else {
+			clusterLogger.V(4).Infof("Found existing cluster copy - checking if update is required (in either direction)")
+			if isStaleClusterCopy(baseIngress, clusterIngress) {
+				// The copy belongs to a deleted predecessor of the same name.
+				// Replace it instead of updating it (and do not transfer its
+				// IP or loadbalancer status to the new federated ingress).
+				clusterLogger.V(2).Infof("Cluster copy is owned by a previous federated ingress (marker %q, current UID %q) - deleting the stale copy",
+					clusterIngress.ObjectMeta.Labels[util.ManagedByLabel], baseIngress.ObjectMeta.UID)
+				ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "ReplaceInCluster",
+					"Replacing stale ingress in cluster %s left behind by a previous federated ingress", cluster.Name)
+				operations = append(operations, util.FederatedOperation{
+					Type:        util.OperationTypeDelete,
+					Obj:         desiredIngress,
+					ClusterName: cluster.Name,
+				})
+				// The replacement is created on a later reconciliation, once
+				// the deletion has reached the cluster's cache.
+				ic.deliverIngress(ingress, ic.smallDelay, false)
+				continue
+			}
+			clusterIPName, clusterIPNameExists := clusterIngress.ObjectMeta.Annotations[staticIPNameKeyReadonly]
+			baseLBStatusExists := len(baseIngress.Status.LoadBalancer.Ingress) > 0
+			clusterLBStatusExists := len(clusterIngress.Status.LoadBalancer.Ingress) > 0
+			logStr := fmt.Sprintf("Cluster ingress has annotation %q=%q, loadbalancer status exists? [%v], federated ingress has annotation %q=%q, loadbalancer status exists? [%v].  %%s annotation and/or loadbalancer status from cluster ingress to federated ingress.", staticIPNameKeyReadonly, clusterIPName, clusterLBStatusExists, staticIPNameKeyWritable, baseIPName, baseLBStatusExists)
+			if ic.federatedWritesAllowed && ((!baseIPAnnotationExists && clusterIPNameExists && !ic.uidReconciliationDisabled) || (!statusSourceActive && !baseLBStatusExists && clusterLBStatusExists)) { // copy the IP name from the readonly annotation on the cluster ingress, to the writable annotation on the federated ingress
+				clusterLogger.V(4).Infof(logStr, "Transferring")
+				if !baseIPAnnotationExists && clusterIPNameExists && !ic.uidReconciliationDisabled {
+					staged.setAnnotation(staticIPNameKeyWritable, clusterIPName)
+					// The desired object for this cluster was computed before
+					// the transfer; revisit the cluster on the redelivery that
+					// follows the consolidated update. Later clusters in this
+					// pass already see the annotation.
+					ic.deliverIngress(ingress, ic.smallDelay, false)
+					continue
+				}
+				if !statusSourceActive && !baseLBStatusExists && clusterLBStatusExists {
+					lbstatusObj, lbErr := api.Scheme.DeepCopy(&clusterIngress.Status.LoadBalancer)
+					lbstatus, ok := lbstatusObj.(*v1.LoadBalancerStatus)
+					if lbErr != nil || !ok {
+						clusterLogger.Errorf("Internal error: Failed to clone LoadBalancerStatus while attempting to update master loadbalancer ingress status, will try again later. error: %v, Object to be cloned: %v", lbErr, lbstatusObj)
+						ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
+						return
+					}
+					baseIngress.Status.LoadBalancer = *lbstatus
+					ic.capLoadBalancerStatus(&baseIngress.Status.LoadBalancer)
+					glog.V(4).Infof("Attempting to update base federated ingress status: %v", baseIngress)
+					if updatedFedIngress, err := ic.federatedApiClient.Extensions().Ingresses(baseIngress.Namespace).UpdateStatus(baseIngress); err != nil {
+						glog.Errorf("Failed to update federated ingress status of %q (loadbalancer status), will try again later: %v", ingress, err)
+						ic.deliverIngressAfterFederationError(ingress, "status-update", err)
+						return
+					} else {
+						glog.V(4).Infof("Successfully updated federated ingress status of %q (added loadbalancer status), after update: %q", ingress, updatedFedIngress)
+						recordAction(actionStatusUpdate, cluster.Name)
+						ic.deliverIngress(ingress, ic.smallDelay, false)
+						return
+					}
+				}
+			} else {
+				clusterLogger.V(4).Infof(logStr, "Not transferring")
+			}
+			if !statusSourceActive && ic.federatedWritesAllowed && ic.includeStatusInComparison && baseLBStatusExists && !clusterLBStatusExists {
+				// The cluster copy's status was wiped after the federated
+				// status had been derived from it. Re-aggregate the federated
+				// status from the statuses the cluster copies report now.
+				aggregated := ic.aggregatedLoadBalancerStatus(baseIngress, clusters)
+				if !reflect.DeepEqual(baseIngress.Status.LoadBalancer, aggregated) {
+					clusterLogger.V(2).Infof("Loadbalancer status of the cluster copy was cleared externally - re-deriving the federated status")
+					baseIngress.Status.LoadBalancer = aggregated
+					if _, err := ic.federatedApiClient.Extensions().Ingresses(baseIngress.Namespace).UpdateStatus(baseIngress); err != nil {
+						glog.Errorf("Failed to update federated ingress status of %q (re-derived loadbalancer status), will try again later: %v", ingress, err)
+						ic.deliverIngressAfterFederationError(ingress, "status-update", err)
+						return
+					}
+					recordAction(actionStatusUpdate, cluster.Name)
+					ic.deliverIngress(ingress, ic.smallDelay, false)
+					return
+				}
+			}
+			// Update existing cluster ingress, if needed. The managed-by
+			// marker is federation-added and excluded from the comparison.
+			// Under preserve-local merging the cluster copy may legitimately
+			// differ from the federated spec, so compare against the merged
+			// desired object instead.
+			source := normalizedForComparison(baseIngress)
+			if conflictPolicy == AdoptionConflictPreserve || hasHostTemplate(baseIngress) || ic.managedSpecSubfields.Len() > 0 || ic.preservedAnnotations.Len() > 0 {
+				// Host-template rewrites, unmanaged spec subfields and
+				// preserved cluster-local annotations make the cluster copy
+				// legitimately differ from the federated spec; compare against
+				// the merged desired object instead.
+				source = normalizedForComparison(desiredIngress)
+			}
+			compareAgainst := pkgruntime.Object(source)
+			comparableClusterIngress := normalizedForComparison(clusterIngress)
+			if ic.ignoreClusterOwnedAnnotations {
+				// Strip the cluster-owned keys from both sides: adoption may
+				// have merged them into the desired object too.
+				clusterOwned := clusterOwnedAnnotationKeys(baseIngress, clusterIngress)
+				comparableClusterIngress = withoutAnnotationKeys(comparableClusterIngress, clusterOwned)
+				compareAgainst = withoutAnnotationKeys(source, clusterOwned)
+			}
+			if util.ObjectMetaAndSpecEquivalent(compareAgainst, comparableClusterIngress) {
+				clusterLogger.V(4).Infof("Cluster copy does not need an update: cluster ingress is equivalent to federated ingress")
+				ic.markClusterSynced(key, cluster.Name, baseIngress.ObjectMeta.ResourceVersion, clusterIngress.ObjectMeta.ResourceVersion)
+				recordAction(actionNoop, cluster.Name)
+			} else if clusterMutationTolerated(desiredIngress, clusterIngress, baseIngress) {
+				clusterLogger.V(4).Infof("Cluster copy differs only in fields federation did not set (likely a mutating admission webhook) - leaving the cluster's version in place")
+				ic.markClusterSynced(key, cluster.Name, baseIngress.ObjectMeta.ResourceVersion, clusterIngress.ObjectMeta.ResourceVersion)
+				recordAction(actionNoop, cluster.Name)
+			} else {
+				diff := semanticIngressDiff(withoutManagedByMarker(clusterIngress), withoutManagedByMarker(desiredIngress))
+				clusterLogger.V(4).Infof("Cluster copy needs an update: %s", diff)
+				if changedKey, disruptive := ic.disruptiveAnnotationChange(clusterIngress, desiredIngress); disruptive {
+					// The cluster's ingress controller will likely recreate
+					// its loadbalancer for this change - propagate it ahead
+					// of any staggered rollout and leave a visible trace.
+					disruptiveClusters.Insert(cluster.Name)
+					ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "DisruptiveUpdate",
+						"Annotation %s changed for cluster %s; the cluster loadbalancer may be recreated", changedKey, cluster.Name)
+				}
+				ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "UpdateInCluster",
+					"Updating ingress in cluster %s: %s", cluster.Name, diff)
+
+				operations = append(operations, util.FederatedOperation{
+					Type:        util.OperationTypeUpdate,
+					Obj:         desiredIngress,
+					ClusterName: cluster.Name,
+				})
+				// TODO: Transfer any readonly (target-proxy, url-map etc) annotations from the master cluster to the federation, if this is the master cluster.
+				// This is only for consistency, so that the federation ingress metadata matches the underlying clusters.  It's not actually required				}
+			}
+		}
+	}
+
+	// GLBC health-checks every cluster of a shared loadbalancer on the same
+	// backend service NodePort; surface disagreements before they manifest
+	// as silently unhealthy backends.
+	ic.checkBackendNodePortConsistency(baseIngress, clusters)
+
+	if ic.markLoadBalancerPending {
+		lbStatusSeen := len(baseIngress.Status.LoadBalancer.Ingress) > 0 ||
+			len(ic.aggregatedLoadBalancerStatus(baseIngress, clusters).Ingress) > 0
+		ic.reconcileLBPendingMarker(staged, baseIngress, lbStatusSeen)
+	}
+
+	operations = ic.filterValidatedOperations(baseIngress, operations)
+	if ic.skipTerminatingNamespaces {
+		operations = ic.filterTerminatingNamespaceOperations(baseIngress, operations)
+	}
+	if ic.strictReadinessGate {
+		operations = ic.filterNotReadyClusterOperations(operations)
+	}
+	operations = ic.filterQuotaBlockedOperations(baseIngress, operations)
+	operations = ic.filterTLSGatedOperations(baseIngress, clusters, operations)
+	if len(operations) == 0 {
+		// Everything is in order
+		glog.V(4).Infof("Ingress %q is up-to-date in all clusters - no propagation to clusters required.", ingress)
+		record.Result = reconcileResultConverged
+		ic.setLastError(nil)
+		if ic.rolloutStrategy.Type == RolloutStrategyStaggered && ic.rolloutStrategy.RollbackOnFailure {
+			ic.recordLastKnownGoodSpec(staged, baseIngress)
+		}
+		if baseIngress, err = ic.flushStagedIngressUpdate(staged); err != nil {
+			glog.Errorf("Failed to update federated ingress %q, will try again later: %v", ingress, err)
+			record.Result, record.Error = reconcileResultError, err.Error()
+			ic.setLastError(newReconcileError(key, "", ReconcilePhaseUpdate, err))
+			ic.deliverIngressAfterFederationError(ingress, "federated-update", err)
+			return
+		}
+		// The federation api server answered - clear any federation backoff.
+		ic.federationBackoff.Reset(key)
+		ic.checkPropagationDeadline(baseIngress, true, nil)
+		ic.checkConvergenceBudget(baseIngress, true, nil)
+		return
+	}
+	// Persist the staged federation-side changes before writing to any
+	// cluster, so the coordination annotations (first cluster, static IP) are
+	// durable by the time a cluster copy can act on them.
+	if baseIngress, err = ic.flushStagedIngressUpdate(staged); err != nil {
+		glog.Errorf("Failed to update federated ingress %q, will try again later: %v", ingress, err)
+		record.Result, record.Error = reconcileResultError, err.Error()
+		ic.setLastError(newReconcileError(key, "", ReconcilePhaseUpdate, err))
+		ic.deliverIngressAfterFederationError(ingress, "federated-update", err)
+		return
+	}
+	ic.federationBackoff.Reset(key)
+	deferredUpdates := 0
+	if ic.rolloutStrategy.Type == RolloutStrategyStaggered {
+		if ic.featureGates.FederationProgressiveRollout() {
+			operations = prioritizeOperations(operations, disruptiveClusters)
+			operations, deferredUpdates = staggerUpdateOperations(operations, ic.rolloutStrategy.MaxUnavailable)
+		} else {
+			glog.V(2).Infof("Ignoring staggered rollout strategy for ingress %q: the FederationProgressiveRollout feature gate is disabled", ingress)
+		}
+	}
+	glog.V(4).Infof("Calling federatedUpdater.Update() - operations: %v", operations)
+	var throttledMutex sync.Mutex
+	throttledDelay := time.Duration(0)
+	failedUpdateClusters := sets.NewString()
+	propagationFailures := []string{}
+	var firstFailure *ReconcileError
+	err = ic.federatedIngressUpdater.UpdateWithPolicies(operations, ic.updateTimeout, ic.retryPolicies, func(op util.FederatedOperation, operror error) {
+		opLogger := logger.WithCluster(op.ClusterName)
+		if delay, throttled := ic.throttledRetryDelay(op.ClusterName, operror); throttled {
+			opLogger.V(2).Infof("Cluster is rate-limiting requests, retrying no earlier than %v from now", delay)
+			ic.setClusterThrottledCondition(op.ClusterName, true)
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "ClusterThrottled",
+				"Cluster %s is rate-limiting requests (429), retrying in %v", op.ClusterName, delay)
+			throttledMutex.Lock()
+			if delay > throttledDelay {
+				throttledDelay = delay
+			}
+			throttledMutex.Unlock()
+			return
+		}
+		if operror == util.ErrClusterCircuitOpen {
+			// The cluster is effectively unavailable; the write is retried
+			// on a later reconciliation, like for a not-ready cluster.
+			opLogger.V(2).Infof("Skipped %s: %v", op.Type, operror)
+			return
+		}
+		if isQuotaExceededError(operror) {
+			retryIn := ic.markQuotaBlocked(op.ClusterName, baseIngress.Namespace)
+			opLogger.V(2).Infof("Namespace %q is out of quota; blocking ingress propagation into it for %v",
+				baseIngress.Namespace, retryIn)
+			ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "QuotaBlocked",
+				"Propagation to cluster %s blocked: namespace quota exhausted, next probe in %v", op.ClusterName, retryIn)
+		}
+		ic.setClusterStatus(op.ClusterName, operror.Error())
+		throttledMutex.Lock()
+		propagationFailures = append(propagationFailures, fmt.Sprintf("%s: %v", op.ClusterName, operror))
+		if firstFailure == nil {
+			firstFailure = newReconcileError(key, op.ClusterName, phaseForOperation(op.Type), operror)
+		}
+		if op.Type == util.OperationTypeUpdate {
+			failedUpdateClusters.Insert(op.ClusterName)
+		}
+		throttledMutex.Unlock()
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "FailedClusterUpdate",
+			"Ingress update in cluster %s failed: %v", op.ClusterName, operror)
+	})
+	throttledMutex.Lock()
+	defer throttledMutex.Unlock()
+	if err != nil {
+		if throttledDelay > 0 {
+			// Honor the Retry-After of the throttling cluster instead of the
+			// generic backoff. Writes to other clusters proceed normally on
+			// their own deliveries.
+			record.Result = reconcileResultDeferred
+			ic.deliverIngress(ingress, throttledDelay, false)
+			return
+		}
+		glog.Errorf("Failed to execute updates for %s: %v", ingress, err)
+		ic.invalidateSyncedVersions(key)
+		record.Result, record.Error = reconcileResultError, err.Error()
+		if ic.rolloutStrategy.Type == RolloutStrategyStaggered && ic.rolloutStrategy.RollbackOnFailure && failedUpdateClusters.Len() > 0 {
+			ic.rollbackUpdatedClusters(baseIngress, clusters, failedUpdateClusters)
+		}
+		if firstFailure == nil {
+			// No per-operation failure was reported (e.g. the update round
+			// timed out as a whole) - record the aggregate error without a
+			// cluster.
+			firstFailure = newReconcileError(key, "", ReconcilePhaseUpdate, err)
+		}
+		ic.setLastError(firstFailure)
+		ic.checkPropagationDeadline(baseIngress, false, propagationFailures)
+		ic.checkConvergenceBudget(baseIngress, false, propagationFailures)
+		ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
+		return
+	}
+	record.Result = reconcileResultPropagated
+	ic.setLastError(nil)
+	for _, operation := range operations {
+		// Successful writes clear any throttling and quota-block state for
+		// the cluster.
+		ic.clusterThrottleBackoff.Reset(operation.ClusterName)
+		ic.setClusterThrottledCondition(operation.ClusterName, false)
+		ic.clearQuotaBlock(operation.ClusterName, baseIngress.Namespace)
+	}
+	if ic.deferDeleteFinalizer && ic.federatedWritesAllowed {
+		for _, operation := range operations {
+			if operation.Type != util.OperationTypeAdd {
+				continue
+			}
+			// The ingress now exists in a cluster; from here on deletion has
+			// cluster copies to clean up.
+			if _, err := ic.deletionHelper.EnsureDeleteFinalizer(baseIngress); err != nil {
+				logger.WithCluster(operation.ClusterName).Errorf("Failed to add the delete finalizer after creating the cluster copy: %v", err)
+				ic.deliverIngressAfterFederationError(ingress, "finalizer-update", err)
+			}
+			break
+		}
+	}
+	for _, operation := range operations {
+		switch operation.Type {
+		case util.OperationTypeAdd:
+			recordAction(actionCreate, operation.ClusterName)
+		case util.OperationTypeUpdate:
+			recordAction(actionUpdate, operation.ClusterName)
+		case util.OperationTypeDelete:
+			recordAction(actionDelete, operation.ClusterName)
+		}
+		// Keep the per-cluster last-applied record current: writes replace
+		// it, deletes drop it.
+		if writtenIngress, ok := operation.Obj.(*extensionsv1beta1.Ingress); ok {
+			if operation.Type == util.OperationTypeDelete {
+				ic.clearLastAppliedSpec(key, operation.ClusterName)
+			} else {
+				ic.recordLastAppliedSpec(key, operation.ClusterName, &writtenIngress.Spec)
+			}
+		}
+		ic.setClusterStatus(operation.ClusterName, fmt.Sprintf("ok: %s", operation.Type))
+	}
+	if deferredUpdates > 0 {
+		// Come back for the remaining clusters after the staggered rollout interval.
+		glog.V(4).Infof("Deferred update of ingress %q in %d cluster(s), will continue rollout in %v", ingress, deferredUpdates, ic.rolloutStrategy.Interval)
+		ic.deliverIngress(ingress, ic.rolloutStrategy.Interval, false)
+		return
+	}
+	// Schedule another periodic reconciliation, only to account for possible bugs in watch processing.
+	ic.deliverIngress(ingress, ic.ingressReviewDelay, false)
+}
+
+// lastKnownGoodSpec parses the last-known-good spec annotation on the
+// federated ingress, returning false when none is recorded or it does not
+// parse.
+func lastKnownGoodSpec(baseIngress *extensionsv1beta1.Ingress) (*extensionsv1beta1.IngressSpec, bool) {
+	value, found := baseIngress.ObjectMeta.Annotations[lastKnownGoodSpecAnnotation]
+	if !found {
+		return nil, false
+	}
+	spec := &extensionsv1beta1.IngressSpec{}
+	if err := json.Unmarshal([]byte(value), spec); err != nil {
+		glog.Errorf("Ignoring unparseable %s annotation on ingress %s/%s: %v", lastKnownGoodSpecAnnotation, baseIngress.Namespace, baseIngress.Name, err)
+		return nil, false
+	}
+	return spec, true
+}
+
+// recordLastKnownGoodSpec stages stamping the federated ingress's current
+// spec as the last-known-good one, once the spec has reached all clusters.
+// No-op when the recorded spec is already current.
+func (ic *IngressController) recordLastKnownGoodSpec(staged *stagedIngressUpdate, baseIngress *extensionsv1beta1.Ingress) {
+	serialized, err := json.Marshal(&baseIngress.Spec)
+	if err != nil {
+		glog.Errorf("Failed to serialize spec of ingress %s/%s: %v", baseIngress.Namespace, baseIngress.Name, err)
+		return
+	}
+	staged.setAnnotation(lastKnownGoodSpecAnnotation, string(serialized))
+}
+
+// rollbackUpdatedClusters reverts the clusters that already received the
+// federated ingress's new spec back to the last-known-good spec, after a
+// staggered rollout stage failed. Clusters whose update failed are left
+// alone: they still carry the previous spec.
+func (ic *IngressController) rollbackUpdatedClusters(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster, failedClusters sets.String) {
+	ingress := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}
+	goodSpec, found := lastKnownGoodSpec(baseIngress)
+	if !found {
+		glog.Warningf("Cannot roll back ingress %q: no last-known-good spec recorded", ingress)
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "RollbackSkipped",
+			"Rollout failed but no last-known-good spec is recorded to roll back to")
+		return
+	}
+	if reflect.DeepEqual(*goodSpec, baseIngress.Spec) {
+		// The failing rollout is the last-known-good spec itself; nothing
+		// meaningful to revert to.
+		return
+	}
+	operations := make([]util.FederatedOperation, 0, len(clusters))
+	for _, cluster := range clusters {
+		if failedClusters.Has(cluster.Name) {
+			continue
+		}
+		clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, clusterIngressKey(cluster, ingress))
+		if err != nil || !found {
+			continue
+		}
+		clusterIngress := clusterIngressObj.(*extensionsv1beta1.Ingress)
+		if !reflect.DeepEqual(clusterIngress.Spec, baseIngress.Spec) {
+			// This cluster never received the new spec.
+			continue
+		}
+		revertedObj, err := api.Scheme.DeepCopy(clusterIngress)
+		if err != nil {
+			glog.Errorf("Error deep copying ingress %q in cluster %s for rollback: %v", ingress, cluster.Name, err)
+			continue
+		}
+		reverted := revertedObj.(*extensionsv1beta1.Ingress)
+		reverted.Spec = *goodSpec
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "RollbackInCluster",
+			"Rolling ingress in cluster %s back to the last-known-good spec after a failed rollout stage", cluster.Name)
+		operations = append(operations, util.FederatedOperation{
+			Type:        util.OperationTypeUpdate,
+			Obj:         reverted,
+			ClusterName: cluster.Name,
+		})
+	}
+	if len(operations) == 0 {
+		return
+	}
+	if err := ic.federatedIngressUpdater.Update(operations, ic.updateTimeout); err != nil {
+		glog.Errorf("Failed to roll back ingress %q: %v", ingress, err)
+		ic.eventRecorder.Eventf(baseIngress, api.EventTypeWarning, "RollbackFailed",
+			"Failed to roll back ingress to the last-known-good spec: %v", err)
+	}
+}
+
+// computeDesiredIngress returns the ingress that the federation wants in a
+// member cluster, given the federated ingress, the existing cluster copy
+// (nil if the ingress does not yet exist in the cluster), the policy for
+// resolving annotation conflicts with the existing copy and the label/
+// annotation propagation policy (nil passes everything through).
+func computeDesiredIngress(baseIngress, clusterIngress *extensionsv1beta1.Ingress, conflictPolicy AdoptionConflictPolicy, propagationPolicy *util.PropagationPolicy) (*extensionsv1beta1.Ingress, error) {
+	desiredIngress := &extensionsv1beta1.Ingress{}
+	objSpec, err := api.Scheme.DeepCopy(&baseIngress.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("error deep copying Spec: %v", err)
+	}
+	objSpecCopy, ok := objSpec.(*extensionsv1beta1.IngressSpec)
+	if !ok {
+		return nil, fmt.Errorf("internal error: failed to cast to *extensionsv1beta1.IngressSpec: %v", objSpec)
+	}
+	desiredIngress.Spec = *objSpecCopy
+	if clusterIngress == nil {
+		// We can't supply server-created fields when creating a new object.
+		desiredIngress.ObjectMeta = util.DeepCopyRelevantObjectMeta(baseIngress.ObjectMeta)
+		// The lb-pending and last-known-good markers describe the federated
+		// object and have no meaning on a cluster copy.
+		delete(desiredIngress.ObjectMeta.Annotations, lbPendingAnnotation)
+		delete(desiredIngress.ObjectMeta.Annotations, lastKnownGoodSpecAnnotation)
+		desiredIngress.ObjectMeta.Annotations = propagationPolicy.FilterKeys(desiredIngress.ObjectMeta.Annotations)
+		desiredIngress.ObjectMeta.Labels = propagationPolicy.FilterKeys(desiredIngress.ObjectMeta.Labels)
+		stampManagedFields(desiredIngress, baseIngress)
+		stampManagedByMarker(desiredIngress, baseIngress)
+		return desiredIngress, nil
+	}
+	if conflictPolicy == AdoptionConflictPreserve {
+		// Preserve-local merging: only overwrite the spec fields that
+		// federation owns, as recorded on the cluster copy.
+		clusterSpecObj, err := api.Scheme.DeepCopy(&clusterIngress.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("error deep copying cluster Spec: %v", err)
+		}
+		clusterSpecCopy, ok := clusterSpecObj.(*extensionsv1beta1.IngressSpec)
+		if !ok {
+			return nil, fmt.Errorf("internal error: failed to cast to *extensionsv1beta1.IngressSpec: %v", clusterSpecObj)
+		}
+		mergeSpecPreserveLocal(&desiredIngress.Spec, &baseIngress.Spec, clusterSpecCopy, parseManagedFields(clusterIngress.ObjectMeta.Annotations))
+	}
+	objMeta, err := api.Scheme.DeepCopy(&clusterIngress.ObjectMeta)
+	if err != nil {
+		return nil, fmt.Errorf("error deep copying ObjectMeta: %v", err)
+	}
+	objMetaCopy, ok := objMeta.(*v1.ObjectMeta)
+	if !ok {
+		return nil, fmt.Errorf("internal error: failed to cast to *v1.ObjectMeta: %v", objMeta)
+	}
+	desiredIngress.ObjectMeta = *objMetaCopy
+	// Merge any annotations and labels on the federated ingress onto the underlying cluster ingress,
+	// overwriting duplicates.
+	if desiredIngress.ObjectMeta.Annotations == nil {
+		desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+	}
+	for key, val := range baseIngress.ObjectMeta.Annotations {
+		if existing, found := desiredIngress.ObjectMeta.Annotations[key]; found && existing != val {
+			switch conflictPolicy {
+			case AdoptionConflictPreserve:
+				glog.V(4).Infof("Preserving existing value %q of conflicting annotation %q on cluster ingress %s/%s", existing, key, clusterIngress.Namespace, clusterIngress.Name)
+				continue
+			case AdoptionConflictFail:
+				return nil, &adoptionConflictError{key: key, federationValue: val, clusterValue: existing}
+			default:
+				glog.V(4).Infof("Overwriting conflicting annotation %q on cluster ingress %s/%s: %q -> %q", key, clusterIngress.Namespace, clusterIngress.Name, existing, val)
+			}
+		}
+		desiredIngress.ObjectMeta.Annotations[key] = val
+	}
+	if desiredIngress.ObjectMeta.Labels == nil {
+		desiredIngress.ObjectMeta.Labels = make(map[string]string)
+	}
+	for key, val := range baseIngress.ObjectMeta.Labels {
+		desiredIngress.ObjectMeta.Labels[key] = val
+	}
+	// Labels that federation propagated earlier (recorded in the copy's
+	// last-applied state) and that are gone from the federated object are
+	// removals: drop them from the copy. Labels the cluster added on its own
+	// are not recorded as propagated and stay untouched. This matters for
+	// selectors used by in-cluster network policy.
+	dropRemovedLabels(desiredIngress, baseIngress, clusterIngress)
+	// The lb-pending and last-known-good markers describe the federated
+	// object and have no meaning on a cluster copy.
+	delete(desiredIngress.ObjectMeta.Annotations, lbPendingAnnotation)
+	delete(desiredIngress.ObjectMeta.Annotations, lastKnownGoodSpecAnnotation)
+	// Apply the propagation policy after the merge so that excluded keys are
+	// also removed from cluster copies that already carry them.
+	desiredIngress.ObjectMeta.Annotations = propagationPolicy.FilterKeys(desiredIngress.ObjectMeta.Annotations)
+	desiredIngress.ObjectMeta.Labels = propagationPolicy.FilterKeys(desiredIngress.ObjectMeta.Labels)
+	stampManagedFields(desiredIngress, baseIngress)
+	stampManagedByMarker(desiredIngress, baseIngress)
+	stampLastApplied(desiredIngress, baseIngress)
+	return desiredIngress, nil
+}
+
+// stampManagedByMarker records the federated object's UID on the cluster copy
+// via the managed-by label, so that copy ownership survives delete/recreate of
+// a same-named federated ingress.
+func stampManagedByMarker(desiredIngress, baseIngress *extensionsv1beta1.Ingress) {
+	if baseIngress.ObjectMeta.UID == "" {
+		return
+	}
+	if desiredIngress.ObjectMeta.Labels == nil {
+		desiredIngress.ObjectMeta.Labels = make(map[string]string)
+	}
+	desiredIngress.ObjectMeta.Labels[util.ManagedByLabel] = string(baseIngress.ObjectMeta.UID)
+}
+
+// managedSpecFields returns the names of the top-level spec fields the given
+// federated ingress spec currently sets.
+func managedSpecFields(spec *extensionsv1beta1.IngressSpec) []string {
+	fields := []string{}
+	if spec.Backend != nil {
+		fields = append(fields, managedFieldBackend)
+	}
+	if len(spec.TLS) > 0 {
+		fields = append(fields, managedFieldTLS)
+	}
+	if len(spec.Rules) > 0 {
+		fields = append(fields, managedFieldRules)
+	}
+	return fields
+}
+
+// parseManagedFields returns the set of spec fields recorded as
+// federation-managed in the given annotations.
+func parseManagedFields(annotations map[string]string) sets.String {
+	value, found := annotations[managedFieldsAnnotation]
+	if !found || value == "" {
+		return sets.NewString()
+	}
+	return sets.NewString(strings.Split(value, ",")...)
+}
+
+// stampManagedFields records on the desired cluster copy which spec fields
+// the federation currently manages.
+func stampManagedFields(desiredIngress, baseIngress *extensionsv1beta1.Ingress) {
+	fields := managedSpecFields(&baseIngress.Spec)
+	if len(fields) == 0 {
+		delete(desiredIngress.ObjectMeta.Annotations, managedFieldsAnnotation)
+		return
+	}
+	if desiredIngress.ObjectMeta.Annotations == nil {
+		desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+	}
+	desiredIngress.ObjectMeta.Annotations[managedFieldsAnnotation] = strings.Join(fields, ",")
+}
+
+// mergeSpecPreserveLocal merges the federated spec onto the cluster copy's
+// spec, overwriting a field only if federation previously managed it or the
+// cluster copy does not set it. Fields the federation stopped setting keep
+// their local value.
+func mergeSpecPreserveLocal(desiredSpec, baseSpec, clusterSpec *extensionsv1beta1.IngressSpec, previouslyManaged sets.String) {
+	if !(baseSpec.Backend != nil && (previouslyManaged.Has(managedFieldBackend) || clusterSpec.Backend == nil)) {
+		desiredSpec.Backend = clusterSpec.Backend
+	}
+	if !(len(baseSpec.TLS) > 0 && (previouslyManaged.Has(managedFieldTLS) || len(clusterSpec.TLS) == 0)) {
+		desiredSpec.TLS = clusterSpec.TLS
+	}
+	if !(len(baseSpec.Rules) > 0 && (previouslyManaged.Has(managedFieldRules) || len(clusterSpec.Rules) == 0)) {
+		desiredSpec.Rules = clusterSpec.Rules
+	}
+}
+
+// isStaleClusterCopy returns true if the cluster copy carries a managed-by
+// marker for a different federated object UID, meaning it belongs to a deleted
+// predecessor of the same name and must be replaced rather than updated. A
+// marker matching the federated object's adopted-from-uid annotation, seeded
+// by a disaster-recovery import, counts as owned.
+func isStaleClusterCopy(baseIngress, clusterIngress *extensionsv1beta1.Ingress) bool {
+	markerUID := clusterIngress.ObjectMeta.Labels[util.ManagedByLabel]
+	if markerUID == "" || baseIngress.ObjectMeta.UID == "" || markerUID == string(baseIngress.ObjectMeta.UID) {
+		return false
+	}
+	return markerUID != baseIngress.ObjectMeta.Annotations[util.AdoptedFromUIDAnnotation]
+}
+
+// normalizeIngressCollections returns a copy of the given ingress with
+// empty-but-non-nil spec collections canonicalized to nil. Clients and
+// apiservers serialize empty and absent collections interchangeably (e.g.
+// spec.rules as [] vs omitted), and without canonicalization such copies
+// would look permanently out of sync and feed an update loop. Returns the
+// argument unchanged when nothing needs normalizing.
+func normalizeIngressCollections(ingress *extensionsv1beta1.Ingress) *extensionsv1beta1.Ingress {
+	needsNormalization := (ingress.Spec.Rules != nil && len(ingress.Spec.Rules) == 0) ||
+		(ingress.Spec.TLS != nil && len(ingress.Spec.TLS) == 0)
+	for i := range ingress.Spec.TLS {
+		if ingress.Spec.TLS[i].Hosts != nil && len(ingress.Spec.TLS[i].Hosts) == 0 {
+			needsNormalization = true
+		}
+	}
+	for i := range ingress.Spec.Rules {
+		if http := ingress.Spec.Rules[i].HTTP; http != nil && http.Paths != nil && len(http.Paths) == 0 {
+			needsNormalization = true
+		}
+	}
+	if !needsNormalization {
+		return ingress
+	}
+	normalized := *ingress
+	if len(ingress.Spec.Rules) == 0 {
+		normalized.Spec.Rules = nil
+	} else {
+		normalized.Spec.Rules = append([]extensionsv1beta1.IngressRule{}, ingress.Spec.Rules...)
+		for i := range normalized.Spec.Rules {
+			if http := normalized.Spec.Rules[i].HTTP; http != nil && http.Paths != nil && len(http.Paths) == 0 {
+				httpCopy := *http
+				httpCopy.Paths = nil
+				normalized.Spec.Rules[i].HTTP = &httpCopy
+			}
+		}
+	}
+	if len(ingress.Spec.TLS) == 0 {
+		normalized.Spec.TLS = nil
+	} else {
+		normalized.Spec.TLS = append([]extensionsv1beta1.IngressTLS{}, ingress.Spec.TLS...)
+		for i := range normalized.Spec.TLS {
+			if normalized.Spec.TLS[i].Hosts != nil && len(normalized.Spec.TLS[i].Hosts) == 0 {
+				normalized.Spec.TLS[i].Hosts = nil
+			}
+		}
+	}
+	return &normalized
+}
+
+// normalizedForComparison prepares an ingress for the equivalence comparison:
+// federation bookkeeping markers are stripped and empty spec collections are
+// canonicalized.
+func normalizedForComparison(ingress *extensionsv1beta1.Ingress) *extensionsv1beta1.Ingress {
+	return normalizeIngressCollections(withoutManagedByMarker(ingress))
+}
+
+// withoutManagedByMarker returns a copy of the given ingress with the
+// federation-added markers (the managed-by label and the managed-fields,
+// last-applied and lb-pending annotations) removed, for equivalence
+// comparison between the federated ingress and a cluster copy, neither of
+// which is required to carry them.
+func withoutManagedByMarker(clusterIngress *extensionsv1beta1.Ingress) *extensionsv1beta1.Ingress {
+	_, hasMarker := clusterIngress.ObjectMeta.Labels[util.ManagedByLabel]
+	_, hasManagedFields := clusterIngress.ObjectMeta.Annotations[managedFieldsAnnotation]
+	_, hasLastApplied := clusterIngress.ObjectMeta.Annotations[lastAppliedAnnotation]
+	_, hasLBPending := clusterIngress.ObjectMeta.Annotations[lbPendingAnnotation]
+	_, hasLastKnownGood := clusterIngress.ObjectMeta.Annotations[lastKnownGoodSpecAnnotation]
+	if !hasMarker && !hasManagedFields && !hasLastApplied && !hasLBPending && !hasLastKnownGood {
+		return clusterIngress
+	}
+	unmarked := *clusterIngress
+	if hasMarker {
+		unmarked.ObjectMeta.Labels = make(map[string]string)
+		for key, val := range clusterIngress.ObjectMeta.Labels {
+			if key != util.ManagedByLabel {
+				unmarked.ObjectMeta.Labels[key] = val
+			}
+		}
+	}
+	if hasManagedFields || hasLastApplied || hasLBPending || hasLastKnownGood {
+		unmarked.ObjectMeta.Annotations = make(map[string]string)
+		for key, val := range clusterIngress.ObjectMeta.Annotations {
+			if key != managedFieldsAnnotation && key != lastAppliedAnnotation && key != lbPendingAnnotation && key != lastKnownGoodSpecAnnotation {
+				unmarked.ObjectMeta.Annotations[key] = val
+			}
+		}
+	}
+	return &unmarked
+}
+
+// ClusterCopyEquivalent reports whether a cluster's copy of a federated
+// ingress is equivalent to the federated object, under the same comparison
+// the reconciliation loop uses in the common case: federation bookkeeping
+// markers are excluded on both sides and empty spec collections are
+// canonicalized. Exported for inspection tooling so it does not have to
+// re-implement the comparison.
+func ClusterCopyEquivalent(baseIngress, clusterIngress *extensionsv1beta1.Ingress) bool {
+	return util.ObjectMetaAndSpecEquivalent(normalizedForComparison(baseIngress), normalizedForComparison(clusterIngress))
+}
+
+// PropagationStatus is the propagation state the controller encodes in the
+// annotations of a federated ingress, in parsed form.
+type PropagationStatus struct {
+	// Degraded is true when the ingress carries the degraded marker: its
+	// propagation deadline was exceeded and it has not converged since.
+	Degraded bool
+	// LoadBalancerPending is true while the lb-pending marker is set, i.e.
+	// no member cluster has reported a loadbalancer address yet.
+	LoadBalancerPending bool
+	// PropagationDeadline is the per-ingress propagation deadline, zero when
+	// the annotation is absent or unparsable.
+	PropagationDeadline time.Duration
+	// PrimaryCluster is the designated primary cluster, if any.
+	PrimaryCluster string
+	// FirstCluster is the cluster elected to create the initial copy, if any.
+	FirstCluster string
+	// StaticIPName is the global static IP name shared by the cluster
+	// copies, if already known.
+	StaticIPName string
+}
+
+// ParsePropagationStatus extracts the parsed propagation status from the
+// annotations of the given federated ingress.
+func ParsePropagationStatus(baseIngress *extensionsv1beta1.Ingress) PropagationStatus {
+	annotations := baseIngress.ObjectMeta.Annotations
+	status := PropagationStatus{
+		PrimaryCluster: annotations[primaryClusterAnnotation],
+		FirstCluster:   annotations[firstClusterAnnotation],
+		StaticIPName:   annotations[staticIPNameKeyWritable],
+	}
+	_, status.Degraded = annotations[degradedAnnotation]
+	_, status.LoadBalancerPending = annotations[lbPendingAnnotation]
+	if value, found := annotations[propagationDeadlineAnnotation]; found {
+		if deadline, err := time.ParseDuration(value); err == nil {
+			status.PropagationDeadline = deadline
+		}
+	}
+	return status
+}
+
+// isFederationManagedAnnotation returns true for annotation keys in the
+// namespaces federation itself writes under; such keys are never treated as
+// cluster-owned.
+func isFederationManagedAnnotation(key string) bool {
+	return strings.HasPrefix(key, "federation.kubernetes.io/") ||
+		strings.HasPrefix(key, "federation.alpha.kubernetes.io/") ||
+		strings.HasPrefix(key, "ingress.federation.kubernetes.io/") ||
+		strings.HasPrefix(key, "ingress.alpha.kubernetes.io/")
+}
+
+// clusterOwnedAnnotationKeys returns the annotation keys the cluster added
+// on its own: present on the copy but absent from the federated ingress, not
+// federation-managed and not recorded in the copy's last-applied state. The
+// set is derived from the federated object rather than the merged desired
+// object, because adoption merges copy annotations into the desired state.
+// An annotation federation wrote earlier and has since dropped is still in
+// the last-applied state, so it keeps counting towards the comparison and
+// gets deleted rather than ignored.
+func clusterOwnedAnnotationKeys(baseIngress, clusterIngress *extensionsv1beta1.Ingress) sets.String {
+	previouslyApplied := sets.NewString()
+	if value, found := clusterIngress.ObjectMeta.Annotations[lastAppliedAnnotation]; found {
+		applied := appliedState{}
+		if err := json.Unmarshal([]byte(value), &applied); err == nil {
+			for key := range applied.Annotations {
+				previouslyApplied.Insert(key)
+			}
+		}
+	}
+	clusterOwned := sets.NewString()
+	for key := range clusterIngress.ObjectMeta.Annotations {
+		if _, desired := baseIngress.ObjectMeta.Annotations[key]; desired {
+			continue
+		}
+		if isFederationManagedAnnotation(key) || previouslyApplied.Has(key) {
+			continue
+		}
+		clusterOwned.Insert(key)
+	}
+	return clusterOwned
+}
+
+// withoutAnnotationKeys returns a copy of the ingress without the given
+// annotation keys, or the ingress itself when none of them are present.
+func withoutAnnotationKeys(ingress *extensionsv1beta1.Ingress, keys sets.String) *extensionsv1beta1.Ingress {
+	present := false
+	for key := range ingress.ObjectMeta.Annotations {
+		if keys.Has(key) {
+			present = true
+			break
+		}
+	}
+	if !present {
+		return ingress
+	}
+	stripped := *ingress
+	stripped.ObjectMeta.Annotations = make(map[string]string)
+	for key, val := range ingress.ObjectMeta.Annotations {
+		if !keys.Has(key) {
+			stripped.ObjectMeta.Annotations[key] = val
+		}
+	}
+	return &stripped
+}
+
+// appliedState is the serialized shape of the last-applied annotation: the
+// parts of a cluster copy that federation writes.
+type appliedState struct {
+	Labels      map[string]string             `json:"labels,omitempty"`
+	Annotations map[string]string             `json:"annotations,omitempty"`
+	Spec        extensionsv1beta1.IngressSpec `json:"spec"`
+	// The label keys that came from the federated object at the last write,
+	// as opposed to labels adopted from the cluster copy. A key recorded
+	// here but gone from the federated object is a removal that must be
+	// synced to the copy.
+	PropagatedLabels []string `json:"propagatedLabels,omitempty"`
+}
+
+// lastAppliedValue serializes what federation is about to write to a cluster
+// copy, minus the federation-added markers, which are excluded from the
+// equivalence comparison anyway. The label keys shared with the federated
+// object are recorded separately so that a label later removed from the
+// federated object can be told apart from one the cluster added on its own.
+func lastAppliedValue(desiredIngress, baseIngress *extensionsv1beta1.Ingress) (string, error) {
+	stripped := withoutManagedByMarker(desiredIngress)
+	propagatedLabels := []string{}
+	for key := range stripped.ObjectMeta.Labels {
+		if _, fromBase := baseIngress.ObjectMeta.Labels[key]; fromBase {
+			propagatedLabels = append(propagatedLabels, key)
+		}
+	}
+	sort.Strings(propagatedLabels)
+	data, err := json.Marshal(appliedState{
+		Labels:           stripped.ObjectMeta.Labels,
+		Annotations:      stripped.ObjectMeta.Annotations,
+		Spec:             stripped.Spec,
+		PropagatedLabels: propagatedLabels,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// stampLastApplied records the desired state on the cluster copy itself. Must
+// run after all other mutations of the desired object. Only updates stamp the
+// annotation: a freshly created copy has nothing to diverge from yet, and
+// plain copies stay byte-identical to the federated ingress metadata.
+func stampLastApplied(desiredIngress, baseIngress *extensionsv1beta1.Ingress) {
+	value, err := lastAppliedValue(desiredIngress, baseIngress)
+	if err != nil {
+		// Not fatal: without the annotation the controller merely falls back
+		// to the strict equivalence check.
+		glog.Errorf("Failed to serialize last-applied state of ingress %s/%s: %v", desiredIngress.Namespace, desiredIngress.Name, err)
+		return
+	}
+	if desiredIngress.ObjectMeta.Annotations == nil {
+		desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+	}
+	desiredIngress.ObjectMeta.Annotations[lastAppliedAnnotation] = value
+}
+
+// dropRemovedLabels deletes labels from the desired cluster ingress that the
+// copy's last-applied state records as propagated from the federated object
+// but that the federated object no longer carries.
+func dropRemovedLabels(desiredIngress, baseIngress, clusterIngress *extensionsv1beta1.Ingress) {
+	value, found := clusterIngress.ObjectMeta.Annotations[lastAppliedAnnotation]
+	if !found {
+		return
+	}
+	applied := appliedState{}
+	if err := json.Unmarshal([]byte(value), &applied); err != nil {
+		return
+	}
+	for _, key := range applied.PropagatedLabels {
+		if _, stillWanted := baseIngress.ObjectMeta.Labels[key]; !stillWanted {
+			delete(desiredIngress.ObjectMeta.Labels, key)
+		}
+	}
+}
+
+// clusterMutationTolerated returns true if the cluster copy differs from the
+// desired state only in fields federation did not set, as witnessed by the
+// last-applied annotation: the desired state is unchanged since the last
+// write and everything written then is still intact on the copy. Such
+// differences were added by the cluster (typically a mutating admission
+// webhook) and updating would only restart the fight.
+func clusterMutationTolerated(desiredIngress, clusterIngress, baseIngress *extensionsv1beta1.Ingress) bool {
+	previousValue, found := clusterIngress.ObjectMeta.Annotations[lastAppliedAnnotation]
+	if !found {
+		return false
+	}
+	currentValue, err := lastAppliedValue(desiredIngress, baseIngress)
+	if err != nil || previousValue != currentValue {
+		// Federation's intent changed since the last write.
+		return false
+	}
+	applied := appliedState{}
+	if err := json.Unmarshal([]byte(previousValue), &applied); err != nil {
+		return false
+	}
+	for key, value := range applied.Labels {
+		if clusterIngress.ObjectMeta.Labels[key] != value {
+			return false
+		}
+	}
+	for key, value := range applied.Annotations {
+		if clusterIngress.ObjectMeta.Annotations[key] != value {
+			return false
+		}
+	}
+	if applied.Spec.Backend != nil && !reflect.DeepEqual(applied.Spec.Backend, clusterIngress.Spec.Backend) {
+		return false
+	}
+	if len(applied.Spec.TLS) > 0 && !reflect.DeepEqual(applied.Spec.TLS, clusterIngress.Spec.TLS) {
+		return false
+	}
+	if len(applied.Spec.Rules) > 0 && !reflect.DeepEqual(applied.Spec.Rules, clusterIngress.Spec.Rules) {
+		return false
+	}
+	return true
+}
+
+// canaryTarget parses and validates the canary annotation on the federated
+// ingress, returning the named cluster, the weight and whether a canary is
+// requested. The named cluster must be one of the given ready clusters and the
+// weight must be an integer in [0, 100].
+func canaryTarget(baseIngress *extensionsv1beta1.Ingress, clusters []*federationapi.Cluster) (clusterName string, weight int, active bool, err error) {
+	value, found := baseIngress.ObjectMeta.Annotations[canaryAnnotation]
+	if !found {
+		return "", 0, false, nil
+	}
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", 0, false, fmt.Errorf("expected %q annotation in the form <cluster>=<weight>, got %q", canaryAnnotation, value)
+	}
+	weight, err = strconv.Atoi(parts[1])
+	if err != nil || weight < 0 || weight > 100 {
+		return "", 0, false, fmt.Errorf("canary weight %q must be an integer between 0 and 100", parts[1])
+	}
+	for _, cluster := range clusters {
+		if cluster.Name == parts[0] {
+			return parts[0], weight, true, nil
+		}
+	}
+	return "", 0, false, fmt.Errorf("canary cluster %q is not a ready cluster of the federation", parts[0])
+}
+
+// applyCanaryAnnotation adjusts the canary annotations on the desired cluster
+// ingress: the named canary cluster's copy gets the per-cluster weight
+// annotation, all other copies (and copies left over after the canary
+// annotation was removed from the federated ingress) have it stripped so that
+// they carry the stable config. The federation-level canary annotation itself
+// is never propagated to the clusters.
+func applyCanaryAnnotation(desiredIngress *extensionsv1beta1.Ingress, clusterName, canaryClusterName string, weight int, canaryActive bool) {
+	if canaryActive && clusterName == canaryClusterName {
+		if desiredIngress.ObjectMeta.Annotations == nil {
+			desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+		}
+		delete(desiredIngress.ObjectMeta.Annotations, canaryAnnotation)
+		desiredIngress.ObjectMeta.Annotations[canaryWeightAnnotation] = strconv.Itoa(weight)
+		return
+	}
+	if desiredIngress.ObjectMeta.Annotations == nil {
+		return
+	}
+	delete(desiredIngress.ObjectMeta.Annotations, canaryAnnotation)
+	delete(desiredIngress.ObjectMeta.Annotations, canaryWeightAnnotation)
+}
+
+// syncedVersions is the pair of resource versions recorded when a cluster
+// copy was last found equivalent to the federated ingress.
+type syncedVersions struct {
+	federatedVersion string
+	clusterVersion   string
+}
+
+// markClusterSynced records that the given versions of the federated ingress
+// and its copy in the given cluster were found equivalent. An object without
+// a resourceVersion cannot be change-tracked, so an empty version on either
+// side is never recorded: skipping a comparison based on it would hide later
+// changes to that object.
+func (ic *IngressController) markClusterSynced(key, clusterName, federatedVersion, clusterVersion string) {
+	if federatedVersion == "" || clusterVersion == "" {
+		return
+	}
+	ic.syncedVersionsLock.Lock()
+	defer ic.syncedVersionsLock.Unlock()
+	clusterVersions, found := ic.syncedVersionCache[key]
+	if !found {
+		clusterVersions = make(map[string]syncedVersions)
+		ic.syncedVersionCache[key] = clusterVersions
+	}
+	clusterVersions[clusterName] = syncedVersions{federatedVersion: federatedVersion, clusterVersion: clusterVersion}
+}
+
+// isClusterSynced returns true if the given versions match the ones recorded
+// at the last equivalence check, meaning neither side changed since. Empty
+// versions never match; see markClusterSynced.
+func (ic *IngressController) isClusterSynced(key, clusterName, federatedVersion, clusterVersion string) bool {
+	if federatedVersion == "" || clusterVersion == "" {
+		return false
+	}
+	ic.syncedVersionsLock.Lock()
+	defer ic.syncedVersionsLock.Unlock()
+	versions, found := ic.syncedVersionCache[key][clusterName]
+	return found && versions.federatedVersion == federatedVersion && versions.clusterVersion == clusterVersion
+}
+
+// invalidateSyncedVersions drops the recorded versions for all clusters of
+// the given federated key, forcing the next reconciliation down the full
+// comparison path.
+func (ic *IngressController) invalidateSyncedVersions(key string) {
+	ic.syncedVersionsLock.Lock()
+	defer ic.syncedVersionsLock.Unlock()
+	delete(ic.syncedVersionCache, key)
+}
+
+// recordLastAppliedSpec stores a copy of the spec federation just wrote to
+// the given cluster for the given federated key.
+func (ic *IngressController) recordLastAppliedSpec(key, clusterName string, spec *extensionsv1beta1.IngressSpec) {
+	copiedObj, err := api.Scheme.DeepCopy(spec)
+	copied, ok := copiedObj.(*extensionsv1beta1.IngressSpec)
+	if err != nil || !ok {
+		glog.Errorf("Internal error: Failed to clone last-applied spec of %q for cluster %q: %v", key, clusterName, err)
+		return
+	}
+	ic.lastAppliedLock.Lock()
+	defer ic.lastAppliedLock.Unlock()
+	clusterSpecs, found := ic.lastAppliedSpecs[key]
+	if !found {
+		clusterSpecs = make(map[string]*extensionsv1beta1.IngressSpec)
+		ic.lastAppliedSpecs[key] = clusterSpecs
+	}
+	clusterSpecs[clusterName] = copied
+}
+
+// clearLastAppliedSpec forgets the recorded spec for one cluster, after the
+// cluster copy has been deleted.
+func (ic *IngressController) clearLastAppliedSpec(key, clusterName string) {
+	ic.lastAppliedLock.Lock()
+	defer ic.lastAppliedLock.Unlock()
+	delete(ic.lastAppliedSpecs[key], clusterName)
+	if len(ic.lastAppliedSpecs[key]) == 0 {
+		delete(ic.lastAppliedSpecs, key)
+	}
+}
+
+// clearLastAppliedSpecs forgets the recorded specs of every cluster for the
+// given federated key.
+func (ic *IngressController) clearLastAppliedSpecs(key string) {
+	ic.lastAppliedLock.Lock()
+	defer ic.lastAppliedLock.Unlock()
+	delete(ic.lastAppliedSpecs, key)
+}
+
+// LastAppliedSpec returns a copy of the ingress spec the controller last
+// wrote to the given cluster for the named federated ingress, and whether one
+// is recorded. Comparing the returned spec to the live cluster copy's spec
+// reveals edits made outside federation. Read-only: mutating the returned
+// spec does not affect the controller. The record is in-memory only, so after
+// a controller restart nothing is returned until the next write.
+func (ic *IngressController) LastAppliedSpec(clusterName, namespace, name string) (*extensionsv1beta1.IngressSpec, bool) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}.String()
+	ic.lastAppliedLock.Lock()
+	spec, found := ic.lastAppliedSpecs[key][clusterName]
+	ic.lastAppliedLock.Unlock()
+	if !found {
+		return nil, false
+	}
+	copiedObj, err := api.Scheme.DeepCopy(spec)
+	copied, ok := copiedObj.(*extensionsv1beta1.IngressSpec)
+	if err != nil || !ok {
+		glog.Errorf("Internal error: Failed to clone last-applied spec of %q for cluster %q: %v", key, clusterName, err)
+		return nil, false
+	}
+	return copied, true
+}
+
+// resetIngressStateOnUIDChange drops the per-ingress auxiliary state when the
+// UID behind a key changes, i.e. the federated ingress was deleted and
+// recreated under the same name. Retry backoffs, deadline timers and the
+// recorded last error describe the previous incarnation; without the reset a
+// recreated ingress could inherit a long backoff or a stale deadline from its
+// predecessor.
+func (ic *IngressController) resetIngressStateOnUIDChange(key string, uid types.UID) {
+	previous, seen := ic.observedIngressUIDs[key]
+	ic.observedIngressUIDs[key] = uid
+	if !seen || previous == uid {
+		return
+	}
+	glog.V(2).Infof("Federated ingress %q was recreated (UID %s -> %s) - dropping the auxiliary state of the previous incarnation", key, previous, uid)
+	ic.dropIngressState(key)
+}
+
+// dropIngressState removes all auxiliary state kept for the given ingress
+// key: retry backoffs, the synced-version cache, the last-applied specs,
+// deadline and stall timers, any active drain, and the last error if it
+// refers to this key.
+func (ic *IngressController) dropIngressState(key string) {
+	ic.ingressBackoff.Reset(key)
+	ic.federationBackoff.Reset(key)
+	ic.trackBackoffKey(key, false)
+	ic.invalidateSyncedVersions(key)
+	ic.clearLastAppliedSpecs(key)
+	ic.propagationDeadlinesLock.Lock()
+	delete(ic.propagationDeadlines, key)
+	ic.propagationDeadlinesLock.Unlock()
+	ic.convergenceLock.Lock()
+	delete(ic.convergenceStalls, key)
+	ic.convergenceLock.Unlock()
+	ic.drainsLock.Lock()
+	delete(ic.ingressDrains, key)
+	ic.drainsLock.Unlock()
+	ic.statusLock.Lock()
+	if reconcileErr, ok := ic.lastReconcileError.(*ReconcileError); ok && reconcileErr.Key == key {
+		ic.lastReconcileError = nil
+	}
+	ic.statusLock.Unlock()
+}
+
+// applyHostTemplate rewrites every rule and TLS host of the desired cluster
+// ingress through the host-template annotation, if present. {{host}} expands
+// to the first label of the federated host and {{cluster}} to the cluster
+// name, e.g. "app.example.com" with template "{{host}}.{{cluster}}.example.com"
+// becomes "app.cluster1.example.com" in cluster1.
+func applyHostTemplate(desiredIngress *extensionsv1beta1.Ingress, clusterName string) {
+	template, found := desiredIngress.ObjectMeta.Annotations[hostTemplateAnnotation]
+	if !found || template == "" {
+		return
+	}
+	if !strings.Contains(template, "{{host}}") && !strings.Contains(template, "{{cluster}}") {
+		// A template without any placeholder would collapse all hosts into
+		// the same name; ignore it.
+		glog.Warningf("Ignoring %s annotation %q on ingress %s/%s: no {{host}} or {{cluster}} placeholder", hostTemplateAnnotation, template, desiredIngress.Namespace, desiredIngress.Name)
+		return
+	}
+	rewrite := func(host string) string {
+		if host == "" {
+			return host
+		}
+		label := host
+		if dot := strings.Index(host, "."); dot >= 0 {
+			label = host[:dot]
+		}
+		result := strings.Replace(template, "{{host}}", label, -1)
+		return strings.Replace(result, "{{cluster}}", clusterName, -1)
+	}
+	for i := range desiredIngress.Spec.Rules {
+		desiredIngress.Spec.Rules[i].Host = rewrite(desiredIngress.Spec.Rules[i].Host)
+	}
+	for i := range desiredIngress.Spec.TLS {
+		for j := range desiredIngress.Spec.TLS[i].Hosts {
+			desiredIngress.Spec.TLS[i].Hosts[j] = rewrite(desiredIngress.Spec.TLS[i].Hosts[j])
+		}
+	}
+}
+
+// hasHostTemplate returns true if the federated ingress carries a non-empty
+// host-template annotation.
+func hasHostTemplate(ingress *extensionsv1beta1.Ingress) bool {
+	template, found := ingress.ObjectMeta.Annotations[hostTemplateAnnotation]
+	return found && template != ""
+}
+
+// ClusterHostsForIngress returns the hosts the given cluster serves for the
+// federated ingress once the host template (if any) is applied, in rule
+// order. DNS reconciliation uses this to map the global hosts of the
+// federated spec onto the per-cluster names.
+func ClusterHostsForIngress(baseIngress *extensionsv1beta1.Ingress, clusterName string) ([]string, error) {
+	copyObj, err := api.Scheme.DeepCopy(baseIngress)
+	if err != nil {
+		return nil, err
+	}
+	ingress := copyObj.(*extensionsv1beta1.Ingress)
+	applyHostTemplate(ingress, clusterName)
+	hosts := []string{}
+	seen := sets.NewString()
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" && !seen.Has(rule.Host) {
+			seen.Insert(rule.Host)
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	for _, tls := range ingress.Spec.TLS {
+		for _, host := range tls.Hosts {
+			if host != "" && !seen.Has(host) {
+				seen.Insert(host)
+				hosts = append(hosts, host)
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// applyPreservedAnnotations re-applies the configured cluster-local
+// annotations from the existing cluster copy onto the desired object, so a
+// federation update does not wipe values maintained by the cluster's own
+// ingress controller. No-op for creates: there is no cluster copy to read
+// from yet.
+func (ic *IngressController) applyPreservedAnnotations(desiredIngress, clusterIngress *extensionsv1beta1.Ingress) {
+	if ic.preservedAnnotations.Len() == 0 || clusterIngress == nil {
+		return
+	}
+	for _, key := range ic.preservedAnnotations.List() {
+		value, found := clusterIngress.ObjectMeta.Annotations[key]
+		if !found {
+			continue
+		}
+		if desiredIngress.ObjectMeta.Annotations == nil {
+			desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+		}
+		desiredIngress.ObjectMeta.Annotations[key] = value
+	}
+}
+
+// stripProviderAnnotationsOnClassChange removes the known GCE provider
+// annotations from the desired object when the ingress class of the cluster
+// copy is about to change, so the controller taking over (e.g. nginx) does
+// not act on stale GLBC state. The removal rides along with the class-change
+// update; an event lists the removed keys since their values are the only
+// record of the GCE resources GLBC created.
+func (ic *IngressController) stripProviderAnnotationsOnClassChange(desiredIngress, clusterIngress, baseIngress *extensionsv1beta1.Ingress, clusterName string) {
+	if !ic.gcProviderAnnotationsOnClassChange || clusterIngress == nil {
+		return
+	}
+	clusterClass := clusterIngress.ObjectMeta.Annotations[ingressClassAnnotation]
+	desiredClass := desiredIngress.ObjectMeta.Annotations[ingressClassAnnotation]
+	if clusterClass == desiredClass {
+		return
+	}
+	removed := []string{}
+	for _, key := range gceProviderAnnotationKeys.List() {
+		if _, found := clusterIngress.ObjectMeta.Annotations[key]; found {
+			removed = append(removed, key)
+		}
+		delete(desiredIngress.ObjectMeta.Annotations, key)
+	}
+	if len(removed) == 0 {
+		return
+	}
+	glog.V(2).Infof("Stripping GCE provider annotations from ingress %s/%s in cluster %s on ingress class change %q -> %q: %s",
+		baseIngress.Namespace, baseIngress.Name, clusterName, clusterClass, desiredClass, strings.Join(removed, ", "))
+	ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "ProviderAnnotationsRemoved",
+		"Removing GCE provider annotations from ingress in cluster %s after ingress class change to %q: %s",
+		clusterName, desiredClass, strings.Join(removed, ", "))
+}
+
+// applyManagedSubfields restricts the desired cluster ingress to the spec
+// subfields the controller is configured to manage. An unmanaged subfield is
+// not propagated: an existing cluster copy keeps its local value and a new
+// copy is created without it. The managed-fields record on the copy is
+// reduced accordingly so ownership matches what federation actually wrote.
+func (ic *IngressController) applyManagedSubfields(desiredIngress, clusterIngress *extensionsv1beta1.Ingress) {
+	managed := ic.managedSpecSubfields
+	if managed.Len() == 0 {
+		return
+	}
+	var clusterSpec *extensionsv1beta1.IngressSpec
+	if clusterIngress != nil {
+		clusterSpecObj, err := api.Scheme.DeepCopy(&clusterIngress.Spec)
+		if err != nil {
+			glog.Errorf("Failed to deep copy cluster spec of ingress %s/%s while preserving unmanaged subfields: %v", clusterIngress.Namespace, clusterIngress.Name, err)
+			return
+		}
+		clusterSpec = clusterSpecObj.(*extensionsv1beta1.IngressSpec)
+	}
+	if !managed.Has(managedFieldBackend) {
+		desiredIngress.Spec.Backend = nil
+		if clusterSpec != nil {
+			desiredIngress.Spec.Backend = clusterSpec.Backend
+		}
+	}
+	if !managed.Has(managedFieldTLS) {
+		desiredIngress.Spec.TLS = nil
+		if clusterSpec != nil {
+			desiredIngress.Spec.TLS = clusterSpec.TLS
+		}
+	}
+	if !managed.Has(managedFieldRules) {
+		desiredIngress.Spec.Rules = nil
+		if clusterSpec != nil {
+			desiredIngress.Spec.Rules = clusterSpec.Rules
+		}
+	}
+	// Unmanaged subfields must not be recorded as federation-owned, or a
+	// later preserve-local merge would overwrite the cluster's values.
+	fields := []string{}
+	for _, field := range managedSpecFields(&desiredIngress.Spec) {
+		if managed.Has(field) {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		delete(desiredIngress.ObjectMeta.Annotations, managedFieldsAnnotation)
+		return
+	}
+	if desiredIngress.ObjectMeta.Annotations == nil {
+		desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+	}
+	desiredIngress.ObjectMeta.Annotations[managedFieldsAnnotation] = strings.Join(fields, ",")
+}
+
+// applyIngressClassOverride stamps the ingress class annotation on the
+// desired cluster ingress when the cluster needs a different class than the
+// federated object carries. The controller-level mapping takes precedence
+// over the ingress-class label on the Cluster object; without either, the
+// class from the federated ingress (if any) is left as is.
+func (ic *IngressController) applyIngressClassOverride(desiredIngress *extensionsv1beta1.Ingress, cluster *federationapi.Cluster) {
+	class, found := ic.ingressClassOverrides[cluster.Name]
+	if !found {
+		class, found = cluster.ObjectMeta.Labels[ingressClassOverrideLabel]
+	}
+	if !found || class == "" {
+		return
+	}
+	if desiredIngress.ObjectMeta.Annotations == nil {
+		desiredIngress.ObjectMeta.Annotations = make(map[string]string)
+	}
+	desiredIngress.ObjectMeta.Annotations[ingressClassAnnotation] = class
+}
+
+// splitSelfReferenceClusters partitions ready clusters into those safe to
+// propagate into and those whose api endpoint is one of the federation's own
+// (the host cluster joined as a member). Propagating into the latter would
+// produce duplicate objects and reconcile loops.
+func (ic *IngressController) splitSelfReferenceClusters(clusters []*federationapi.Cluster) (propagate, self []*federationapi.Cluster) {
+	if ic.hostClusterEndpoints.Len() == 0 {
+		return clusters, nil
+	}
+	propagate = make([]*federationapi.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		isSelf := false
+		for _, address := range cluster.Spec.ServerAddressByClientCIDRs {
+			if ic.hostClusterEndpoints.Has(normalizeServerAddress(address.ServerAddress)) {
+				isSelf = true
+				break
 			}
+		}
+		if isSelf {
+			self = append(self, cluster)
 		} else {
-			clusterIngress := clusterIngressObj.(*extensionsv1beta1.Ingress)
-			glog.V(4).Infof("Found existing Ingress %s in cluster %s - checking if update is required (in either direction)", ingress, cluster.Name)
-			clusterIPName, clusterIPNameExists := clusterIngress.ObjectMeta.Annotations[staticIPNameKeyReadonly]
-			baseLBStatusExists := len(baseIngress.Status.LoadBalancer.Ingress) > 0
-			clusterLBStatusExists := len(clusterIngress.Status.LoadBalancer.Ingress) > 0
-			logStr := fmt.Sprintf("Cluster ingress %q has annotation %q=%q, loadbalancer status exists? [%v], federated ingress has annotation %q=%q, loadbalancer status exists? [%v].  %%s annotation and/or loadbalancer status from cluster ingress to federated ingress.", ingress, staticIPNameKeyReadonly, clusterIPName, clusterLBStatusExists, staticIPNameKeyWritable, baseIPName, baseLBStatusExists)
-			if (!baseIPAnnotationExists && clusterIPNameExists) || (!baseLBStatusExists && clusterLBStatusExists) { // copy the IP name from the readonly annotation on the cluster ingress, to the writable annotation on the federated ingress
-				glog.V(4).Infof(logStr, "Transferring")
-				if !baseIPAnnotationExists && clusterIPNameExists {
-					ic.updateAnnotationOnIngress(baseIngress, staticIPNameKeyWritable, clusterIPName)
-					return
-				}
-				if !baseLBStatusExists && clusterLBStatusExists {
-					lbstatusObj, lbErr := api.Scheme.DeepCopy(&clusterIngress.Status.LoadBalancer)
-					lbstatus, ok := lbstatusObj.(*v1.LoadBalancerStatus)
-					if lbErr != nil || !ok {
-						glog.Errorf("Internal error: Failed to clone LoadBalancerStatus of %q in cluster %q while attempting to update master loadbalancer ingress status, will try again later. error: %v, Object to be cloned: %v", ingress, cluster.Name, lbErr, lbstatusObj)
-						ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
-						return
-					}
-					baseIngress.Status.LoadBalancer = *lbstatus
-					glog.V(4).Infof("Attempting to update base federated ingress status: %v", baseIngress)
-					if updatedFedIngress, err := ic.federatedApiClient.Extensions().Ingresses(baseIngress.Namespace).UpdateStatus(baseIngress); err != nil {
-						glog.Errorf("Failed to update federated ingress status of %q (loadbalancer status), will try again later: %v", ingress, err)
-						ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
-						return
-					} else {
-						glog.V(4).Infof("Successfully updated federated ingress status of %q (added loadbalancer status), after update: %q", ingress, updatedFedIngress)
-						ic.deliverIngress(ingress, ic.smallDelay, false)
-						return
-					}
-				}
-			} else {
-				glog.V(4).Infof(logStr, "Not transferring")
+			propagate = append(propagate, cluster)
+		}
+	}
+	return propagate, self
+}
+
+// splitLBCapableClusters partitions ready clusters into those eligible for
+// ingress placement and those labeled as unable to provision external load
+// balancers (lbCapableLabel = "false"). Unlabeled clusters are assumed to be
+// capable.
+func splitLBCapableClusters(clusters []*federationapi.Cluster) (capable, incapable []*federationapi.Cluster) {
+	capable = make([]*federationapi.Cluster, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.ObjectMeta.Labels[lbCapableLabel] == "false" {
+			incapable = append(incapable, cluster)
+		} else {
+			capable = append(capable, cluster)
+		}
+	}
+	return capable, incapable
+}
+
+// normalizeServerAddress strips the scheme and any trailing slash from an api
+// endpoint so that equivalent spellings compare equal.
+func normalizeServerAddress(address string) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	address = strings.TrimPrefix(address, "https://")
+	address = strings.TrimPrefix(address, "http://")
+	return strings.TrimSuffix(address, "/")
+}
+
+// PlannedChange describes one per-cluster action that a reconciliation of a
+// federated ingress would take right now, as reported by PlanReconcile.
+type PlannedChange struct {
+	// Cluster is the name of the member cluster the action targets.
+	Cluster string
+	// Action is one of the cluster action names also used by the metrics:
+	// create, update, delete or noop.
+	Action string
+	// Diff is a compact summary of the object sections that differ between
+	// the cluster copy and the desired state, empty for noops and creates.
+	Diff string
+}
+
+// PlanReconcile computes the per-cluster actions a reconciliation of the
+// named federated ingress would take, without performing any of them. Like
+// DesiredClusterIngress it works entirely from the informer caches, so the
+// plan reflects the controller's current view of the clusters.
+func (ic *IngressController) PlanReconcile(namespace, name string) ([]PlannedChange, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}.String()
+	baseIngressObj, exists, err := ic.ingressInformerStore.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("federated ingress %q not found", key)
+	}
+	baseIngress, ok := baseIngressObj.(*extensionsv1beta1.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("internal error: object with key %q is not an ingress", key)
+	}
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
+	if err != nil {
+		return nil, err
+	}
+	clusters, _ = ic.splitSelfReferenceClusters(clusters)
+	clusters, _ = splitLBCapableClusters(clusters)
+	conflictPolicy := ic.adoptionConflictPolicy
+	if !ic.featureGates.FederationIngressAdoption() {
+		conflictPolicy = AdoptionConflictOverwrite
+	}
+	propagationPolicy := ic.propagationPolicyFor(baseIngress)
+	plan := make([]PlannedChange, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterKey := clusterIngressKey(cluster, types.NamespacedName{Namespace: namespace, Name: name})
+		clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, clusterKey)
+		if err != nil {
+			return nil, err
+		}
+		var clusterIngress *extensionsv1beta1.Ingress
+		if found {
+			clusterIngress = clusterIngressObj.(*extensionsv1beta1.Ingress)
+		}
+		desiredIngress, err := computeDesiredIngress(baseIngress, clusterIngress, conflictPolicy, propagationPolicy)
+		if err != nil {
+			return nil, err
+		}
+		applyHostTemplate(desiredIngress, cluster.Name)
+		ic.applyManagedSubfields(desiredIngress, clusterIngress)
+		change := PlannedChange{Cluster: cluster.Name, Action: actionNoop}
+		if !found {
+			change.Action = actionCreate
+		} else if isStaleClusterCopy(baseIngress, clusterIngress) {
+			change.Action = actionDelete
+			change.Diff = "stale copy of a previous federated ingress"
+		} else {
+			source := normalizedForComparison(baseIngress)
+			if conflictPolicy == AdoptionConflictPreserve || hasHostTemplate(baseIngress) || ic.managedSpecSubfields.Len() > 0 {
+				source = normalizedForComparison(desiredIngress)
 			}
-			// Update existing cluster ingress, if needed.
-			if util.ObjectMetaAndSpecEquivalent(baseIngress, clusterIngress) {
-				glog.V(4).Infof("Ingress %q in cluster %q does not need an update: cluster ingress is equivalent to federated ingress", ingress, cluster.Name)
-			} else {
-				glog.V(4).Infof("Ingress %s in cluster %s needs an update: cluster ingress %v is not equivalent to federated ingress %v", ingress, cluster.Name, clusterIngress, desiredIngress)
-				objMeta, err := api.Scheme.DeepCopy(&clusterIngress.ObjectMeta)
-				if err != nil {
-					glog.Errorf("Error deep copying ObjectMeta: %v", err)
-					ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
-				}
-				objMetaCopy, ok := objMeta.(*v1.ObjectMeta)
-				if !ok {
-					glog.Errorf("Internal error: Failed to cast to v1.ObjectMeta: %v", objMeta)
-					ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
-				}
-				desiredIngress.ObjectMeta = *objMetaCopy
-				// Merge any annotations and labels on the federated ingress onto the underlying cluster ingress,
-				// overwriting duplicates.
-				if desiredIngress.ObjectMeta.Annotations == nil {
-					desiredIngress.ObjectMeta.Annotations = make(map[string]string)
-				}
-				for key, val := range baseIngress.ObjectMeta.Annotations {
-					desiredIngress.ObjectMeta.Annotations[key] = val
-				}
-				if desiredIngress.ObjectMeta.Labels == nil {
-					desiredIngress.ObjectMeta.Labels = make(map[string]string)
-				}
-				for key, val := range baseIngress.ObjectMeta.Labels {
-					desiredIngress.ObjectMeta.Labels[key] = val
-				}
-				ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "UpdateInCluster",
-					"Updating ingress in cluster %s", cluster.Name)
+			compareAgainst := pkgruntime.Object(source)
+			comparableClusterIngress := normalizedForComparison(clusterIngress)
+			if ic.ignoreClusterOwnedAnnotations {
+				clusterOwned := clusterOwnedAnnotationKeys(baseIngress, clusterIngress)
+				comparableClusterIngress = withoutAnnotationKeys(comparableClusterIngress, clusterOwned)
+				compareAgainst = withoutAnnotationKeys(source, clusterOwned)
+			}
+			if !util.ObjectMetaAndSpecEquivalent(compareAgainst, comparableClusterIngress) &&
+				!clusterMutationTolerated(desiredIngress, clusterIngress, baseIngress) {
+				change.Action = actionUpdate
+				change.Diff = semanticIngressDiff(withoutManagedByMarker(clusterIngress), withoutManagedByMarker(desiredIngress))
+			}
+		}
+		plan = append(plan, change)
+	}
+	return plan, nil
+}
 
-				operations = append(operations, util.FederatedOperation{
-					Type:        util.OperationTypeUpdate,
-					Obj:         desiredIngress,
-					ClusterName: cluster.Name,
-				})
-				// TODO: Transfer any readonly (target-proxy, url-map etc) annotations from the master cluster to the federation, if this is the master cluster.
-				// This is only for consistency, so that the federation ingress metadata matches the underlying clusters.  It's not actually required				}
+// IsFullyReconciled reports whether the named federated ingress currently
+// needs no work at all: it exists, every targeted ready cluster holds an
+// equivalent copy, and any loadbalancer address reported by a cluster has
+// been aggregated onto the federated status. A missing ingress is reported
+// as not reconciled rather than as an error, so callers can poll a name that
+// is still being created. Intended for higher-level orchestration that gates
+// on ingress readiness; like PlanReconcile it works from the informer caches.
+func (ic *IngressController) IsFullyReconciled(namespace, name string) (bool, error) {
+	if !ic.isSynced() {
+		return false, nil
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}.String()
+	baseIngressObj, exists, err := ic.ingressInformerStore.GetByKey(key)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+	baseIngress, ok := baseIngressObj.(*extensionsv1beta1.Ingress)
+	if !ok {
+		return false, fmt.Errorf("internal error: object with key %q is not an ingress", key)
+	}
+	plan, err := ic.PlanReconcile(namespace, name)
+	if err != nil {
+		return false, err
+	}
+	for _, change := range plan {
+		if change.Action != actionNoop {
+			return false, nil
+		}
+	}
+	// A cluster reporting a loadbalancer address that the federated status
+	// has not absorbed yet means status aggregation is still pending.
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
+	if err != nil {
+		return false, err
+	}
+	clusters, _ = ic.splitSelfReferenceClusters(clusters)
+	clusters, _ = splitLBCapableClusters(clusters)
+	aggregated := ic.aggregatedLoadBalancerStatus(baseIngress, clusters)
+	if len(aggregated.Ingress) > 0 && len(baseIngress.Status.LoadBalancer.Ingress) == 0 {
+		return false, nil
+	}
+	return true, nil
+}
+
+// maxIngressDiffLength caps the diff text attached to logs, events and plans
+// so a pathological object cannot blow up an event or log line.
+const maxIngressDiffLength = 1024
+
+// semanticIngressDiff produces a compact, path-based summary of the
+// differences between the current cluster copy and the desired state, e.g.
+// `spec.backend.serviceName: "old" -> "new"`. Only the propagated metadata
+// subset (labels and annotations) and the spec are compared. Values under
+// sensitive-looking annotation keys are redacted, and the overall size is
+// capped at maxIngressDiffLength.
+func semanticIngressDiff(current, desired *extensionsv1beta1.Ingress) string {
+	entries := []string{}
+	entries = append(entries, diffStringMaps("labels", current.ObjectMeta.Labels, desired.ObjectMeta.Labels)...)
+	entries = append(entries, diffStringMaps("annotations", current.ObjectMeta.Annotations, desired.ObjectMeta.Annotations)...)
+
+	currentBackend, desiredBackend := current.Spec.Backend, desired.Spec.Backend
+	switch {
+	case currentBackend == nil && desiredBackend != nil:
+		entries = append(entries, fmt.Sprintf("spec.backend.serviceName: <none> -> %q", desiredBackend.ServiceName))
+	case currentBackend != nil && desiredBackend == nil:
+		entries = append(entries, fmt.Sprintf("spec.backend.serviceName: %q -> <none>", currentBackend.ServiceName))
+	case currentBackend != nil && desiredBackend != nil:
+		if currentBackend.ServiceName != desiredBackend.ServiceName {
+			entries = append(entries, fmt.Sprintf("spec.backend.serviceName: %q -> %q", currentBackend.ServiceName, desiredBackend.ServiceName))
+		}
+		if currentBackend.ServicePort != desiredBackend.ServicePort {
+			entries = append(entries, fmt.Sprintf("spec.backend.servicePort: %s -> %s", currentBackend.ServicePort.String(), desiredBackend.ServicePort.String()))
+		}
+	}
+	if !reflect.DeepEqual(current.Spec.TLS, desired.Spec.TLS) {
+		entries = append(entries, fmt.Sprintf("spec.tls: %d -> %d entries", len(current.Spec.TLS), len(desired.Spec.TLS)))
+	}
+	if !reflect.DeepEqual(current.Spec.Rules, desired.Spec.Rules) {
+		entries = append(entries, fmt.Sprintf("spec.rules: %s -> %s", describeRuleHosts(current.Spec.Rules), describeRuleHosts(desired.Spec.Rules)))
+	}
+	diff := strings.Join(entries, "; ")
+	if len(diff) > maxIngressDiffLength {
+		diff = diff[:maxIngressDiffLength] + "...(truncated)"
+	}
+	return diff
+}
+
+// diffStringMaps returns one entry per key whose value differs between the
+// two maps, prefixed with the given path. Missing keys read as <none>.
+func diffStringMaps(path string, current, desired map[string]string) []string {
+	keys := sets.NewString()
+	for key := range current {
+		keys.Insert(key)
+	}
+	for key := range desired {
+		keys.Insert(key)
+	}
+	entries := []string{}
+	for _, key := range keys.List() {
+		currentValue, inCurrent := current[key]
+		desiredValue, inDesired := desired[key]
+		if inCurrent && inDesired && currentValue == desiredValue {
+			continue
+		}
+		entries = append(entries, fmt.Sprintf("%s.%s: %s -> %s",
+			path, key, describeMapValue(key, currentValue, inCurrent), describeMapValue(key, desiredValue, inDesired)))
+	}
+	return entries
+}
+
+// describeMapValue renders a label or annotation value for a diff entry,
+// redacting values whose key suggests secret material.
+func describeMapValue(key, value string, present bool) string {
+	if !present {
+		return "<none>"
+	}
+	lowerKey := strings.ToLower(key)
+	for _, sensitive := range []string{"secret", "token", "password"} {
+		if strings.Contains(lowerKey, sensitive) {
+			return "<redacted>"
+		}
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// describeRuleHosts summarizes a rule list by its hosts, keeping rule diffs
+// readable without dumping the full HTTP paths.
+func describeRuleHosts(rules []extensionsv1beta1.IngressRule) string {
+	hosts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		hosts = append(hosts, rule.Host)
+	}
+	return fmt.Sprintf("%d rule(s) [%s]", len(rules), strings.Join(hosts, ","))
+}
+
+// DesiredClusterIngress returns the fully computed ingress that the federation
+// would write to the named cluster for the given federated ingress, without
+// writing it. The result reflects the same object meta merging that
+// reconciliation applies, which makes it suitable for comparison debugging
+// when a cluster copy looks out of sync.
+func (ic *IngressController) DesiredClusterIngress(clusterName, namespace, name string) (*extensionsv1beta1.Ingress, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}.String()
+	baseIngressObj, exists, err := ic.ingressInformerStore.GetByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("federated ingress %q not found", key)
+	}
+	baseIngress, ok := baseIngressObj.(*extensionsv1beta1.Ingress)
+	if !ok {
+		return nil, fmt.Errorf("internal error: object with key %q is not an ingress", key)
+	}
+	var clusterIngress *extensionsv1beta1.Ingress
+	clusterKey := key
+	if cluster, _, err := ic.ingressFederatedInformer.GetReadyCluster(clusterName); err == nil && cluster != nil {
+		clusterKey = clusterIngressKey(cluster, types.NamespacedName{Namespace: namespace, Name: name})
+	}
+	clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(clusterName, clusterKey)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		clusterIngress = clusterIngressObj.(*extensionsv1beta1.Ingress)
+	}
+	return computeDesiredIngress(baseIngress, clusterIngress, ic.adoptionConflictPolicy, ic.propagationPolicyFor(baseIngress))
+}
+
+// staggerUpdateOperations limits the number of update operations executed in a
+// single reconciliation to maxUnavailable, returning the operations to execute
+// now and the number of updates deferred to a later reconciliation. Creates and
+// deletes are never deferred - only spec updates of already existing cluster
+// ingresses are staggered.
+// disruptiveAnnotationChange returns a configured disruptive annotation key
+// whose value the queued update would change on the cluster copy, if any.
+func (ic *IngressController) disruptiveAnnotationChange(clusterIngress, desiredIngress *extensionsv1beta1.Ingress) (string, bool) {
+	for _, key := range ic.disruptiveAnnotations.List() {
+		if clusterIngress.ObjectMeta.Annotations[key] != desiredIngress.ObjectMeta.Annotations[key] {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// prioritizeOperations moves operations targeting the given clusters to the
+// front, preserving the relative order of the rest, so that they land in the
+// first batch of a staggered rollout.
+func prioritizeOperations(operations []util.FederatedOperation, priorityClusters sets.String) []util.FederatedOperation {
+	if priorityClusters.Len() == 0 {
+		return operations
+	}
+	result := make([]util.FederatedOperation, 0, len(operations))
+	for _, operation := range operations {
+		if priorityClusters.Has(operation.ClusterName) {
+			result = append(result, operation)
+		}
+	}
+	for _, operation := range operations {
+		if !priorityClusters.Has(operation.ClusterName) {
+			result = append(result, operation)
+		}
+	}
+	return result
+}
+
+func staggerUpdateOperations(operations []util.FederatedOperation, maxUnavailable int) ([]util.FederatedOperation, int) {
+	if maxUnavailable < 1 {
+		maxUnavailable = 1
+	}
+	result := make([]util.FederatedOperation, 0, len(operations))
+	updates := 0
+	deferred := 0
+	for _, operation := range operations {
+		if operation.Type == util.OperationTypeUpdate {
+			if updates >= maxUnavailable {
+				deferred++
+				continue
 			}
+			updates++
 		}
+		result = append(result, operation)
 	}
+	return result, deferred
+}
 
-	if len(operations) == 0 {
-		// Everything is in order
-		glog.V(4).Infof("Ingress %q is up-to-date in all clusters - no propagation to clusters required.", ingress)
+// throttledRetryDelay returns the delay before retrying writes to the given
+// cluster after the given error. It honors an explicit Retry-After from a 429
+// response and escalates the per-cluster backoff on repeated throttling. The
+// second return value is false if the error does not indicate throttling.
+func (ic *IngressController) throttledRetryDelay(clusterName string, err error) (time.Duration, bool) {
+	if !errors.IsTooManyRequests(err) {
+		return 0, false
+	}
+	ic.clusterThrottleBackoff.Next(clusterName, time.Now())
+	delay := ic.clusterThrottleBackoff.Get(clusterName)
+	if statusErr, ok := err.(errors.APIStatus); ok {
+		if details := statusErr.Status().Details; details != nil && details.RetryAfterSeconds > 0 {
+			suggested := time.Duration(details.RetryAfterSeconds) * time.Second
+			if suggested > delay {
+				delay = suggested
+			}
+		}
+	}
+	return delay, true
+}
+
+// setClusterThrottledCondition adds or removes the Throttled condition on the
+// given cluster's status. No-op if the condition already matches.
+func (ic *IngressController) setClusterThrottledCondition(clusterName string, throttled bool) {
+	ic.setClusterCondition(clusterName, clusterThrottledCondition, throttled,
+		"ClusterThrottled", "Cluster api server is rate-limiting federation requests")
+}
+
+// setClusterWatchDegradedCondition adds or removes the WatchDegraded
+// condition on the given cluster's status. No-op if the condition already
+// matches. When the condition is set, the message carries the informer's
+// last sync state so operators can judge how far behind the cluster is.
+func (ic *IngressController) setClusterWatchDegradedCondition(clusterName string, degraded bool) {
+	message := "Cluster api server forbids watch requests; the federation is polling instead"
+	if degraded {
+		if lastSyncRV := ic.ingressFederatedInformer.LastSyncResourceVersion(clusterName); lastSyncRV != "" {
+			message = fmt.Sprintf("%s (ingress informer last synced to resource version %q at %s)",
+				message, lastSyncRV, ic.ingressFederatedInformer.LastSyncTime(clusterName).Format(time.RFC3339))
+		}
+	}
+	ic.setClusterCondition(clusterName, clusterWatchDegradedCondition, degraded, "WatchForbidden", message)
+}
+
+// setClusterSelfReferenceCondition adds or removes the SelfReference
+// condition on the given cluster's status. No-op if the condition already
+// matches.
+func (ic *IngressController) setClusterSelfReferenceCondition(clusterName string, self bool) {
+	ic.setClusterCondition(clusterName, clusterSelfReferenceCondition, self,
+		"HostClusterJoined", "Cluster api endpoint is the federation's own; ingress propagation is skipped")
+}
+
+// setClusterCondition sets (active) or clears (!active) the given condition
+// on the cluster, going through the federation api only when the recorded
+// state actually changes.
+func (ic *IngressController) setClusterCondition(clusterName string, conditionType federationapi.ClusterConditionType, active bool, reason, message string) {
+	cluster, found, err := ic.ingressFederatedInformer.GetReadyCluster(clusterName)
+	if !found || err != nil {
 		return
 	}
-	glog.V(4).Infof("Calling federatedUpdater.Update() - operations: %v", operations)
-	err = ic.federatedIngressUpdater.UpdateWithOnError(operations, ic.updateTimeout, func(op util.FederatedOperation, operror error) {
-		ic.eventRecorder.Eventf(baseIngress, api.EventTypeNormal, "FailedClusterUpdate",
-			"Ingress update in cluster %s failed: %v", op.ClusterName, operror)
-	})
+	isActive := false
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type == conditionType {
+			isActive = condition.Status == v1.ConditionTrue
+		}
+	}
+	if isActive == active {
+		return
+	}
+	clusterObj, err := api.Scheme.DeepCopy(cluster)
 	if err != nil {
-		glog.Errorf("Failed to execute updates for %s: %v", ingress, err)
-		ic.deliverIngress(ingress, ic.ingressReviewDelay, true)
+		glog.Errorf("Failed to clone cluster %q while updating %s condition: %v", clusterName, conditionType, err)
 		return
 	}
-	// Schedule another periodic reconciliation, only to account for possible bugs in watch processing.
-	ic.deliverIngress(ingress, ic.ingressReviewDelay, false)
+	cluster = clusterObj.(*federationapi.Cluster)
+	newConditions := make([]federationapi.ClusterCondition, 0, len(cluster.Status.Conditions)+1)
+	for _, condition := range cluster.Status.Conditions {
+		if condition.Type != conditionType {
+			newConditions = append(newConditions, condition)
+		}
+	}
+	if active {
+		newConditions = append(newConditions, federationapi.ClusterCondition{
+			Type:               conditionType,
+			Status:             v1.ConditionTrue,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: metav1.Now(),
+		})
+	}
+	cluster.Status.Conditions = newConditions
+	if _, err := ic.federatedApiClient.Federation().Clusters().UpdateStatus(cluster); err != nil {
+		glog.Errorf("Failed to update %s condition of cluster %q: %v", conditionType, clusterName, err)
+	}
+}
+
+// deleteDeferralRemaining returns how much longer delete actions should be
+// deferred. Zero means deletes may proceed.
+func (ic *IngressController) deleteDeferralRemaining() time.Duration {
+	elapsed := ic.clock.Since(ic.startTime)
+	if ic.startTime.IsZero() || elapsed >= ic.deleteWarmupPeriod {
+		return 0
+	}
+	return ic.deleteWarmupPeriod - elapsed
 }
 
 // delete deletes the given ingress or returns error if the deletion was not complete.
+// deleteMappedClusterCopies deletes the copies of the given federated ingress
+// living in clusters with a namespace mapping. The deletion helper only finds
+// copies under the canonical namespace, so mapped copies are cleaned up here.
+func (ic *IngressController) deleteMappedClusterCopies(ingress *extensionsv1beta1.Ingress) error {
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
+	if err != nil {
+		return err
+	}
+	ingressName := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	operations := make([]util.FederatedOperation, 0)
+	for _, cluster := range clusters {
+		if clusterNamespace(cluster, ingress.Namespace) == ingress.Namespace {
+			continue
+		}
+		clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, clusterIngressKey(cluster, ingressName))
+		if err != nil || !found {
+			continue
+		}
+		operations = append(operations, util.FederatedOperation{
+			Type:        util.OperationTypeDelete,
+			ClusterName: cluster.Name,
+			Obj:         clusterIngressObj.(pkgruntime.Object),
+		})
+	}
+	if len(operations) == 0 {
+		return nil
+	}
+	return ic.federatedIngressUpdater.UpdateWithPolicies(operations, ic.updateTimeout, ic.retryPolicies, nil)
+}
+
+// deleteSecondariesBeforePrimary reverses the primary-first creation order on
+// deletion: it deletes the copies outside the designated primary cluster and
+// reports whether that is complete, so the caller can hold the primary copy's
+// deletion back until the secondaries are gone. Returns true when no
+// secondary copies remain, or when the primary copy itself is already gone
+// and there is no order left to enforce.
+func (ic *IngressController) deleteSecondariesBeforePrimary(ingress *extensionsv1beta1.Ingress, primaryClusterName string) (bool, error) {
+	clusters, err := ic.ingressFederatedInformer.GetReadyClusters()
+	if err != nil {
+		return false, err
+	}
+	ingressName := types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name}
+	primaryFound := false
+	operations := make([]util.FederatedOperation, 0)
+	for _, cluster := range clusters {
+		clusterIngressObj, found, err := ic.ingressFederatedInformer.GetTargetStore().GetByKey(cluster.Name, clusterIngressKey(cluster, ingressName))
+		if err != nil {
+			return false, err
+		}
+		if !found {
+			continue
+		}
+		if cluster.Name == primaryClusterName {
+			primaryFound = true
+			continue
+		}
+		operations = append(operations, util.FederatedOperation{
+			Type:        util.OperationTypeDelete,
+			ClusterName: cluster.Name,
+			Obj:         clusterIngressObj.(pkgruntime.Object),
+		})
+	}
+	if !primaryFound || len(operations) == 0 {
+		return true, nil
+	}
+	if err := ic.federatedIngressUpdater.Update(operations, ic.updateTimeout); err != nil {
+		return false, err
+	}
+	// The deletes are issued; wait for the cluster stores to observe them
+	// before letting the primary copy go.
+	return false, nil
+}
+
 func (ic *IngressController) delete(ingress *extensionsv1beta1.Ingress) error {
 	glog.V(3).Infof("Handling deletion of ingress: %v", *ingress)
+	if err := ic.deleteMappedClusterCopies(ingress); err != nil {
+		return err
+	}
+	if primaryClusterName := ingress.ObjectMeta.Annotations[primaryClusterAnnotation]; primaryClusterName != "" {
+		done, err := ic.deleteSecondariesBeforePrimary(ingress, primaryClusterName)
+		if err != nil {
+			return err
+		}
+		if !done {
+			return fmt.Errorf("waiting for secondary cluster copies of ingress %s/%s to be deleted before the one in primary cluster %s", ingress.Namespace, ingress.Name, primaryClusterName)
+		}
+	}
 	_, err := ic.deletionHelper.HandleObjectInUnderlyingClusters(ingress)
 	if err != nil {
 		return err
 	}
 
+	if ic.ownerReferencesEnabled {
+		// Remove the per-namespace anchors if this was the last federated
+		// ingress in its namespace.
+		remaining := 0
+		for _, obj := range ic.ingressInformerStore.List() {
+			if other, ok := obj.(*extensionsv1beta1.Ingress); ok {
+				if other.Namespace == ingress.Namespace && other.Name != ingress.Name {
+					remaining++
+				}
+			}
+		}
+		if clusters, err := ic.ingressFederatedInformer.GetReadyClusters(); err == nil {
+			for _, cluster := range clusters {
+				if err := ic.anchorManager.CleanupAnchor(cluster.Name, ingress.Namespace, remaining); err != nil {
+					glog.Errorf("Failed to clean up anchor ConfigMap in cluster %q for namespace %q: %v", cluster.Name, ingress.Namespace, err)
+				}
+			}
+		}
+	}
+
+	if !ic.federatedWritesAllowed {
+		// The copies in this shard's clusters are cleaned up above; deleting
+		// the federated object itself belongs to the writer shard.
+		return nil
+	}
 	err = ic.federatedApiClient.Extensions().Ingresses(ingress.Namespace).Delete(ingress.Name, nil)
 	if err != nil {
 		// Its all good if the error is not found error. That means it is deleted already and we do not have to do anything.
@@ -0,0 +1,212 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"expvar"
+	"sync"
+
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Action labels for the federation_ingress_cluster_actions_total counter.
+// A high update rate combined with a low noop rate indicates the controller is
+// doing real work; a high update rate with no spec changes indicates an update
+// loop.
+const (
+	actionCreate       = "create"
+	actionUpdate       = "update"
+	actionDelete       = "delete"
+	actionStatusUpdate = "status-update"
+	actionNoop         = "noop"
+)
+
+var (
+	ingressClusterActions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "federation_ingress_cluster_actions_total",
+			Help: "Number of actions taken by the federated ingress controller per member cluster, broken down by action type.",
+		},
+		[]string{"action", "cluster"},
+	)
+	ingressUnsyncedReconcileSkips = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "federation_ingress_unsynced_reconcile_skips_total",
+			Help: "Number of reconciles the federated ingress controller deferred because an informer had not synced yet, broken down by the lagging informer.",
+		},
+		[]string{"reason"},
+	)
+	ingressFederationAPIErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "federation_ingress_federation_api_errors_total",
+			Help: "Number of federation api server errors seen by the federated ingress controller, broken down by operation.",
+		},
+		[]string{"operation"},
+	)
+	ingressCallsPerReconcile = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "federation_ingress_cluster_api_calls_per_reconcile",
+			Help: "Average number of cluster API calls per reconcile over the recent reconcile window kept by the federated ingress controller. Noop reconcile decisions count as zero calls.",
+		},
+	)
+	ingressConvergenceBudgetExceeded = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "federation_ingress_convergence_budget_exceeded_total",
+			Help: "Number of times a federated ingress stayed out of sync with a Ready member cluster beyond the configured convergence budget.",
+		},
+		[]string{"ingress"},
+	)
+	ingressClusterCircuitOpen = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_ingress_cluster_circuit_open",
+			Help: "Whether the circuit breaker for a member cluster is currently open (1) or closed (0).",
+		},
+		[]string{"cluster"},
+	)
+	ingressPendingFinalizerRemovals = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "federation_ingress_pending_finalizer_removals",
+			Help: "Number of federated ingresses currently queued for finalizer removal by the finalizer removal batcher.",
+		},
+	)
+	ingressFinalizerRemovals = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "federation_ingress_finalizer_removals_total",
+			Help: "Number of finalizer removal updates completed by the finalizer removal batcher.",
+		},
+	)
+	ingressQuotaBlockedNamespaces = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_ingress_quota_blocked_namespaces",
+			Help: "Number of namespaces per member cluster into which ingress propagation is currently blocked by an exhausted resource quota.",
+		},
+		[]string{"cluster"},
+	)
+	registerMetricsOnce sync.Once
+
+	// When non-nil, cluster action counts are mirrored into this expvar map
+	// (published as "federation_ingress_cluster_actions") under
+	// "<action>.<cluster>" keys, as a dependency-light alternative to
+	// Prometheus available at /debug/vars.
+	expvarClusterActions    *expvar.Map
+	enableExpvarMetricsOnce sync.Once
+)
+
+func registerMetrics() {
+	registerMetricsOnce.Do(func() {
+		prometheus.MustRegister(ingressClusterActions)
+		prometheus.MustRegister(ingressUnsyncedReconcileSkips)
+		prometheus.MustRegister(ingressFederationAPIErrors)
+		prometheus.MustRegister(ingressCallsPerReconcile)
+		prometheus.MustRegister(ingressConvergenceBudgetExceeded)
+		prometheus.MustRegister(ingressClusterCircuitOpen)
+		prometheus.MustRegister(ingressPendingFinalizerRemovals)
+		prometheus.MustRegister(ingressFinalizerRemovals)
+		prometheus.MustRegister(ingressQuotaBlockedNamespaces)
+	})
+}
+
+// EnableExpvarMetrics turns on mirroring of the cluster action counters into
+// an expvar map named "federation_ingress_cluster_actions". Safe to call
+// multiple times; the map is published only once.
+func EnableExpvarMetrics() {
+	enableExpvarMetricsOnce.Do(func() {
+		expvarClusterActions = expvar.NewMap("federation_ingress_cluster_actions")
+	})
+}
+
+// recordUnsyncedReconcileSkip increments the deferred-reconcile counter for
+// the given reason.
+func recordUnsyncedReconcileSkip(reason string) {
+	registerMetrics()
+	ingressUnsyncedReconcileSkips.WithLabelValues(reason).Inc()
+	if expvarClusterActions != nil {
+		expvarClusterActions.Add("unsynced-skip."+reason, 1)
+	}
+}
+
+// recordFederationAPIError counts an error returned by the federation api
+// server for the given operation.
+func recordFederationAPIError(operation string) {
+	registerMetrics()
+	ingressFederationAPIErrors.WithLabelValues(operation).Inc()
+	if expvarClusterActions != nil {
+		expvarClusterActions.Add("federation-api-error."+operation, 1)
+	}
+}
+
+// recordCallsPerReconcile publishes the current average number of cluster API
+// calls per reconcile, as computed from the reconcile replay log.
+func recordCallsPerReconcile(value float64) {
+	registerMetrics()
+	ingressCallsPerReconcile.Set(value)
+}
+
+// recordConvergenceBudgetExceeded counts a convergence stall for the given
+// federated ingress (namespace/name).
+func recordConvergenceBudgetExceeded(key string) {
+	registerMetrics()
+	ingressConvergenceBudgetExceeded.WithLabelValues(key).Inc()
+	if expvarClusterActions != nil {
+		expvarClusterActions.Add("convergence-budget-exceeded."+key, 1)
+	}
+}
+
+// recordClusterAction increments the action counter for the given action type
+// and cluster.
+func recordClusterAction(action, clusterName string) {
+	registerMetrics()
+	ingressClusterActions.WithLabelValues(action, clusterName).Inc()
+	if expvarClusterActions != nil {
+		expvarClusterActions.Add(action+"."+clusterName, 1)
+	}
+}
+
+// recordPendingFinalizerRemovals publishes the current depth of the finalizer
+// removal queue.
+func recordPendingFinalizerRemovals(count int) {
+	registerMetrics()
+	ingressPendingFinalizerRemovals.Set(float64(count))
+}
+
+// recordFinalizerRemoval counts one completed finalizer removal update.
+func recordFinalizerRemoval() {
+	registerMetrics()
+	ingressFinalizerRemovals.Inc()
+	if expvarClusterActions != nil {
+		expvarClusterActions.Add("finalizer-removal", 1)
+	}
+}
+
+// recordQuotaBlockedNamespaces publishes the number of quota-blocked
+// namespaces of the given cluster.
+func recordQuotaBlockedNamespaces(clusterName string, count int) {
+	registerMetrics()
+	ingressQuotaBlockedNamespaces.WithLabelValues(clusterName).Set(float64(count))
+}
+
+// recordCircuitState records a cluster circuit breaker transition.
+func recordCircuitState(clusterName, state string) {
+	registerMetrics()
+	value := 0.0
+	if state == util.CircuitOpen {
+		value = 1.0
+	}
+	ingressClusterCircuitOpen.WithLabelValues(clusterName).Set(value)
+}
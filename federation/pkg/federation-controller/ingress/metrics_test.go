@@ -0,0 +1,70 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func actionCounterValue(t *testing.T, action, cluster string) float64 {
+	counter, err := ingressClusterActions.GetMetricWithLabelValues(action, cluster)
+	assert.NoError(t, err)
+	metric := &dto.Metric{}
+	assert.NoError(t, counter.Write(metric))
+	return metric.GetCounter().GetValue()
+}
+
+// Checks that the noop action label increments when a reconcile leaves the
+// cluster untouched, and that other action types are recorded independently.
+func TestRecordClusterAction(t *testing.T) {
+	before := actionCounterValue(t, actionNoop, "metrics-cluster")
+	recordClusterAction(actionNoop, "metrics-cluster")
+	assert.Equal(t, before+1, actionCounterValue(t, actionNoop, "metrics-cluster"))
+
+	noopBefore := actionCounterValue(t, actionNoop, "metrics-cluster")
+	before = actionCounterValue(t, actionUpdate, "metrics-cluster")
+	recordClusterAction(actionUpdate, "metrics-cluster")
+	assert.Equal(t, before+1, actionCounterValue(t, actionUpdate, "metrics-cluster"))
+	// The noop counter must not move when an update is recorded.
+	assert.Equal(t, noopBefore, actionCounterValue(t, actionNoop, "metrics-cluster"))
+}
+
+// Checks that once expvar mirroring is enabled the published map follows the
+// action counters recorded during reconciles, and that it stays quiet for
+// actions recorded before it was enabled.
+func TestExpvarMetrics(t *testing.T) {
+	recordClusterAction(actionCreate, "expvar-cluster")
+	EnableExpvarMetrics()
+	assert.Nil(t, expvarClusterActions.Get(actionCreate+".expvar-cluster"),
+		"actions recorded before enabling must not appear")
+
+	recordClusterAction(actionCreate, "expvar-cluster")
+	recordClusterAction(actionCreate, "expvar-cluster")
+	recordClusterAction(actionNoop, "expvar-cluster")
+
+	created := expvarClusterActions.Get(actionCreate + ".expvar-cluster")
+	if assert.NotNil(t, created) {
+		assert.Equal(t, "2", created.String())
+	}
+	noop := expvarClusterActions.Get(actionNoop + ".expvar-cluster")
+	if assert.NotNil(t, noop) {
+		assert.Equal(t, "1", noop.String())
+	}
+}
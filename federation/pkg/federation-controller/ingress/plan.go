@@ -0,0 +1,151 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	pkgruntime "k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/types"
+)
+
+// Plan is the full set of per-cluster operations the ingress sync controller
+// would perform to converge the given federated objects onto the given
+// cluster states. It is serializable, so CI tooling can diff or archive it.
+type Plan struct {
+	Changes []PlannedObjectChange `json:"changes"`
+}
+
+// PlannedObjectChange is one per-cluster action of a Plan, annotated with the
+// federated object it is for.
+type PlannedObjectChange struct {
+	// Key is the namespace/name of the federated object.
+	Key string `json:"key"`
+	// Cluster is the name of the member cluster the action targets.
+	Cluster string `json:"cluster"`
+	// Action is one of the cluster action names also used by the metrics:
+	// create, update, delete or noop.
+	Action string `json:"action"`
+	// Diff is a compact summary of the object sections that differ between
+	// the cluster copy and the desired state, empty for noops and creates.
+	Diff string `json:"diff,omitempty"`
+}
+
+// ComputePlan computes the create/update/delete operations the ingress sync
+// controller would perform for the given federated objects against the given
+// member cluster states, without performing any of them. Unlike
+// PlanReconcile it needs no running controller: both sides are passed in
+// directly (e.g. federated objects from a manifest bundle and cluster states
+// from API dumps), so it can run in CI. Per-object propagation policy
+// annotations are honored; everything else is evaluated under the
+// controller's default configuration. Clusters are visited in name order and
+// federated objects in input order, so the plan is deterministic.
+func ComputePlan(fedObjects []pkgruntime.Object, clusterStates map[string][]pkgruntime.Object) (*Plan, error) {
+	clusterNames := make([]string, 0, len(clusterStates))
+	for clusterName := range clusterStates {
+		clusterNames = append(clusterNames, clusterName)
+	}
+	sort.Strings(clusterNames)
+
+	plan := &Plan{Changes: []PlannedObjectChange{}}
+	for _, fedObject := range fedObjects {
+		baseIngress, ok := fedObject.(*extensionsv1beta1.Ingress)
+		if !ok {
+			return nil, fmt.Errorf("unsupported federated object type %T: only ingresses are planned by this package", fedObject)
+		}
+		key := types.NamespacedName{Namespace: baseIngress.Namespace, Name: baseIngress.Name}.String()
+		propagationPolicy, err := planPropagationPolicy(baseIngress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid propagation policy on %q: %v", key, err)
+		}
+		for _, clusterName := range clusterNames {
+			clusterIngress, err := findClusterIngress(clusterStates[clusterName], baseIngress.Namespace, baseIngress.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid state for cluster %q: %v", clusterName, err)
+			}
+			change, err := planClusterChange(baseIngress, clusterIngress, clusterName, propagationPolicy)
+			if err != nil {
+				return nil, fmt.Errorf("failed to plan %q in cluster %q: %v", key, clusterName, err)
+			}
+			change.Key = key
+			plan.Changes = append(plan.Changes, change)
+		}
+	}
+	return plan, nil
+}
+
+// planPropagationPolicy parses the per-object propagation policy annotation,
+// if present. Unlike the controller, which falls back to the controller-wide
+// policy and emits an event, the pure planner surfaces an invalid policy as
+// an error.
+func planPropagationPolicy(baseIngress *extensionsv1beta1.Ingress) (*util.PropagationPolicy, error) {
+	spec, found := baseIngress.ObjectMeta.Annotations[util.PropagationPolicyAnnotation]
+	if !found {
+		return nil, nil
+	}
+	return util.ParsePropagationPolicy(spec)
+}
+
+// findClusterIngress returns the ingress with the given namespace and name
+// from the cluster state, or nil when the cluster holds no copy. Non-ingress
+// objects in the state are ignored, so full cluster dumps can be passed in.
+func findClusterIngress(state []pkgruntime.Object, namespace, name string) (*extensionsv1beta1.Ingress, error) {
+	for _, obj := range state {
+		ingress, ok := obj.(*extensionsv1beta1.Ingress)
+		if !ok {
+			continue
+		}
+		if ingress.Namespace == namespace && ingress.Name == name {
+			return ingress, nil
+		}
+	}
+	return nil, nil
+}
+
+// planClusterChange decides the action for one federated ingress in one
+// cluster, mirroring the decision the reconciliation loop takes under the
+// default controller configuration.
+func planClusterChange(baseIngress, clusterIngress *extensionsv1beta1.Ingress, clusterName string, propagationPolicy *util.PropagationPolicy) (PlannedObjectChange, error) {
+	change := PlannedObjectChange{Cluster: clusterName, Action: actionNoop}
+	desiredIngress, err := computeDesiredIngress(baseIngress, clusterIngress, AdoptionConflictOverwrite, propagationPolicy)
+	if err != nil {
+		return change, err
+	}
+	applyHostTemplate(desiredIngress, clusterName)
+	if clusterIngress == nil {
+		change.Action = actionCreate
+		return change, nil
+	}
+	if isStaleClusterCopy(baseIngress, clusterIngress) {
+		change.Action = actionDelete
+		change.Diff = "stale copy of a previous federated ingress"
+		return change, nil
+	}
+	source := normalizedForComparison(baseIngress)
+	if hasHostTemplate(baseIngress) {
+		source = normalizedForComparison(desiredIngress)
+	}
+	if !util.ObjectMetaAndSpecEquivalent(pkgruntime.Object(source), normalizedForComparison(clusterIngress)) &&
+		!clusterMutationTolerated(desiredIngress, clusterIngress, baseIngress) {
+		change.Action = actionUpdate
+		change.Diff = semanticIngressDiff(withoutManagedByMarker(clusterIngress), withoutManagedByMarker(desiredIngress))
+	}
+	return change, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"encoding/json"
+	"testing"
+
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	pkgruntime "k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func planIngress(name, backend string) *extensionsv1beta1.Ingress {
+	return &extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      name,
+			Namespace: "mynamespace",
+			UID:       "base-uid",
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{ServiceName: backend},
+		},
+	}
+}
+
+// Checks that ComputePlan reports create, noop, update and delete for the
+// representative cluster states, in deterministic cluster order, without any
+// running controller.
+func TestComputePlan(t *testing.T) {
+	baseIngress := planIngress("test-ingress", "backend")
+
+	equivalentCopy := planIngress("test-ingress", "backend")
+	outdatedCopy := planIngress("test-ingress", "old-backend")
+	staleCopy := planIngress("test-ingress", "backend")
+	staleCopy.ObjectMeta.Labels = map[string]string{util.ManagedByLabel: "previous-uid"}
+
+	plan, err := ComputePlan(
+		[]pkgruntime.Object{baseIngress},
+		map[string][]pkgruntime.Object{
+			"cluster-d": {staleCopy},
+			"cluster-b": {equivalentCopy},
+			"cluster-c": {outdatedCopy},
+			"cluster-a": {},
+		})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if !assert.Len(t, plan.Changes, 4) {
+		return
+	}
+	assert.Equal(t, PlannedObjectChange{Key: "mynamespace/test-ingress", Cluster: "cluster-a", Action: actionCreate}, plan.Changes[0])
+	assert.Equal(t, PlannedObjectChange{Key: "mynamespace/test-ingress", Cluster: "cluster-b", Action: actionNoop}, plan.Changes[1])
+	assert.Equal(t, "cluster-c", plan.Changes[2].Cluster)
+	assert.Equal(t, actionUpdate, plan.Changes[2].Action)
+	assert.Contains(t, plan.Changes[2].Diff, "backend")
+	assert.Equal(t, "cluster-d", plan.Changes[3].Cluster)
+	assert.Equal(t, actionDelete, plan.Changes[3].Action)
+
+	// The plan is serializable for CI archiving and diffing.
+	serialized, err := json.Marshal(plan)
+	assert.NoError(t, err)
+	assert.Contains(t, string(serialized), `"action":"create"`)
+}
+
+// Checks that non-ingress federated objects are rejected, while non-ingress
+// objects inside a cluster state dump are simply ignored.
+func TestComputePlanRejectsUnsupportedObjects(t *testing.T) {
+	_, err := ComputePlan([]pkgruntime.Object{&apiv1.ConfigMap{}}, map[string][]pkgruntime.Object{})
+	assert.Error(t, err)
+
+	baseIngress := planIngress("test-ingress", "backend")
+	plan, err := ComputePlan(
+		[]pkgruntime.Object{baseIngress},
+		map[string][]pkgruntime.Object{
+			"cluster-a": {&apiv1.ConfigMap{}, planIngress("test-ingress", "backend")},
+		})
+	if !assert.NoError(t, err) {
+		return
+	}
+	if assert.Len(t, plan.Changes, 1) {
+		assert.Equal(t, actionNoop, plan.Changes[0].Action)
+	}
+}
+
+// Checks that an invalid per-object propagation policy annotation surfaces
+// as an error instead of being silently ignored.
+func TestComputePlanInvalidPropagationPolicy(t *testing.T) {
+	baseIngress := planIngress("test-ingress", "backend")
+	baseIngress.ObjectMeta.Annotations = map[string]string{
+		util.PropagationPolicyAnnotation: "bogus=stanza",
+	}
+	_, err := ComputePlan([]pkgruntime.Object{baseIngress}, map[string][]pkgruntime.Object{"cluster-a": {}})
+	assert.Error(t, err)
+}
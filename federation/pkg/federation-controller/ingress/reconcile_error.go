@@ -0,0 +1,78 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+)
+
+// Phases of a reconciliation in which a write can fail. They label which kind
+// of API operation the wrapped cause came from.
+const (
+	ReconcilePhaseCreate    = "create"
+	ReconcilePhaseUpdate    = "update"
+	ReconcilePhaseDelete    = "delete"
+	ReconcilePhaseStatus    = "status"
+	ReconcilePhaseConfigMap = "configmap"
+)
+
+// ReconcileError carries structured context about a failed reconciliation
+// write: which object it was for, which cluster the write was directed at,
+// and in which phase the wrapped cause occurred. It is what LastError returns
+// for failed passes, so callers can extract the context without parsing error
+// text.
+type ReconcileError struct {
+	// Key is the namespace/name of the reconciled object.
+	Key string
+	// Cluster is the name of the member cluster the failed write was
+	// directed at, or empty for writes to the federation control plane.
+	Cluster string
+	// Phase is one of the ReconcilePhase* constants above.
+	Phase string
+	// Err is the underlying cause.
+	Err error
+}
+
+func (e *ReconcileError) Error() string {
+	if e.Cluster == "" {
+		return fmt.Sprintf("%s of %s failed: %v", e.Phase, e.Key, e.Err)
+	}
+	return fmt.Sprintf("%s of %s in cluster %s failed: %v", e.Phase, e.Key, e.Cluster, e.Err)
+}
+
+// Unwrap returns the underlying cause.
+func (e *ReconcileError) Unwrap() error {
+	return e.Err
+}
+
+func newReconcileError(key, cluster, phase string, err error) *ReconcileError {
+	return &ReconcileError{Key: key, Cluster: cluster, Phase: phase, Err: err}
+}
+
+// phaseForOperation maps a federated operation type to its reconcile phase
+// label.
+func phaseForOperation(opType util.FederatedOperationType) string {
+	switch opType {
+	case util.OperationTypeAdd:
+		return ReconcilePhaseCreate
+	case util.OperationTypeDelete:
+		return ReconcilePhaseDelete
+	}
+	return ReconcilePhaseUpdate
+}
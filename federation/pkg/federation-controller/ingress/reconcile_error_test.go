@@ -0,0 +1,124 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	federationapi "k8s.io/kubernetes/federation/apis/federation/v1beta1"
+	fakefedclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset/fake"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+	. "k8s.io/kubernetes/federation/pkg/federation-controller/util/test"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/wait"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Checks that every phase label produces a ReconcileError whose fields and
+// text carry the full context, and that the cause stays reachable via Unwrap.
+func TestReconcileErrorFields(t *testing.T) {
+	cause := fmt.Errorf("connection refused")
+	for _, phase := range []string{
+		ReconcilePhaseCreate,
+		ReconcilePhaseUpdate,
+		ReconcilePhaseDelete,
+		ReconcilePhaseStatus,
+		ReconcilePhaseConfigMap,
+	} {
+		reconcileErr := newReconcileError("myns/myingress", "cluster1", phase, cause)
+		assert.Equal(t, "myns/myingress", reconcileErr.Key)
+		assert.Equal(t, "cluster1", reconcileErr.Cluster)
+		assert.Equal(t, phase, reconcileErr.Phase)
+		assert.Equal(t, cause, reconcileErr.Unwrap())
+		assert.Contains(t, reconcileErr.Error(), phase)
+		assert.Contains(t, reconcileErr.Error(), "myns/myingress")
+		assert.Contains(t, reconcileErr.Error(), "cluster1")
+		assert.Contains(t, reconcileErr.Error(), "connection refused")
+	}
+
+	// Federation-side writes have no cluster.
+	federationErr := newReconcileError("myns/myingress", "", ReconcilePhaseUpdate, cause)
+	assert.NotContains(t, federationErr.Error(), "in cluster")
+
+	assert.Equal(t, ReconcilePhaseCreate, phaseForOperation(util.OperationTypeAdd))
+	assert.Equal(t, ReconcilePhaseUpdate, phaseForOperation(util.OperationTypeUpdate))
+	assert.Equal(t, ReconcilePhaseDelete, phaseForOperation(util.OperationTypeDelete))
+}
+
+// Checks that a failed create in a member cluster surfaces through LastError
+// as a ReconcileError with the ingress key, the cluster and the create phase.
+func TestReconcileErrorOnFailedCreate(t *testing.T) {
+	cluster1 := NewCluster("cluster1", apiv1.ConditionTrue)
+	fedClient := &fakefedclientset.Clientset{}
+	RegisterFakeList("clusters", &fedClient.Fake, &federationapi.ClusterList{Items: []federationapi.Cluster{*cluster1}})
+	RegisterFakeList("ingresses", &fedClient.Fake, &extensionsv1beta1.IngressList{Items: []extensionsv1beta1.Ingress{}})
+	fedIngressWatch := RegisterFakeWatch("ingresses", &fedClient.Fake)
+	RegisterFakeWatch("clusters", &fedClient.Fake)
+	RegisterFakeCopyOnUpdate("ingresses", &fedClient.Fake, fedIngressWatch)
+
+	clusterClient := &fakekubeclientset.Clientset{}
+	RegisterFakeList("ingresses", &clusterClient.Fake, &extensionsv1beta1.IngressList{Items: []extensionsv1beta1.Ingress{}})
+	RegisterFakeList("configmaps", &clusterClient.Fake, &apiv1.ConfigMapList{Items: []apiv1.ConfigMap{}})
+	RegisterFakeWatch("ingresses", &clusterClient.Fake)
+	RegisterFakeWatch("configmaps", &clusterClient.Fake)
+	clusterClient.Fake.PrependReactor("create", "ingresses", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("admission denied")
+	})
+
+	ingressController := NewIngressControllerWithOptions(IngressControllerOptions{
+		FedClient: fedClient,
+		ClientFactory: func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+			return clusterClient, nil
+		},
+		Config: &IngressControllerConfig{DisableUIDReconciliation: true},
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	ingressController.Run(stop)
+
+	fedIngressWatch.Add(&extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "test-ingress",
+			Namespace:   "mynamespace",
+			SelfLink:    "/api/v1/namespaces/mynamespace/ingress/test-ingress",
+			Annotations: map[string]string{firstClusterAnnotation: cluster1.Name},
+		},
+	})
+
+	err := wait.PollImmediate(50*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return ingressController.LastError() != nil, nil
+	})
+	if !assert.NoError(t, err, "a reconcile error should have been recorded") {
+		return
+	}
+	reconcileErr, ok := ingressController.LastError().(*ReconcileError)
+	if !assert.True(t, ok, "LastError should return a *ReconcileError") {
+		return
+	}
+	assert.Equal(t, "mynamespace/test-ingress", reconcileErr.Key)
+	assert.Equal(t, "cluster1", reconcileErr.Cluster)
+	assert.Equal(t, ReconcilePhaseCreate, reconcileErr.Phase)
+	assert.Contains(t, reconcileErr.Unwrap().Error(), "admission denied")
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Result labels recorded in the reconcile replay log.
+const (
+	// The reconciliation was postponed and redelivered, e.g. because an
+	// informer had not synced or a cluster asked for a retry later.
+	reconcileResultDeferred = "deferred"
+	// The key does not belong to a federated ingress.
+	reconcileResultNotFederated = "not-federated"
+	// The ingress does not carry the required ownership annotation and is
+	// ignored entirely.
+	reconcileResultUnowned = "unowned"
+	// The federated ingress is being deleted and the deletion was processed.
+	reconcileResultDeleted = "deleted"
+	// The reconciliation failed; the Error field holds the cause.
+	reconcileResultError = "error"
+	// All clusters already matched the desired state.
+	reconcileResultConverged = "converged"
+	// Operations were executed against at least one cluster.
+	reconcileResultPropagated = "propagated"
+)
+
+// The number of reconcile decisions kept in the replay log unless overridden
+// through IngressControllerConfig.ReconcileLogSize.
+const defaultReconcileLogSize = 20
+
+// ReconcileRecord captures the outcome of a single reconciliation for
+// post-mortem analysis via RecentReconciles.
+type ReconcileRecord struct {
+	// Namespace/name of the federated ingress.
+	Key string
+	// When the reconciliation started.
+	Timestamp time.Time
+	// One of the reconcileResult* labels above.
+	Result string
+	// Cluster writes decided by this reconciliation, as "<cluster>:<action>"
+	// in the order they were recorded. Empty for reconciliations that did
+	// not touch any cluster.
+	Actions []string
+	// Text of the error for reconcileResultError, empty otherwise.
+	Error string
+}
+
+// reconcileLog is a fixed-size ring buffer of the most recent reconcile
+// decisions. It gives a "what just happened" window for debugging
+// intermittent issues without enabling verbose logging everywhere.
+type reconcileLog struct {
+	sync.Mutex
+	records []ReconcileRecord
+	next    int
+	full    bool
+}
+
+func newReconcileLog(size int) *reconcileLog {
+	return &reconcileLog{records: make([]ReconcileRecord, size)}
+}
+
+// add appends the record, evicting the oldest one once the buffer is full.
+func (l *reconcileLog) add(record ReconcileRecord) {
+	l.Lock()
+	defer l.Unlock()
+	if len(l.records) == 0 {
+		return
+	}
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// snapshot returns a copy of the buffered records, oldest first.
+func (l *reconcileLog) snapshot() []ReconcileRecord {
+	l.Lock()
+	defer l.Unlock()
+	result := make([]ReconcileRecord, 0, len(l.records))
+	if l.full {
+		result = append(result, l.records[l.next:]...)
+	}
+	result = append(result, l.records[:l.next]...)
+	return result
+}
+
+// ReconcileCostEstimate summarizes the cluster API call cost of the
+// reconciliations currently held in the replay log. It is meant to help
+// operators size the controller's rate limits for a given ingress set.
+type ReconcileCostEstimate struct {
+	// Number of reconciliations the estimate is based on.
+	Reconciles int
+	// Average number of cluster API calls per reconciliation. Noop
+	// decisions cost no API call and are not counted.
+	CallsPerReconcile float64
+	// Average number of cluster API calls per reconciliation, broken down
+	// by federated ingress key.
+	CallsPerIngress map[string]float64
+}
+
+// costEstimate derives the API call averages from the buffered records.
+func (l *reconcileLog) costEstimate() ReconcileCostEstimate {
+	estimate := ReconcileCostEstimate{CallsPerIngress: map[string]float64{}}
+	reconcilesPerIngress := map[string]int{}
+	totalCalls := 0
+	for _, record := range l.snapshot() {
+		calls := 0
+		for _, action := range record.Actions {
+			if !strings.HasSuffix(action, ":"+actionNoop) {
+				calls++
+			}
+		}
+		estimate.Reconciles++
+		totalCalls += calls
+		reconcilesPerIngress[record.Key]++
+		estimate.CallsPerIngress[record.Key] += float64(calls)
+	}
+	for key, reconciles := range reconcilesPerIngress {
+		estimate.CallsPerIngress[key] /= float64(reconciles)
+	}
+	if estimate.Reconciles > 0 {
+		estimate.CallsPerReconcile = float64(totalCalls) / float64(estimate.Reconciles)
+	}
+	return estimate
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"sync"
+
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/types"
+
+	"github.com/golang/glog"
+)
+
+// Annotation on a federated ingress hinting how urgently it should be
+// reconciled relative to other ingresses when many are queued. One of
+// "high", "normal" (the default) or "low".
+const reconcilePriorityAnnotation = "federation.kubernetes.io/reconcile-priority"
+
+// Reconcile priorities, in processing order. They index the queues of a
+// reconcileBuffer.
+const (
+	reconcilePriorityHigh = iota
+	reconcilePriorityNormal
+	reconcilePriorityLow
+	reconcilePriorityCount
+)
+
+// Values of the reconcile-priority annotation.
+const (
+	reconcilePriorityHighValue   = "high"
+	reconcilePriorityNormalValue = "normal"
+	reconcilePriorityLowValue    = "low"
+)
+
+// reconcilePriorityOf maps the reconcile-priority annotation of the given
+// ingress to a priority, defaulting to normal when the annotation is absent
+// or carries an unknown value.
+func reconcilePriorityOf(baseIngress *extensionsv1beta1.Ingress) int {
+	value, found := baseIngress.ObjectMeta.Annotations[reconcilePriorityAnnotation]
+	if !found {
+		return reconcilePriorityNormal
+	}
+	switch value {
+	case reconcilePriorityHighValue:
+		return reconcilePriorityHigh
+	case reconcilePriorityNormalValue:
+		return reconcilePriorityNormal
+	case reconcilePriorityLowValue:
+		return reconcilePriorityLow
+	}
+	glog.V(2).Infof("Unknown %s annotation value %q on ingress %s/%s, treating as %s", reconcilePriorityAnnotation, value, baseIngress.Namespace, baseIngress.Name, reconcilePriorityNormalValue)
+	return reconcilePriorityNormal
+}
+
+// reconcilePriorityFor looks up the delivered ingress in the federated
+// informer cache to read its reconcile-priority annotation. Keys that are not
+// in the cache (e.g. a deleted ingress) reconcile at normal priority.
+func (ic *IngressController) reconcilePriorityFor(ingress types.NamespacedName) int {
+	obj, exists, err := ic.ingressInformerStore.GetByKey(ingress.String())
+	if err != nil || !exists {
+		return reconcilePriorityNormal
+	}
+	baseIngress, ok := obj.(*extensionsv1beta1.Ingress)
+	if !ok {
+		return reconcilePriorityNormal
+	}
+	return reconcilePriorityOf(baseIngress)
+}
+
+// reconcileBuffer sits between the delaying deliverer and the reconciliation
+// worker and hands out queued ingresses in priority order: all queued
+// high-priority ingresses are processed before normal ones, which are
+// processed before low-priority ones. Within a priority, order of arrival is
+// preserved. A key already queued is not queued again; a priority change
+// while queued takes effect on the next delivery.
+type reconcileBuffer struct {
+	sync.Mutex
+	cond    *sync.Cond
+	queues  [reconcilePriorityCount][]types.NamespacedName
+	queued  map[string]bool
+	stopped bool
+}
+
+func newReconcileBuffer() *reconcileBuffer {
+	buffer := &reconcileBuffer{queued: make(map[string]bool)}
+	buffer.cond = sync.NewCond(buffer)
+	return buffer
+}
+
+// add queues the ingress at the given priority, unless it is already queued.
+func (b *reconcileBuffer) add(priority int, ingress types.NamespacedName) {
+	b.Lock()
+	defer b.Unlock()
+	key := ingress.String()
+	if b.queued[key] || b.stopped {
+		return
+	}
+	b.queued[key] = true
+	b.queues[priority] = append(b.queues[priority], ingress)
+	b.cond.Signal()
+}
+
+// pop blocks until an ingress is queued (or the buffer is stopped, in which
+// case it returns false) and returns the queued ingress with the highest
+// priority.
+func (b *reconcileBuffer) pop() (types.NamespacedName, bool) {
+	b.Lock()
+	defer b.Unlock()
+	for {
+		for priority := range b.queues {
+			if len(b.queues[priority]) == 0 {
+				continue
+			}
+			ingress := b.queues[priority][0]
+			b.queues[priority] = b.queues[priority][1:]
+			delete(b.queued, ingress.String())
+			return ingress, true
+		}
+		if b.stopped {
+			return types.NamespacedName{}, false
+		}
+		b.cond.Wait()
+	}
+}
+
+// stop wakes up blocked pop calls; they drain what is queued and then return
+// false.
+func (b *reconcileBuffer) stop() {
+	b.Lock()
+	defer b.Unlock()
+	b.stopped = true
+	b.cond.Broadcast()
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	"k8s.io/kubernetes/pkg/types"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Checks that a high-priority ingress is handed out before a normal one and a
+// normal one before a low one, regardless of the order they were queued in.
+func TestReconcileBufferPriorityOrdering(t *testing.T) {
+	buffer := newReconcileBuffer()
+	high := types.NamespacedName{Namespace: "ns", Name: "critical"}
+	normal := types.NamespacedName{Namespace: "ns", Name: "ordinary"}
+	low := types.NamespacedName{Namespace: "ns", Name: "batch"}
+
+	buffer.add(reconcilePriorityNormal, normal)
+	buffer.add(reconcilePriorityLow, low)
+	buffer.add(reconcilePriorityHigh, high)
+
+	for _, expected := range []types.NamespacedName{high, normal, low} {
+		ingress, ok := buffer.pop()
+		assert.True(t, ok)
+		assert.Equal(t, expected, ingress)
+	}
+
+	// A key already queued is not queued twice.
+	buffer.add(reconcilePriorityNormal, normal)
+	buffer.add(reconcilePriorityNormal, normal)
+	ingress, ok := buffer.pop()
+	assert.True(t, ok)
+	assert.Equal(t, normal, ingress)
+
+	// After stop, what is queued drains and then pop reports done.
+	buffer.add(reconcilePriorityLow, low)
+	buffer.stop()
+	ingress, ok = buffer.pop()
+	assert.True(t, ok)
+	assert.Equal(t, low, ingress)
+	_, ok = buffer.pop()
+	assert.False(t, ok)
+}
+
+// Checks the mapping from the reconcile-priority annotation to a priority:
+// absent and unknown values default to normal.
+func TestReconcilePriorityOf(t *testing.T) {
+	makeIngress := func(priority string) *extensionsv1beta1.Ingress {
+		ingress := &extensionsv1beta1.Ingress{
+			ObjectMeta: apiv1.ObjectMeta{Name: "test-ingress", Namespace: "ns"},
+		}
+		if priority != "" {
+			ingress.ObjectMeta.Annotations = map[string]string{reconcilePriorityAnnotation: priority}
+		}
+		return ingress
+	}
+	assert.Equal(t, reconcilePriorityNormal, reconcilePriorityOf(makeIngress("")))
+	assert.Equal(t, reconcilePriorityHigh, reconcilePriorityOf(makeIngress(reconcilePriorityHighValue)))
+	assert.Equal(t, reconcilePriorityNormal, reconcilePriorityOf(makeIngress(reconcilePriorityNormalValue)))
+	assert.Equal(t, reconcilePriorityLow, reconcilePriorityOf(makeIngress(reconcilePriorityLowValue)))
+	assert.Equal(t, reconcilePriorityNormal, reconcilePriorityOf(makeIngress("urgent")))
+}
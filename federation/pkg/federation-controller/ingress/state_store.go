@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"fmt"
+	"time"
+)
+
+// ControllerState captures the auxiliary in-memory reconcile state worth
+// carrying across a restart or leader failover: retry backoffs, the last
+// reconcile error and quarantined (circuit-open) clusters. Nothing in it is
+// authoritative - the informers rebuild the actual object state - so losing
+// a snapshot only costs re-learning cluster health the hard way.
+type ControllerState struct {
+	// LastError is the text of the most recent failed reconciliation's
+	// error, or "" after a clean pass.
+	LastError string `json:"lastError,omitempty"`
+	// ClusterStatuses records the result of the most recent write per
+	// cluster: "ok: <action>" or the error text.
+	ClusterStatuses map[string]string `json:"clusterStatuses,omitempty"`
+	// OpenCircuitClusters lists clusters whose circuit breaker was open.
+	OpenCircuitClusters []string `json:"openCircuitClusters,omitempty"`
+	// BackoffKeys lists federated ingresses that were in retry backoff.
+	BackoffKeys []string `json:"backoffKeys,omitempty"`
+}
+
+// StateStore persists ControllerState outside the controller process, for
+// example in a ConfigMap or the federation API, so that a restarted or newly
+// elected leader resumes without a cold start. Load is called once when the
+// controller starts, Save periodically afterwards; both are best effort and
+// errors are only logged. Load must return an empty state, not an error,
+// when nothing has been saved yet.
+type StateStore interface {
+	Save(state *ControllerState) error
+	Load() (*ControllerState, error)
+}
+
+// snapshotState captures the current auxiliary state for the state store.
+func (ic *IngressController) snapshotState() *ControllerState {
+	state := &ControllerState{
+		ClusterStatuses:     ic.ClusterStatuses(),
+		OpenCircuitClusters: ic.clusterCircuitBreaker.OpenClusters(),
+	}
+	if err := ic.LastError(); err != nil {
+		state.LastError = err.Error()
+	}
+	ic.statusLock.Lock()
+	state.BackoffKeys = ic.backoffKeys.List()
+	ic.statusLock.Unlock()
+	return state
+}
+
+// restoreState re-arms the in-memory state from a previously saved snapshot.
+// Restored backoffs start over at the initial backoff duration: the precise
+// durations are not worth persisting, only which ingresses were failing.
+func (ic *IngressController) restoreState(state *ControllerState) {
+	if state == nil {
+		return
+	}
+	if state.LastError != "" {
+		ic.setLastError(fmt.Errorf("%s (restored from the state store)", state.LastError))
+	}
+	for clusterName, status := range state.ClusterStatuses {
+		ic.setClusterStatus(clusterName, status)
+	}
+	for _, clusterName := range state.OpenCircuitClusters {
+		ic.clusterCircuitBreaker.ForceOpen(clusterName)
+	}
+	now := time.Now()
+	for _, key := range state.BackoffKeys {
+		ic.ingressBackoff.Next(key, now)
+		ic.trackBackoffKey(key, true)
+	}
+}
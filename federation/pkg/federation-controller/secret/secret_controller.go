@@ -28,6 +28,7 @@ import (
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/client/cache"
 	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	"k8s.io/kubernetes/pkg/client/record"
@@ -42,6 +43,19 @@ import (
 
 const (
 	allClustersKey = "ALL_CLUSTERS"
+
+	// Clusters carrying this annotation with the value "true" must never have
+	// their Secret payloads cached in the federation control plane. No secret
+	// informer is run against such clusters; presence and version of their
+	// secret copies are verified with direct GETs during reconciliation, and
+	// secret data is kept out of logs and events.
+	sensitiveClusterAnnotation = "federation.alpha.kubernetes.io/sensitive"
+
+	// Annotation stamped on secret copies written to sensitive clusters,
+	// recording the resourceVersion of the federated secret the copy was
+	// written from. Convergence is judged from this stamp alone, so the data
+	// of the cluster copy never needs to be read back or compared.
+	sensitiveClusterVersionAnnotation = "federation.alpha.kubernetes.io/federated-secret-version"
 )
 
 type SecretController struct {
@@ -118,6 +132,25 @@ func NewSecretController(client federationclientset.Interface) *SecretController
 	secretcontroller.secretFederatedInformer = util.NewFederatedInformer(
 		client,
 		func(cluster *federationapi.Cluster, targetClient kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+			if isSensitiveCluster(cluster) {
+				// Never open a secret list or watch against a sensitive
+				// cluster. The informer runs over a local empty source so
+				// that the target store still reports the cluster as synced,
+				// and reconciliation verifies the cluster's copies with
+				// direct GETs instead.
+				return cache.NewInformer(
+					&cache.ListWatch{
+						ListFunc: func(options apiv1.ListOptions) (pkgruntime.Object, error) {
+							return &apiv1.SecretList{}, nil
+						},
+						WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+							return watch.NewFake(), nil
+						},
+					},
+					&apiv1.Secret{},
+					controller.NoResyncPeriodFunc(),
+					&cache.ResourceEventHandlerFuncs{})
+			}
 			return cache.NewInformer(
 				&cache.ListWatch{
 					ListFunc: func(options apiv1.ListOptions) (pkgruntime.Object, error) {
@@ -354,6 +387,19 @@ func (secretcontroller *SecretController) reconcileSecret(secret types.Namespace
 
 	operations := make([]util.FederatedOperation, 0)
 	for _, cluster := range clusters {
+		if isSensitiveCluster(cluster) {
+			operation, err := secretcontroller.sensitiveClusterOperation(baseSecret, cluster)
+			if err != nil {
+				glog.Errorf("Failed to check %s in sensitive cluster %s: %v", key, cluster.Name, err)
+				secretcontroller.deliverSecret(secret, 0, true)
+				return
+			}
+			if operation != nil {
+				operations = append(operations, *operation)
+			}
+			continue
+		}
+
 		clusterSecretObj, found, err := secretcontroller.secretFederatedInformer.GetTargetStore().GetByKey(cluster.Name, key)
 		if err != nil {
 			glog.Errorf("Failed to get %s from %s: %v", key, cluster.Name, err)
@@ -414,9 +460,64 @@ func (secretcontroller *SecretController) reconcileSecret(secret types.Namespace
 	secretcontroller.deliverSecret(secret, secretcontroller.secretReviewDelay, false)
 }
 
+// Returns true if secrets of the given cluster must not be cached or diffed
+// by the federation control plane.
+func isSensitiveCluster(cluster *federationapi.Cluster) bool {
+	return cluster.ObjectMeta.Annotations[sensitiveClusterAnnotation] == "true"
+}
+
+// sensitiveClusterOperation decides the operation needed to bring the given
+// sensitive cluster in sync with the federated secret, without a cached copy
+// to compare against. The cluster's copy is fetched directly, only its
+// metadata is inspected, and convergence is judged from the resource version
+// stamped on the last write. Returns nil when the cluster is already in sync.
+func (secretcontroller *SecretController) sensitiveClusterOperation(baseSecret *apiv1.Secret, cluster *federationapi.Cluster) (*util.FederatedOperation, error) {
+	clusterClient, err := secretcontroller.secretFederatedInformer.GetClientsetForCluster(cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// The data should not be modified.
+	desiredSecret := &apiv1.Secret{
+		ObjectMeta: util.DeepCopyRelevantObjectMeta(baseSecret.ObjectMeta),
+		Data:       baseSecret.Data,
+		Type:       baseSecret.Type,
+	}
+	if desiredSecret.ObjectMeta.Annotations == nil {
+		desiredSecret.ObjectMeta.Annotations = make(map[string]string)
+	}
+	desiredSecret.ObjectMeta.Annotations[sensitiveClusterVersionAnnotation] = baseSecret.ResourceVersion
+
+	clusterSecret, err := clusterClient.Core().Secrets(baseSecret.Namespace).Get(baseSecret.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		secretcontroller.eventRecorder.Eventf(baseSecret, api.EventTypeNormal, "CreateInCluster",
+			"Creating secret in cluster %s", cluster.Name)
+		return &util.FederatedOperation{
+			Type:        util.OperationTypeAdd,
+			Obj:         desiredSecret,
+			ClusterName: cluster.Name,
+		}, nil
+	}
+
+	if clusterSecret.ObjectMeta.Annotations[sensitiveClusterVersionAnnotation] == baseSecret.ResourceVersion {
+		return nil, nil
+	}
+	secretcontroller.eventRecorder.Eventf(baseSecret, api.EventTypeNormal, "UpdateInCluster",
+		"Updating secret in cluster %s", cluster.Name)
+	return &util.FederatedOperation{
+		Type:        util.OperationTypeUpdate,
+		Obj:         desiredSecret,
+		ClusterName: cluster.Name,
+	}, nil
+}
+
 // delete deletes the given secret or returns error if the deletion was not complete.
 func (secretcontroller *SecretController) delete(secret *apiv1.Secret) error {
-	glog.V(3).Infof("Handling deletion of secret: %v", *secret)
+	// Log only the identity of the secret, never its data.
+	glog.V(3).Infof("Handling deletion of secret: %s/%s", secret.Namespace, secret.Name)
 	_, err := secretcontroller.deletionHelper.HandleObjectInUnderlyingClusters(secret)
 	if err != nil {
 		return err
@@ -28,6 +28,7 @@ import (
 	"k8s.io/kubernetes/federation/pkg/federation-controller/util/deletionhelper"
 	. "k8s.io/kubernetes/federation/pkg/federation-controller/util/test"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
 	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
 	"k8s.io/kubernetes/pkg/runtime"
@@ -169,6 +170,107 @@ func TestSecretController(t *testing.T) {
 	close(stop)
 }
 
+func TestSecretControllerSensitiveCluster(t *testing.T) {
+	cluster1 := NewCluster("cluster1", apiv1.ConditionTrue)
+	cluster2 := NewCluster("cluster2", apiv1.ConditionTrue)
+	cluster2.ObjectMeta.Annotations = map[string]string{sensitiveClusterAnnotation: "true"}
+
+	fakeClient := &fakefedclientset.Clientset{}
+	RegisterFakeList("clusters", &fakeClient.Fake, &federationapi.ClusterList{Items: []federationapi.Cluster{*cluster1, *cluster2}})
+	RegisterFakeList("secrets", &fakeClient.Fake, &apiv1.SecretList{Items: []apiv1.Secret{}})
+	secretWatch := RegisterFakeWatch("secrets", &fakeClient.Fake)
+	secretUpdateChan := RegisterFakeCopyOnUpdate("secrets", &fakeClient.Fake, secretWatch)
+	RegisterFakeWatch("clusters", &fakeClient.Fake)
+
+	cluster1Client := &fakekubeclientset.Clientset{}
+	cluster1Watch := RegisterFakeWatch("secrets", &cluster1Client.Fake)
+	RegisterFakeList("secrets", &cluster1Client.Fake, &apiv1.SecretList{Items: []apiv1.Secret{}})
+	cluster1CreateChan := RegisterFakeCopyOnCreate("secrets", &cluster1Client.Fake, cluster1Watch)
+
+	// The sensitive cluster is backed by an object tracker, so direct GETs
+	// and writes behave like a real API server while every action taken
+	// against the cluster stays recorded for the assertions below.
+	cluster2Client := fakekubeclientset.NewSimpleClientset()
+
+	secretController := NewSecretController(fakeClient)
+	informerClientFactory := func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+		switch cluster.Name {
+		case cluster1.Name:
+			return cluster1Client, nil
+		case cluster2.Name:
+			return cluster2Client, nil
+		default:
+			return nil, fmt.Errorf("Unknown cluster")
+		}
+	}
+	setClientFactory(secretController.secretFederatedInformer, informerClientFactory)
+
+	secretController.clusterAvailableDelay = time.Second
+	secretController.secretReviewDelay = 50 * time.Millisecond
+	secretController.smallDelay = 20 * time.Millisecond
+	secretController.updateTimeout = 5 * time.Second
+
+	stop := make(chan struct{})
+	defer close(stop)
+	secretController.Run(stop)
+
+	secret1 := apiv1.Secret{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:            "test-secret",
+			Namespace:       "ns",
+			SelfLink:        "/api/v1/namespaces/ns/secrets/test-secret",
+			ResourceVersion: "1",
+		},
+		Data: map[string][]byte{
+			"A": []byte("ala ma kota"),
+		},
+		Type: apiv1.SecretTypeOpaque,
+	}
+
+	secretWatch.Add(&secret1)
+	// There should be 2 updates to add both the finalizers.
+	updatedSecret := GetSecretFromChan(secretUpdateChan)
+	assert.True(t, secretController.hasFinalizerFunc(updatedSecret, deletionhelper.FinalizerDeleteFromUnderlyingClusters))
+	updatedSecret = GetSecretFromChan(secretUpdateChan)
+	assert.True(t, secretController.hasFinalizerFunc(updatedSecret, apiv1.FinalizerOrphan))
+	secret1 = *updatedSecret
+
+	// The regular cluster gets its copy through the cached informer path.
+	createdSecret := GetSecretFromChan(cluster1CreateChan)
+	assert.NotNil(t, createdSecret)
+
+	// The sensitive cluster converges too, with the version of the federated
+	// secret stamped on its copy.
+	waitForSensitiveCopy := func(resourceVersion string, data map[string][]byte) {
+		err := wait.PollImmediate(50*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			clusterSecret, err := cluster2Client.Core().Secrets(secret1.Namespace).Get(secret1.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, nil
+			}
+			return clusterSecret.Annotations[sensitiveClusterVersionAnnotation] == resourceVersion &&
+				reflect.DeepEqual(clusterSecret.Data, data), nil
+		})
+		assert.NoError(t, err, "secret should have converged in the sensitive cluster")
+	}
+	waitForSensitiveCopy("1", secret1.Data)
+
+	// An update of the federated secret reaches the sensitive cluster as well.
+	secret1.ResourceVersion = "2"
+	secret1.Data = map[string][]byte{
+		"A": []byte("quick brown fox"),
+	}
+	secretWatch.Modify(&secret1)
+	waitForSensitiveCopy("2", secret1.Data)
+
+	// The compliance property: no secret list or watch was ever opened
+	// against the sensitive cluster, only direct GETs and writes.
+	for _, action := range cluster2Client.Actions() {
+		if action.Matches("list", "secrets") || action.Matches("watch", "secrets") {
+			t.Errorf("%s opened on secrets of the sensitive cluster", action.GetVerb())
+		}
+	}
+}
+
 func setClientFactory(informer util.FederatedInformer, informerClientFactory func(*federationapi.Cluster) (kubeclientset.Interface, error)) {
 	testInformer := ToFederatedInformerForTestOnly(informer)
 	testInformer.SetClientFactory(informerClientFactory)
@@ -0,0 +1,214 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrClusterCircuitOpen is returned instead of executing an operation when
+// the target cluster's circuit breaker is open. Controllers treat it like a
+// cluster that is unavailable: the operation is not attempted and no timeout
+// is consumed waiting for it.
+var ErrClusterCircuitOpen = errors.New("cluster circuit breaker is open")
+
+// States of a cluster's circuit, as reported by CircuitBreaker.State.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitBreaker tracks consecutive request failures per cluster. After
+// failureThreshold consecutive failures the cluster's circuit opens and
+// requests to it fail fast with ErrClusterCircuitOpen for the cooling period.
+// Afterwards a single probe request is let through (half-open): its success
+// closes the circuit, its failure reopens it for another cooling period.
+//
+// A nil *CircuitBreaker is valid and never opens.
+type CircuitBreaker struct {
+	failureThreshold int
+	coolingPeriod    time.Duration
+	// now is replaceable for tests.
+	now func() time.Time
+	// stateChangeHandler, when set, is notified after a cluster's circuit
+	// opens or closes. Called without the lock held.
+	stateChangeHandler func(clusterName, state string)
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	consecutiveFailures int
+	open                bool
+	openedAt            time.Time
+	probing             bool
+}
+
+// NewCircuitBreaker creates a circuit breaker that opens a cluster's circuit
+// after failureThreshold consecutive failed requests and keeps it open for at
+// least coolingPeriod.
+func NewCircuitBreaker(failureThreshold int, coolingPeriod time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		coolingPeriod:    coolingPeriod,
+		now:              time.Now,
+		circuits:         make(map[string]*circuit),
+	}
+}
+
+// SetStateChangeHandler registers a function notified whenever a cluster's
+// circuit opens or closes, e.g. to export the state as a metric.
+func (cb *CircuitBreaker) SetStateChangeHandler(handler func(clusterName, state string)) {
+	if cb == nil {
+		return
+	}
+	cb.stateChangeHandler = handler
+}
+
+// Allow returns nil if a request to the given cluster may proceed and
+// ErrClusterCircuitOpen if its circuit is open. Once the cooling period has
+// passed, one request at a time is allowed through as a probe.
+func (cb *CircuitBreaker) Allow(clusterName string) error {
+	if cb == nil {
+		return nil
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	c := cb.circuits[clusterName]
+	if c == nil || !c.open {
+		return nil
+	}
+	if cb.now().Sub(c.openedAt) < cb.coolingPeriod || c.probing {
+		return ErrClusterCircuitOpen
+	}
+	c.probing = true
+	return nil
+}
+
+// RecordSuccess resets the cluster's failure count and closes its circuit.
+func (cb *CircuitBreaker) RecordSuccess(clusterName string) {
+	if cb == nil {
+		return
+	}
+	cb.lock.Lock()
+	c := cb.circuits[clusterName]
+	wasOpen := c != nil && c.open
+	delete(cb.circuits, clusterName)
+	cb.lock.Unlock()
+	if wasOpen && cb.stateChangeHandler != nil {
+		cb.stateChangeHandler(clusterName, CircuitClosed)
+	}
+}
+
+// RecordFailure counts a failed request against the cluster, opening its
+// circuit at the failure threshold and reopening it after a failed probe.
+func (cb *CircuitBreaker) RecordFailure(clusterName string) {
+	if cb == nil {
+		return
+	}
+	cb.lock.Lock()
+	c := cb.circuits[clusterName]
+	if c == nil {
+		c = &circuit{}
+		cb.circuits[clusterName] = c
+	}
+	c.consecutiveFailures++
+	opened := false
+	if c.open {
+		if c.probing {
+			// The probe failed: another full cooling period.
+			c.openedAt = cb.now()
+			c.probing = false
+		}
+	} else if c.consecutiveFailures >= cb.failureThreshold {
+		c.open = true
+		c.openedAt = cb.now()
+		opened = true
+	}
+	cb.lock.Unlock()
+	if opened && cb.stateChangeHandler != nil {
+		cb.stateChangeHandler(clusterName, CircuitOpen)
+	}
+}
+
+// OpenClusters returns the names of the clusters whose circuits are
+// currently open (including half-open), sorted for deterministic output.
+// Intended for persisting the quarantine state across controller restarts.
+func (cb *CircuitBreaker) OpenClusters() []string {
+	if cb == nil {
+		return nil
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	result := []string{}
+	for clusterName, c := range cb.circuits {
+		if c.open {
+			result = append(result, clusterName)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ForceOpen opens the cluster's circuit as if the failure threshold had just
+// been crossed. Used to restore persisted quarantine state after a restart
+// or leader failover. No-op if the circuit is already open.
+func (cb *CircuitBreaker) ForceOpen(clusterName string) {
+	if cb == nil {
+		return
+	}
+	cb.lock.Lock()
+	c := cb.circuits[clusterName]
+	if c == nil {
+		c = &circuit{}
+		cb.circuits[clusterName] = c
+	}
+	alreadyOpen := c.open
+	if !alreadyOpen {
+		c.consecutiveFailures = cb.failureThreshold
+		c.open = true
+		c.openedAt = cb.now()
+		c.probing = false
+	}
+	cb.lock.Unlock()
+	if !alreadyOpen && cb.stateChangeHandler != nil {
+		cb.stateChangeHandler(clusterName, CircuitOpen)
+	}
+}
+
+// State returns the current state of the cluster's circuit: CircuitClosed,
+// CircuitOpen or CircuitHalfOpen.
+func (cb *CircuitBreaker) State(clusterName string) string {
+	if cb == nil {
+		return CircuitClosed
+	}
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	c := cb.circuits[clusterName]
+	if c == nil || !c.open {
+		return CircuitClosed
+	}
+	if cb.now().Sub(c.openedAt) >= cb.coolingPeriod {
+		return CircuitHalfOpen
+	}
+	return CircuitOpen
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerStateMachine(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(3, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	// Below the threshold the circuit stays closed.
+	assert.NoError(t, breaker.Allow("cluster1"))
+	breaker.RecordFailure("cluster1")
+	breaker.RecordFailure("cluster1")
+	assert.Equal(t, CircuitClosed, breaker.State("cluster1"))
+	assert.NoError(t, breaker.Allow("cluster1"))
+
+	// The threshold failure opens the circuit; requests fail fast.
+	breaker.RecordFailure("cluster1")
+	assert.Equal(t, CircuitOpen, breaker.State("cluster1"))
+	assert.Equal(t, ErrClusterCircuitOpen, breaker.Allow("cluster1"))
+
+	// Other clusters are unaffected.
+	assert.NoError(t, breaker.Allow("cluster2"))
+
+	// After the cooling period a single probe is allowed through.
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, CircuitHalfOpen, breaker.State("cluster1"))
+	assert.NoError(t, breaker.Allow("cluster1"))
+	assert.Equal(t, ErrClusterCircuitOpen, breaker.Allow("cluster1"), "only one probe at a time")
+
+	// A failed probe reopens the circuit for another cooling period.
+	breaker.RecordFailure("cluster1")
+	assert.Equal(t, CircuitOpen, breaker.State("cluster1"))
+	assert.Equal(t, ErrClusterCircuitOpen, breaker.Allow("cluster1"))
+
+	// A successful probe closes it again.
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, breaker.Allow("cluster1"))
+	breaker.RecordSuccess("cluster1")
+	assert.Equal(t, CircuitClosed, breaker.State("cluster1"))
+	assert.NoError(t, breaker.Allow("cluster1"))
+
+	// Intermittent failures never open the circuit: a success resets the
+	// consecutive failure count.
+	breaker.RecordFailure("cluster1")
+	breaker.RecordFailure("cluster1")
+	breaker.RecordSuccess("cluster1")
+	breaker.RecordFailure("cluster1")
+	breaker.RecordFailure("cluster1")
+	assert.Equal(t, CircuitClosed, breaker.State("cluster1"))
+
+	// A nil breaker never opens.
+	var disabled *CircuitBreaker
+	assert.NoError(t, disabled.Allow("cluster1"))
+	disabled.RecordFailure("cluster1")
+	assert.Equal(t, CircuitClosed, disabled.State("cluster1"))
+}
+
+func TestCircuitBreakerStateChangeHandler(t *testing.T) {
+	transitions := []string{}
+	breaker := NewCircuitBreaker(1, 0)
+	breaker.SetStateChangeHandler(func(clusterName, state string) {
+		transitions = append(transitions, clusterName+"="+state)
+	})
+	breaker.RecordFailure("cluster1")
+	breaker.RecordSuccess("cluster1")
+	assert.Equal(t, []string{"cluster1=" + CircuitOpen, "cluster1=" + CircuitClosed}, transitions)
+}
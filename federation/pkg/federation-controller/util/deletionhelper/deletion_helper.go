@@ -88,6 +88,19 @@ func NewDeletionHelper(
 // the corresponding resources in underlying clusters.
 // This method should be called before creating objects in underlying clusters.
 func (dh *DeletionHelper) EnsureFinalizers(obj runtime.Object) (
+	runtime.Object, error) {
+	obj, err := dh.EnsureDeleteFinalizer(obj)
+	if err != nil {
+		return obj, err
+	}
+	return dh.EnsureOrphanFinalizer(obj)
+}
+
+// Ensures that the given object has the FinalizerDeleteFromUnderlyingClusters
+// finalizer. Controllers that defer this finalizer until the object has
+// actually been created in at least one underlying cluster call this
+// separately from EnsureOrphanFinalizer.
+func (dh *DeletionHelper) EnsureDeleteFinalizer(obj runtime.Object) (
 	runtime.Object, error) {
 	if !dh.hasFinalizerFunc(obj, FinalizerDeleteFromUnderlyingClusters) {
 		glog.V(2).Infof("Adding finalizer %s to %s", FinalizerDeleteFromUnderlyingClusters, dh.objNameFunc(obj))
@@ -96,6 +109,14 @@ func (dh *DeletionHelper) EnsureFinalizers(obj runtime.Object) (
 			return obj, err
 		}
 	}
+	return obj, nil
+}
+
+// Ensures that the given object has the FinalizerOrphan finalizer. Safe to
+// add before any cluster write: with no delete finalizer present the object
+// stays deletable whether or not orphaning is requested.
+func (dh *DeletionHelper) EnsureOrphanFinalizer(obj runtime.Object) (
+	runtime.Object, error) {
 	if !dh.hasFinalizerFunc(obj, api_v1.FinalizerOrphan) {
 		glog.V(2).Infof("Adding finalizer %s to %s", api_v1.FinalizerOrphan, dh.objNameFunc(obj))
 		obj, err := dh.addFinalizerFunc(obj, api_v1.FinalizerOrphan)
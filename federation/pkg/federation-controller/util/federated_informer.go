@@ -24,10 +24,12 @@ import (
 
 	federationapi "k8s.io/kubernetes/federation/apis/federation/v1beta1"
 	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
+	"k8s.io/kubernetes/pkg/api/meta"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
 	"k8s.io/kubernetes/pkg/client/cache"
 	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/labels"
 	pkgruntime "k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/watch"
 
@@ -37,8 +39,44 @@ import (
 const (
 	clusterSyncPeriod = 10 * time.Minute
 	userAgentName     = "federation-controller"
+
+	// NamespaceIndex is the name of the default index over object namespace.
+	NamespaceIndex = cache.NamespaceIndex
+	// ManagedByLabelIndex is the name of the default index over the value of
+	// the ManagedByLabel label. Objects without the label are not indexed.
+	ManagedByLabelIndex = "managed-by-label"
+	// ManagedByLabel marks objects in member clusters as managed by a
+	// federation control plane.
+	ManagedByLabel = "federation.kubernetes.io/managed-by"
+	// AdoptedFromUIDAnnotation on a federated object names the UID of a
+	// previous incarnation of the same object, typically restored by a
+	// disaster-recovery import. Controllers treat member cluster copies whose
+	// managed-by marker carries that UID as owned rather than stale.
+	AdoptedFromUIDAnnotation = "federation.alpha.kubernetes.io/adopted-from-uid"
 )
 
+// defaultFederatedIndexers returns the indexes registered on every federated
+// store: by namespace and by the value of the managed-by label.
+func defaultFederatedIndexers() cache.Indexers {
+	return cache.Indexers{
+		NamespaceIndex:      cache.MetaNamespaceIndexFunc,
+		ManagedByLabelIndex: managedByLabelIndexFunc,
+	}
+}
+
+// managedByLabelIndexFunc indexes objects by the value of the ManagedByLabel
+// label, skipping objects that do not carry the label.
+func managedByLabelIndexFunc(obj interface{}) ([]string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, fmt.Errorf("object has no meta: %v", err)
+	}
+	if value, found := accessor.GetLabels()[ManagedByLabel]; found {
+		return []string{value}, nil
+	}
+	return []string{}, nil
+}
+
 // An object with an origin information.
 type FederatedObject struct {
 	Object      interface{}
@@ -62,6 +100,17 @@ type FederatedReadOnlyStore interface {
 	// Returns the items stored under the given key in all clusters.
 	GetFromAllClusters(key string) ([]FederatedObject, error)
 
+	// AddIndexer registers an additional index, available to ByIndex queries
+	// under the given name. Returns an error if the name is already taken.
+	AddIndexer(indexName string, indexFunc cache.IndexFunc) error
+
+	// ByIndex returns the items from the given cluster whose indexed values
+	// for the named index include the given key.
+	ByIndex(clusterName, indexName, indexKey string) ([]interface{}, error)
+
+	// ByIndexFromAllClusters returns the matching items from all clusters.
+	ByIndexFromAllClusters(indexName, indexKey string) ([]FederatedObject, error)
+
 	// Checks whether stores for all clusters form the lists (and only these) are there and
 	// are synced. This is only a basic check whether the data inside of the store is usable.
 	// It is not a full synchronization/locking mechanism it only tries to ensure that out-of-sync
@@ -69,6 +118,12 @@ type FederatedReadOnlyStore interface {
 	// that there may be significant delays in content updates of all kinds and write their
 	// code that it doesn't break if something is slightly out-of-sync.
 	ClustersSynced(clusters []*federationapi.Cluster) bool
+
+	// ClusterSynced checks whether the store for the single named cluster has
+	// completed its initial list. False for unknown clusters. Lets callers
+	// distinguish "object absent" from "list still in progress" before making
+	// create/delete decisions against one cluster.
+	ClusterSynced(clusterName string) bool
 }
 
 // An interface to access federation members and clients.
@@ -100,6 +155,16 @@ type FederatedInformer interface {
 	// Returns a store created over all stores from target informers.
 	GetTargetStore() FederatedReadOnlyStore
 
+	// LastSyncResourceVersion returns the resource version the target
+	// informer for the given cluster most recently synced to, or "" if the
+	// cluster is unknown or the informer has not listed yet.
+	LastSyncResourceVersion(clusterName string) string
+
+	// LastSyncTime returns when the target informer for the given cluster
+	// was last observed advancing its resource version, or the zero time
+	// for unknown clusters. Useful for judging replication lag.
+	LastSyncTime(clusterName string) time.Time
+
 	// Starts all the processes.
 	Start()
 
@@ -135,8 +200,27 @@ func NewFederatedInformer(
 	federationClient federationclientset.Interface,
 	targetInformerFactory TargetInformerFactory,
 	clusterLifecycle *ClusterLifecycleHandlerFuncs) FederatedInformer {
+	return NewShardedFederatedInformer(federationClient, targetInformerFactory, nil, clusterLifecycle)
+}
+
+// NewShardedFederatedInformer builds a FederatedInformer restricted to the
+// member clusters whose labels match the given selector. With very large
+// federations a single controller-manager instance cannot hold informers
+// for every cluster; several instances can instead split the clusters
+// between them by labeling the Cluster objects and running each instance
+// with a disjoint selector. Clusters outside the selector are invisible to
+// the informer: no clientset is built for them, no target informer is
+// started, and no lifecycle event fires. A cluster relabeled out of the
+// shard is torn down like a deleted cluster; one relabeled into the shard
+// is picked up like a new one. A nil selector matches all clusters.
+func NewShardedFederatedInformer(
+	federationClient federationclientset.Interface,
+	targetInformerFactory TargetInformerFactory,
+	clusterSelector labels.Selector,
+	clusterLifecycle *ClusterLifecycleHandlerFuncs) FederatedInformer {
 
 	federatedInformer := &federatedInformerImpl{
+		clusterSelector:       clusterSelector,
 		targetInformerFactory: targetInformerFactory,
 		clientFactory: func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
 			clusterConfig, err := BuildClusterConfig(cluster)
@@ -146,9 +230,16 @@ func NewFederatedInformer(
 			}
 			return nil, err
 		},
-		targetInformers: make(map[string]informer),
+		targetInformers:  make(map[string]informer),
+		syncObservations: make(map[string]syncObservation),
+		indexers:         defaultFederatedIndexers(),
 	}
 
+	// The selector is also applied in the event handlers below: the server
+	// narrows the list and watch, but fakes in tests and relabeling races
+	// can still deliver clusters outside the shard.
+	inShard := federatedInformer.inShard
+
 	getClusterData := func(name string) []interface{} {
 		data, err := federatedInformer.GetTargetStore().ListFromCluster(name)
 		if err != nil {
@@ -161,9 +252,15 @@ func NewFederatedInformer(
 	federatedInformer.clusterInformer.store, federatedInformer.clusterInformer.controller = cache.NewInformer(
 		&cache.ListWatch{
 			ListFunc: func(options apiv1.ListOptions) (pkgruntime.Object, error) {
+				if clusterSelector != nil {
+					options.LabelSelector = clusterSelector.String()
+				}
 				return federationClient.Federation().Clusters().List(options)
 			},
 			WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+				if clusterSelector != nil {
+					options.LabelSelector = clusterSelector.String()
+				}
 				return federationClient.Federation().Clusters().Watch(options)
 			},
 		},
@@ -172,7 +269,7 @@ func NewFederatedInformer(
 		cache.ResourceEventHandlerFuncs{
 			DeleteFunc: func(old interface{}) {
 				oldCluster, ok := old.(*federationapi.Cluster)
-				if ok {
+				if ok && inShard(oldCluster) {
 					var data []interface{}
 					if clusterLifecycle.ClusterUnavailable != nil {
 						data = getClusterData(oldCluster.Name)
@@ -185,6 +282,10 @@ func NewFederatedInformer(
 			},
 			AddFunc: func(cur interface{}) {
 				curCluster, ok := cur.(*federationapi.Cluster)
+				if ok && !inShard(curCluster) {
+					glog.V(4).Infof("Ignoring cluster %s: outside this instance's shard", curCluster.Name)
+					return
+				}
 				if ok && isClusterReady(curCluster) {
 					federatedInformer.addCluster(curCluster)
 					if clusterLifecycle.ClusterAvailable != nil {
@@ -205,6 +306,34 @@ func NewFederatedInformer(
 					glog.Errorf("Internal error: Cluster %v not updated.  New cluster not of correct type.", cur)
 					return
 				}
+				if !inShard(curCluster) {
+					if inShard(oldCluster) {
+						// Relabeled out of the shard - tear the cluster down
+						// as if it had been deleted.
+						glog.V(2).Infof("Cluster %s was relabeled out of this instance's shard", curCluster.Name)
+						var data []interface{}
+						if clusterLifecycle.ClusterUnavailable != nil {
+							data = getClusterData(oldCluster.Name)
+						}
+						federatedInformer.deleteCluster(oldCluster)
+						if clusterLifecycle.ClusterUnavailable != nil {
+							clusterLifecycle.ClusterUnavailable(oldCluster, data)
+						}
+					}
+					return
+				}
+				if !inShard(oldCluster) {
+					// Relabeled into the shard - pick the cluster up as if it
+					// had just been added.
+					glog.V(2).Infof("Cluster %s was relabeled into this instance's shard", curCluster.Name)
+					if isClusterReady(curCluster) {
+						federatedInformer.addCluster(curCluster)
+						if clusterLifecycle.ClusterAvailable != nil {
+							clusterLifecycle.ClusterAvailable(curCluster)
+						}
+					}
+					return
+				}
 				if isClusterReady(oldCluster) != isClusterReady(curCluster) || !reflect.DeepEqual(oldCluster.Spec, curCluster.Spec) || !reflect.DeepEqual(oldCluster.ObjectMeta.Annotations, curCluster.ObjectMeta.Annotations) {
 					var data []interface{}
 					if clusterLifecycle.ClusterUnavailable != nil {
@@ -231,14 +360,22 @@ func NewFederatedInformer(
 }
 
 func isClusterReady(cluster *federationapi.Cluster) bool {
+	ready := false
 	for _, condition := range cluster.Status.Conditions {
-		if condition.Type == federationapi.ClusterReady {
+		switch condition.Type {
+		case federationapi.ClusterReady:
 			if condition.Status == apiv1.ConditionTrue {
-				return true
+				ready = true
+			}
+		case federationapi.ClusterDuplicate:
+			// Duplicate registrations of the same member cluster are never
+			// treated as ready, so that only one of them is operated on.
+			if condition.Status == apiv1.ConditionTrue {
+				return false
 			}
 		}
 	}
-	return false
+	return ready
 }
 
 type informer struct {
@@ -247,20 +384,49 @@ type informer struct {
 	stopChan   chan struct{}
 }
 
+// resourceVersionGetter is implemented by cache controllers that can report
+// the resource version their reflector last synced to. It is not part of
+// cache.ControllerInterface, so the capability is detected by assertion.
+type resourceVersionGetter interface {
+	LastSyncResourceVersion() string
+}
+
+// syncObservation remembers the resource version a target informer was last
+// seen synced to and when that version was first observed. Kept per cluster
+// to answer "is the controller even seeing this cluster's objects" questions.
+type syncObservation struct {
+	resourceVersion string
+	observedAt      time.Time
+}
+
 type federatedInformerImpl struct {
 	sync.Mutex
 
 	// Informer on federated clusters.
 	clusterInformer informer
 
+	// Restricts the view to the clusters matching this selector. Nil means
+	// all clusters. Immutable after construction, so reading it needs no
+	// lock.
+	clusterSelector labels.Selector
+
 	// Target informers factory
 	targetInformerFactory TargetInformerFactory
 
 	// Structures returned by targetInformerFactory
 	targetInformers map[string]informer
 
+	// Last sync observations, by cluster name. Refreshed lazily whenever
+	// the last sync state of a cluster is queried.
+	syncObservations map[string]syncObservation
+
 	// A function to build clients.
 	clientFactory func(*federationapi.Cluster) (kubeclientset.Interface, error)
+
+	// Index functions available to ByIndex queries over the target stores,
+	// keyed by index name. Since target stores are plain cache.Stores the
+	// indexes are evaluated on demand rather than maintained incrementally.
+	indexers cache.Indexers
 }
 
 // *federatedInformerImpl implements FederatedInformer interface.
@@ -327,7 +493,7 @@ func (f *federatedInformerImpl) GetUnreadyClusters() ([]*federationapi.Cluster,
 	result := make([]*federationapi.Cluster, 0, len(items))
 	for _, item := range items {
 		if cluster, ok := item.(*federationapi.Cluster); ok {
-			if !isClusterReady(cluster) {
+			if !isClusterReady(cluster) && f.inShard(cluster) {
 				result = append(result, cluster)
 			}
 		} else {
@@ -337,6 +503,14 @@ func (f *federatedInformerImpl) GetUnreadyClusters() ([]*federationapi.Cluster,
 	return result, nil
 }
 
+// inShard checks whether the given cluster matches the informer's shard
+// selector. The cluster store can contain clusters outside the shard - the
+// fakes used in tests do not apply the server-side selector - so every read
+// from it is filtered through this. A nil selector matches all clusters.
+func (f *federatedInformerImpl) inShard(cluster *federationapi.Cluster) bool {
+	return f.clusterSelector == nil || f.clusterSelector.Matches(labels.Set(cluster.ObjectMeta.Labels))
+}
+
 // GetReadyClusers returns all clusters for which the sub-informers are run.
 func (f *federatedInformerImpl) GetReadyClusters() ([]*federationapi.Cluster, error) {
 	f.Lock()
@@ -346,7 +520,7 @@ func (f *federatedInformerImpl) GetReadyClusters() ([]*federationapi.Cluster, er
 	result := make([]*federationapi.Cluster, 0, len(items))
 	for _, item := range items {
 		if cluster, ok := item.(*federationapi.Cluster); ok {
-			if isClusterReady(cluster) {
+			if isClusterReady(cluster) && f.inShard(cluster) {
 				result = append(result, cluster)
 			}
 		} else {
@@ -366,7 +540,7 @@ func (f *federatedInformerImpl) GetReadyCluster(name string) (*federationapi.Clu
 func (f *federatedInformerImpl) getReadyClusterUnlocked(name string) (*federationapi.Cluster, bool, error) {
 	if obj, exist, err := f.clusterInformer.store.GetByKey(name); exist && err == nil {
 		if cluster, ok := obj.(*federationapi.Cluster); ok {
-			if isClusterReady(cluster) {
+			if isClusterReady(cluster) && f.inShard(cluster) {
 				return cluster, true, nil
 			}
 			return nil, false, nil
@@ -384,6 +558,47 @@ func (f *federatedInformerImpl) ClustersSynced() bool {
 	return f.clusterInformer.controller.HasSynced()
 }
 
+// LastSyncResourceVersion returns the resource version the target informer
+// for the given cluster most recently synced to.
+func (f *federatedInformerImpl) LastSyncResourceVersion(clusterName string) string {
+	f.Lock()
+	defer f.Unlock()
+	return f.observeSyncUnlocked(clusterName).resourceVersion
+}
+
+// LastSyncTime returns when the target informer for the given cluster was
+// last observed advancing its resource version. Since observations are made
+// on access, the reported time is an upper bound on when the informer last
+// made progress.
+func (f *federatedInformerImpl) LastSyncTime(clusterName string) time.Time {
+	f.Lock()
+	defer f.Unlock()
+	return f.observeSyncUnlocked(clusterName).observedAt
+}
+
+// observeSyncUnlocked reads the given cluster's current reflector resource
+// version and refreshes the stored observation if the version has changed
+// since the previous call. Returns a zero observation for unknown clusters
+// and for controllers that cannot report a resource version. Requires f to
+// be locked.
+func (f *federatedInformerImpl) observeSyncUnlocked(clusterName string) syncObservation {
+	targetInformer, found := f.targetInformers[clusterName]
+	if !found {
+		return syncObservation{}
+	}
+	getter, ok := targetInformer.controller.(resourceVersionGetter)
+	if !ok {
+		return syncObservation{}
+	}
+	resourceVersion := getter.LastSyncResourceVersion()
+	observation, tracked := f.syncObservations[clusterName]
+	if !tracked || observation.resourceVersion != resourceVersion {
+		observation = syncObservation{resourceVersion: resourceVersion, observedAt: time.Now()}
+		f.syncObservations[clusterName] = observation
+	}
+	return observation
+}
+
 // Adds the given cluster to federated informer.
 func (f *federatedInformerImpl) addCluster(cluster *federationapi.Cluster) {
 	f.Lock()
@@ -413,6 +628,7 @@ func (f *federatedInformerImpl) deleteCluster(cluster *federationapi.Cluster) {
 		close(targetInformer.stopChan)
 	}
 	delete(f.targetInformers, name)
+	delete(f.syncObservations, name)
 }
 
 // Returns a store created over all stores from target informers.
@@ -477,6 +693,80 @@ func (fs *federatedStoreImpl) GetFromAllClusters(key string) ([]FederatedObject,
 	return result, nil
 }
 
+// AddIndexer registers an additional index, available to ByIndex queries
+// under the given name.
+func (fs *federatedStoreImpl) AddIndexer(indexName string, indexFunc cache.IndexFunc) error {
+	fs.federatedInformer.Lock()
+	defer fs.federatedInformer.Unlock()
+
+	if _, found := fs.federatedInformer.indexers[indexName]; found {
+		return fmt.Errorf("index %q is already registered", indexName)
+	}
+	fs.federatedInformer.indexers[indexName] = indexFunc
+	return nil
+}
+
+// ByIndex returns the items from the given cluster whose indexed values for
+// the named index include the given key. An empty result is returned for
+// unknown clusters, mirroring ListFromCluster.
+func (fs *federatedStoreImpl) ByIndex(clusterName, indexName, indexKey string) ([]interface{}, error) {
+	fs.federatedInformer.Lock()
+	defer fs.federatedInformer.Unlock()
+
+	targetInformer, found := fs.federatedInformer.targetInformers[clusterName]
+	if !found {
+		return []interface{}{}, nil
+	}
+	return fs.byIndexUnlocked(targetInformer, indexName, indexKey)
+}
+
+// ByIndexFromAllClusters returns the matching items from all clusters.
+func (fs *federatedStoreImpl) ByIndexFromAllClusters(indexName, indexKey string) ([]FederatedObject, error) {
+	fs.federatedInformer.Lock()
+	defer fs.federatedInformer.Unlock()
+
+	result := make([]FederatedObject, 0)
+	for clusterName, targetInformer := range fs.federatedInformer.targetInformers {
+		items, err := fs.byIndexUnlocked(targetInformer, indexName, indexKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			result = append(result, FederatedObject{ClusterName: clusterName, Object: item})
+		}
+	}
+	return result, nil
+}
+
+// byIndexUnlocked evaluates the named index over a single target store. If the
+// underlying store happens to maintain the index itself it is used directly,
+// otherwise the registered index function is applied to the store content.
+func (fs *federatedStoreImpl) byIndexUnlocked(targetInformer informer, indexName, indexKey string) ([]interface{}, error) {
+	if indexer, ok := targetInformer.store.(cache.Indexer); ok {
+		if _, found := indexer.GetIndexers()[indexName]; found {
+			return indexer.ByIndex(indexName, indexKey)
+		}
+	}
+	indexFunc, found := fs.federatedInformer.indexers[indexName]
+	if !found {
+		return nil, fmt.Errorf("index %q is not registered", indexName)
+	}
+	result := make([]interface{}, 0)
+	for _, item := range targetInformer.store.List() {
+		indexValues, err := indexFunc(item)
+		if err != nil {
+			return nil, err
+		}
+		for _, indexValue := range indexValues {
+			if indexValue == indexKey {
+				result = append(result, item)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
 // GetKeyFor returns the key under which the item would be put in the store.
 func (fs *federatedStoreImpl) GetKeyFor(item interface{}) string {
 	// TODO: support other keying functions.
@@ -517,3 +807,19 @@ func (fs *federatedStoreImpl) ClustersSynced(clusters []*federationapi.Cluster)
 	}
 	return true
 }
+
+// ClusterSynced checks whether the store for the single named cluster has
+// completed its initial list.
+func (fs *federatedStoreImpl) ClusterSynced(clusterName string) bool {
+	// Get the informer to check under a lock and check it outside.
+	targetInformer, found := func() (informer, bool) {
+		fs.federatedInformer.Lock()
+		defer fs.federatedInformer.Unlock()
+		targetInformer, found := fs.federatedInformer.targetInformers[clusterName]
+		return targetInformer, found
+	}()
+	if !found {
+		return false
+	}
+	return targetInformer.controller.HasSynced()
+}
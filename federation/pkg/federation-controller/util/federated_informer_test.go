@@ -23,10 +23,12 @@ import (
 	federationapi "k8s.io/kubernetes/federation/apis/federation/v1beta1"
 	fakefederationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset/fake"
 	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
 	"k8s.io/kubernetes/pkg/client/cache"
 	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
 	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
 	"k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/watch"
 
@@ -147,3 +149,450 @@ func TestFederatedInformer(t *testing.T) {
 	// Test complete.
 	informer.Stop()
 }
+
+// Checks the sharded informer: only clusters matching the shard selector are
+// picked up, and relabeling a cluster into or out of the shard behaves like
+// adding or deleting it.
+func TestShardedFederatedInformer(t *testing.T) {
+	clusterA := federationapi.Cluster{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:   "cluster-a",
+			Labels: map[string]string{"shard": "a"},
+		},
+		Status: federationapi.ClusterStatus{
+			Conditions: []federationapi.ClusterCondition{
+				{Type: federationapi.ClusterReady, Status: apiv1.ConditionTrue},
+			},
+		},
+	}
+	clusterB := federationapi.Cluster{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:   "cluster-b",
+			Labels: map[string]string{"shard": "b"},
+		},
+		Status: federationapi.ClusterStatus{
+			Conditions: []federationapi.ClusterCondition{
+				{Type: federationapi.ClusterReady, Status: apiv1.ConditionTrue},
+			},
+		},
+	}
+	fakeFederationClient := &fakefederationclientset.Clientset{}
+	fakeFederationClient.AddReactor("list", "clusters", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &federationapi.ClusterList{Items: []federationapi.Cluster{clusterA, clusterB}}, nil
+	})
+	clusterWatch := watch.NewRaceFreeFake()
+	fakeFederationClient.AddWatchReactor("clusters", func(action core.Action) (bool, watch.Interface, error) {
+		return true, clusterWatch, nil
+	})
+
+	fakeKubeClient := &fakekubeclientset.Clientset{}
+	fakeKubeClient.AddReactor("list", "services", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.ServiceList{}, nil
+	})
+	fakeKubeClient.AddWatchReactor("services", func(action core.Action) (bool, watch.Interface, error) {
+		return true, watch.NewRaceFreeFake(), nil
+	})
+
+	targetInformerFactory := func(cluster *federationapi.Cluster, clientset kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+		return cache.NewInformer(
+			&cache.ListWatch{
+				ListFunc: func(options apiv1.ListOptions) (runtime.Object, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).List(options)
+				},
+				WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).Watch(options)
+				},
+			},
+			&apiv1.Service{},
+			10*time.Second,
+			cache.ResourceEventHandlerFuncs{})
+	}
+
+	availableClusters := make(chan string, 10)
+	unavailableClusters := make(chan string, 10)
+	lifecycle := ClusterLifecycleHandlerFuncs{
+		ClusterAvailable: func(cluster *federationapi.Cluster) {
+			availableClusters <- cluster.Name
+		},
+		ClusterUnavailable: func(cluster *federationapi.Cluster, _ []interface{}) {
+			unavailableClusters <- cluster.Name
+		},
+	}
+	selector, err := labels.Parse("shard=a")
+	assert.NoError(t, err)
+
+	informer := NewShardedFederatedInformer(fakeFederationClient, targetInformerFactory, selector, &lifecycle).(*federatedInformerImpl)
+	informer.clientFactory = func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+		// Relabeling below moves clusters across the shard boundary, so the
+		// assertion is on the labels the cluster carries when the clientset
+		// is requested, not on its name.
+		if !selector.Matches(labels.Set(cluster.ObjectMeta.Labels)) {
+			t.Errorf("a clientset was requested for out-of-shard cluster %q (labels %v)", cluster.Name, cluster.ObjectMeta.Labels)
+		}
+		return fakeKubeClient, nil
+	}
+	informer.Start()
+	defer informer.Stop()
+
+	// Only the matching cluster is picked up; the other never appears.
+	assert.Equal(t, clusterA.Name, <-availableClusters)
+	readyClusters, err := informer.GetReadyClusters()
+	assert.NoError(t, err)
+	assert.Len(t, readyClusters, 1)
+	_, found, err := informer.GetReadyCluster(clusterB.Name)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// Relabeling the other cluster into the shard is handled like an add.
+	relabeledB := clusterB
+	relabeledB.ObjectMeta.Labels = map[string]string{"shard": "a"}
+	clusterWatch.Modify(&relabeledB)
+	assert.Equal(t, clusterB.Name, <-availableClusters)
+
+	// Relabeling a cluster out of the shard is handled like a delete.
+	relabeledA := clusterA
+	relabeledA.ObjectMeta.Labels = map[string]string{"shard": "b"}
+	clusterWatch.Modify(&relabeledA)
+	assert.Equal(t, clusterA.Name, <-unavailableClusters)
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(50 * time.Millisecond) {
+		if _, found, err := informer.GetReadyCluster(clusterA.Name); !found && err == nil {
+			break
+		}
+	}
+	_, found, err = informer.GetReadyCluster(clusterA.Name)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+// Checks that the per-cluster last sync accessors advance as the fake watch
+// delivers events, and return zero values for unknown clusters.
+func TestFederatedInformerLastSync(t *testing.T) {
+	fakeFederationClient := &fakefederationclientset.Clientset{}
+	cluster := federationapi.Cluster{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name: "mycluster",
+		},
+		Status: federationapi.ClusterStatus{
+			Conditions: []federationapi.ClusterCondition{
+				{Type: federationapi.ClusterReady, Status: apiv1.ConditionTrue},
+			},
+		},
+	}
+	fakeFederationClient.AddReactor("list", "clusters", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &federationapi.ClusterList{Items: []federationapi.Cluster{cluster}}, nil
+	})
+	fakeFederationClient.AddWatchReactor("clusters", func(action core.Action) (bool, watch.Interface, error) {
+		return true, watch.NewFake(), nil
+	})
+
+	service := apiv1.Service{
+		ObjectMeta: apiv1.ObjectMeta{
+			Namespace:       "ns1",
+			Name:            "s1",
+			ResourceVersion: "10",
+		},
+	}
+	fakeKubeClient := &fakekubeclientset.Clientset{}
+	fakeKubeClient.AddReactor("list", "services", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.ServiceList{
+			ListMeta: metav1.ListMeta{ResourceVersion: "10"},
+			Items:    []apiv1.Service{service},
+		}, nil
+	})
+	serviceWatch := watch.NewRaceFreeFake()
+	fakeKubeClient.AddWatchReactor("services", func(action core.Action) (bool, watch.Interface, error) {
+		return true, serviceWatch, nil
+	})
+
+	targetInformerFactory := func(cluster *federationapi.Cluster, clientset kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+		return cache.NewInformer(
+			&cache.ListWatch{
+				ListFunc: func(options apiv1.ListOptions) (runtime.Object, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).List(options)
+				},
+				WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).Watch(options)
+				},
+			},
+			&apiv1.Service{},
+			10*time.Second,
+			cache.ResourceEventHandlerFuncs{})
+	}
+
+	informer := NewFederatedInformer(fakeFederationClient, targetInformerFactory, &ClusterLifecycleHandlerFuncs{}).(*federatedInformerImpl)
+	informer.clientFactory = func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+		return fakeKubeClient, nil
+	}
+	informer.Start()
+	defer informer.Stop()
+	for !informer.GetTargetStore().ClustersSynced([]*federationapi.Cluster{&cluster}) {
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	waitForResourceVersion := func(expected string) {
+		for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(50 * time.Millisecond) {
+			if informer.LastSyncResourceVersion("mycluster") == expected {
+				return
+			}
+		}
+		t.Fatalf("informer did not sync to resource version %q, last: %q",
+			expected, informer.LastSyncResourceVersion("mycluster"))
+	}
+
+	// The generated fake clientset rebuilds list results and drops their
+	// ListMeta, so the initial list leaves the reflector at an empty resource
+	// version; only watch events carry versions the fake preserves. The sync
+	// time is recorded either way.
+	assert.Equal(t, "", informer.LastSyncResourceVersion("mycluster"))
+	firstSync := informer.LastSyncTime("mycluster")
+	assert.False(t, firstSync.IsZero())
+
+	// A watch event advances the resource version without regressing the
+	// sync time.
+	updated := service
+	updated.ObjectMeta.ResourceVersion = "20"
+	serviceWatch.Modify(&updated)
+	waitForResourceVersion("20")
+	assert.False(t, informer.LastSyncTime("mycluster").Before(firstSync))
+
+	// Unknown clusters report zero values.
+	assert.Equal(t, "", informer.LastSyncResourceVersion("no-such-cluster"))
+	assert.True(t, informer.LastSyncTime("no-such-cluster").IsZero())
+}
+
+// Checks that ClusterSynced reports false while a cluster's initial list is
+// still in progress, so that controllers do not mistake a partial list for
+// missing objects, and flips to true once the list completes.
+func TestFederatedInformerClusterSynced(t *testing.T) {
+	fakeFederationClient := &fakefederationclientset.Clientset{}
+	cluster := federationapi.Cluster{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name: "mycluster",
+		},
+		Status: federationapi.ClusterStatus{
+			Conditions: []federationapi.ClusterCondition{
+				{Type: federationapi.ClusterReady, Status: apiv1.ConditionTrue},
+			},
+		},
+	}
+	fakeFederationClient.AddReactor("list", "clusters", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &federationapi.ClusterList{Items: []federationapi.Cluster{cluster}}, nil
+	})
+	fakeFederationClient.AddWatchReactor("clusters", func(action core.Action) (bool, watch.Interface, error) {
+		return true, watch.NewFake(), nil
+	})
+
+	service := apiv1.Service{
+		ObjectMeta: apiv1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "s1",
+		},
+	}
+	// The initial service list does not return until listCompleted is closed,
+	// simulating a slow or partial initial list.
+	listCompleted := make(chan struct{})
+	fakeKubeClient := &fakekubeclientset.Clientset{}
+	fakeKubeClient.AddReactor("list", "services", func(action core.Action) (bool, runtime.Object, error) {
+		<-listCompleted
+		return true, &apiv1.ServiceList{Items: []apiv1.Service{service}}, nil
+	})
+	fakeKubeClient.AddWatchReactor("services", func(action core.Action) (bool, watch.Interface, error) {
+		return true, watch.NewFake(), nil
+	})
+
+	targetInformerFactory := func(cluster *federationapi.Cluster, clientset kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+		return cache.NewInformer(
+			&cache.ListWatch{
+				ListFunc: func(options apiv1.ListOptions) (runtime.Object, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).List(options)
+				},
+				WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).Watch(options)
+				},
+			},
+			&apiv1.Service{},
+			10*time.Second,
+			cache.ResourceEventHandlerFuncs{})
+	}
+
+	informer := NewFederatedInformer(fakeFederationClient, targetInformerFactory, &ClusterLifecycleHandlerFuncs{}).(*federatedInformerImpl)
+	informer.clientFactory = func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+		return fakeKubeClient, nil
+	}
+	informer.Start()
+	defer informer.Stop()
+	store := informer.GetTargetStore()
+
+	// Wait for the cluster to become ready; its target informer is stuck in
+	// the initial list at this point.
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(50 * time.Millisecond) {
+		if _, found, err := informer.GetReadyCluster("mycluster"); found && err == nil {
+			break
+		}
+	}
+
+	// The cluster must not be reported as synced and its objects must not be
+	// visible while the list is still in progress.
+	assert.False(t, store.ClusterSynced("mycluster"))
+	assert.False(t, store.ClustersSynced([]*federationapi.Cluster{&cluster}))
+	_, found, err := store.GetByKey("mycluster", "ns1/s1")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// Completing the list flips the cluster to synced and makes the object
+	// visible.
+	close(listCompleted)
+	for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(50 * time.Millisecond) {
+		if store.ClusterSynced("mycluster") {
+			break
+		}
+	}
+	assert.True(t, store.ClusterSynced("mycluster"))
+	_, found, err = store.GetByKey("mycluster", "ns1/s1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	// Unknown clusters are never synced.
+	assert.False(t, store.ClusterSynced("no-such-cluster"))
+}
+
+// Checks that the default federated store indexes (namespace and managed-by
+// label) stay consistent across object add/update/delete and cluster removal.
+func TestFederatedStoreIndexes(t *testing.T) {
+	fakeFederationClient := &fakefederationclientset.Clientset{}
+	cluster := federationapi.Cluster{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name: "mycluster",
+		},
+		Status: federationapi.ClusterStatus{
+			Conditions: []federationapi.ClusterCondition{
+				{Type: federationapi.ClusterReady, Status: apiv1.ConditionTrue},
+			},
+		},
+	}
+	fakeFederationClient.AddReactor("list", "clusters", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &federationapi.ClusterList{Items: []federationapi.Cluster{cluster}}, nil
+	})
+	deleteChan := make(chan struct{})
+	fakeFederationClient.AddWatchReactor("clusters", func(action core.Action) (bool, watch.Interface, error) {
+		fakeWatch := watch.NewFake()
+		go func() {
+			<-deleteChan
+			fakeWatch.Delete(&cluster)
+		}()
+		return true, fakeWatch, nil
+	})
+
+	managedService := apiv1.Service{
+		ObjectMeta: apiv1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "s1",
+			Labels:    map[string]string{ManagedByLabel: "myfederation"},
+		},
+	}
+	unmanagedService := apiv1.Service{
+		ObjectMeta: apiv1.ObjectMeta{
+			Namespace: "ns2",
+			Name:      "s2",
+		},
+	}
+	fakeKubeClient := &fakekubeclientset.Clientset{}
+	fakeKubeClient.AddReactor("list", "services", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.ServiceList{Items: []apiv1.Service{managedService, unmanagedService}}, nil
+	})
+	serviceWatch := watch.NewRaceFreeFake()
+	fakeKubeClient.AddWatchReactor("services", func(action core.Action) (bool, watch.Interface, error) {
+		return true, serviceWatch, nil
+	})
+
+	targetInformerFactory := func(cluster *federationapi.Cluster, clientset kubeclientset.Interface) (cache.Store, cache.ControllerInterface) {
+		return cache.NewInformer(
+			&cache.ListWatch{
+				ListFunc: func(options apiv1.ListOptions) (runtime.Object, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).List(options)
+				},
+				WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+					return clientset.Core().Services(apiv1.NamespaceAll).Watch(options)
+				},
+			},
+			&apiv1.Service{},
+			10*time.Second,
+			cache.ResourceEventHandlerFuncs{})
+	}
+
+	informer := NewFederatedInformer(fakeFederationClient, targetInformerFactory, &ClusterLifecycleHandlerFuncs{}).(*federatedInformerImpl)
+	informer.clientFactory = func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+		return fakeKubeClient, nil
+	}
+	informer.Start()
+	store := informer.GetTargetStore()
+	for !store.ClustersSynced([]*federationapi.Cluster{&cluster}) {
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	waitForIndexSize := func(clusterName, indexName, indexKey string, size int) []interface{} {
+		var items []interface{}
+		var err error
+		for start := time.Now(); time.Since(start) < 10*time.Second; time.Sleep(50 * time.Millisecond) {
+			items, err = store.ByIndex(clusterName, indexName, indexKey)
+			assert.NoError(t, err)
+			if len(items) == size {
+				return items
+			}
+		}
+		t.Fatalf("index %s[%s] in cluster %s did not reach size %d, last: %v", indexName, indexKey, clusterName, size, items)
+		return nil
+	}
+
+	// Initial state: one service per namespace, one carries the label.
+	items := waitForIndexSize("mycluster", NamespaceIndex, "ns1", 1)
+	assert.EqualValues(t, &managedService, items[0])
+	waitForIndexSize("mycluster", NamespaceIndex, "ns2", 1)
+	items = waitForIndexSize("mycluster", ManagedByLabelIndex, "myfederation", 1)
+	assert.EqualValues(t, &managedService, items[0])
+
+	federatedItems, err := store.ByIndexFromAllClusters(ManagedByLabelIndex, "myfederation")
+	assert.NoError(t, err)
+	assert.Len(t, federatedItems, 1)
+	assert.Equal(t, "mycluster", federatedItems[0].ClusterName)
+
+	// Unknown indexes are an error, re-registering a default index too.
+	_, err = store.ByIndex("mycluster", "no-such-index", "x")
+	assert.Error(t, err)
+	assert.Error(t, store.AddIndexer(NamespaceIndex, cache.MetaNamespaceIndexFunc))
+
+	// Add: a new service in ns1 shows up in the namespace index.
+	added := apiv1.Service{
+		ObjectMeta: apiv1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "s3",
+			Labels:    map[string]string{ManagedByLabel: "myfederation"},
+		},
+	}
+	serviceWatch.Add(&added)
+	waitForIndexSize("mycluster", NamespaceIndex, "ns1", 2)
+	waitForIndexSize("mycluster", ManagedByLabelIndex, "myfederation", 2)
+
+	// Update: removing the label takes the object out of the label index.
+	updated := added
+	updated.ObjectMeta.Labels = nil
+	serviceWatch.Modify(&updated)
+	waitForIndexSize("mycluster", ManagedByLabelIndex, "myfederation", 1)
+	waitForIndexSize("mycluster", NamespaceIndex, "ns1", 2)
+
+	// Delete: the object disappears from the namespace index.
+	serviceWatch.Delete(&updated)
+	waitForIndexSize("mycluster", NamespaceIndex, "ns1", 1)
+
+	// Cluster removal: all index queries come back empty.
+	deleteChan <- struct{}{}
+	for !store.ClustersSynced([]*federationapi.Cluster{}) {
+		time.Sleep(time.Millisecond * 100)
+	}
+	items, err = store.ByIndex("mycluster", NamespaceIndex, "ns1")
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+	federatedItems, err = store.ByIndexFromAllClusters(ManagedByLabelIndex, "myfederation")
+	assert.NoError(t, err)
+	assert.Empty(t, federatedItems)
+}
@@ -40,6 +40,41 @@ type FederatedOperation struct {
 	Obj         pkgruntime.Object
 }
 
+// RetryPolicy is the retry and timeout budget for a single operation type
+// executed by a FederatedUpdater. The zero value of a field falls back to the
+// updater's defaults.
+type RetryPolicy struct {
+	// PerOperationTimeout bounds a single attempt. The timeout is
+	// best-effort: the clientsets in this release do not accept a context,
+	// so a timed-out attempt is abandoned rather than cancelled.
+	PerOperationTimeout time.Duration
+	// MaxRetries is the number of additional attempts after a failed one.
+	MaxRetries int
+	// Backoff is the delay between consecutive attempts.
+	Backoff time.Duration
+}
+
+// RetryPolicies groups the per-operation-type retry policies, so that e.g.
+// creates in slow clusters can get a larger budget than status writes.
+type RetryPolicies struct {
+	Add    RetryPolicy
+	Update RetryPolicy
+	Delete RetryPolicy
+}
+
+// forType returns the policy for the given operation type.
+func (p RetryPolicies) forType(opType FederatedOperationType) RetryPolicy {
+	switch opType {
+	case OperationTypeAdd:
+		return p.Add
+	case OperationTypeUpdate:
+		return p.Update
+	case OperationTypeDelete:
+		return p.Delete
+	}
+	return RetryPolicy{}
+}
+
 // A helper that executes the given set of updates on federation, in parallel.
 type FederatedUpdater interface {
 	// Executes the given set of operations within the specified timeout.
@@ -49,6 +84,11 @@ type FederatedUpdater interface {
 	Update([]FederatedOperation, time.Duration) error
 
 	UpdateWithOnError([]FederatedOperation, time.Duration, func(FederatedOperation, error)) error
+
+	// Executes the given set of operations applying the retry policy for
+	// each operation's type independently. A policy with a zero
+	// PerOperationTimeout falls back to the given default timeout.
+	UpdateWithPolicies([]FederatedOperation, time.Duration, RetryPolicies, func(FederatedOperation, error)) error
 }
 
 // A function that executes some operation using the passed client and object.
@@ -60,14 +100,26 @@ type federatedUpdaterImpl struct {
 	addFunction    FederatedOperationHandler
 	updateFunction FederatedOperationHandler
 	deleteFunction FederatedOperationHandler
+
+	// Optional per-cluster circuit breaker. Nil never trips.
+	circuitBreaker *CircuitBreaker
 }
 
 func NewFederatedUpdater(federation FederationView, add, update, del FederatedOperationHandler) FederatedUpdater {
+	return NewFederatedUpdaterWithCircuitBreaker(federation, nil, add, update, del)
+}
+
+// NewFederatedUpdaterWithCircuitBreaker is NewFederatedUpdater with a
+// per-cluster circuit breaker: operations targeting a cluster whose circuit
+// is open fail fast with ErrClusterCircuitOpen, and operation outcomes feed
+// the breaker's failure counts.
+func NewFederatedUpdaterWithCircuitBreaker(federation FederationView, circuitBreaker *CircuitBreaker, add, update, del FederatedOperationHandler) FederatedUpdater {
 	return &federatedUpdaterImpl{
 		federation:     federation,
 		addFunction:    add,
 		updateFunction: update,
 		deleteFunction: del,
+		circuitBreaker: circuitBreaker,
 	}
 }
 
@@ -75,27 +127,87 @@ func (fu *federatedUpdaterImpl) Update(ops []FederatedOperation, timeout time.Du
 	return fu.UpdateWithOnError(ops, timeout, nil)
 }
 
-func (fu *federatedUpdaterImpl) UpdateWithOnError(ops []FederatedOperation, timeout time.Duration, onError func(FederatedOperation, error)) error {
+// execute runs a single operation using the clientset of its target cluster.
+func (fu *federatedUpdaterImpl) execute(op FederatedOperation) error {
+	if err := fu.circuitBreaker.Allow(op.ClusterName); err != nil {
+		return err
+	}
+	clientset, err := fu.federation.GetClientsetForCluster(op.ClusterName)
+	if err != nil {
+		return err
+	}
+	switch op.Type {
+	case OperationTypeAdd:
+		err = fu.addFunction(clientset, op.Obj)
+	case OperationTypeUpdate:
+		err = fu.updateFunction(clientset, op.Obj)
+	case OperationTypeDelete:
+		err = fu.deleteFunction(clientset, op.Obj)
+	default:
+		return fmt.Errorf("unknown operation type %q", op.Type)
+	}
+	if err != nil {
+		fu.circuitBreaker.RecordFailure(op.ClusterName)
+	} else {
+		fu.circuitBreaker.RecordSuccess(op.ClusterName)
+	}
+	return err
+}
+
+// executeWithTimeout runs a single operation, giving up after the given
+// timeout. A timed-out attempt is abandoned, not cancelled: the clientsets in
+// this release do not accept a context.
+func (fu *federatedUpdaterImpl) executeWithTimeout(op FederatedOperation, timeout time.Duration) error {
+	attemptDone := make(chan error, 1)
+	go func() {
+		attemptDone <- fu.execute(op)
+	}()
+	select {
+	case err := <-attemptDone:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("failed to finish %s in cluster %s in %v", op.Type, op.ClusterName, timeout)
+	}
+}
+
+func (fu *federatedUpdaterImpl) UpdateWithPolicies(ops []FederatedOperation, defaultTimeout time.Duration, policies RetryPolicies, onError func(FederatedOperation, error)) error {
 	done := make(chan error, len(ops))
 	for _, op := range ops {
 		go func(op FederatedOperation) {
-			clusterName := op.ClusterName
-
-			// TODO: Ensure that the clientset has reasonable timeout.
-			clientset, err := fu.federation.GetClientsetForCluster(clusterName)
-			if err != nil {
-				done <- err
-				return
+			policy := policies.forType(op.Type)
+			timeout := policy.PerOperationTimeout
+			if timeout == 0 {
+				timeout = defaultTimeout
 			}
-
-			switch op.Type {
-			case OperationTypeAdd:
-				err = fu.addFunction(clientset, op.Obj)
-			case OperationTypeUpdate:
-				err = fu.updateFunction(clientset, op.Obj)
-			case OperationTypeDelete:
-				err = fu.deleteFunction(clientset, op.Obj)
+			var err error
+			for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
+				if attempt > 0 && policy.Backoff > 0 {
+					time.Sleep(policy.Backoff)
+				}
+				if err = fu.executeWithTimeout(op, timeout); err == nil {
+					break
+				}
+			}
+			if err != nil && onError != nil {
+				onError(op, err)
 			}
+			done <- err
+		}(op)
+	}
+	var firstErr error
+	for i := 0; i < len(ops); i++ {
+		if err := <-done; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (fu *federatedUpdaterImpl) UpdateWithOnError(ops []FederatedOperation, timeout time.Duration, onError func(FederatedOperation, error)) error {
+	done := make(chan error, len(ops))
+	for _, op := range ops {
+		go func(op FederatedOperation) {
+			err := fu.execute(op)
 			if err != nil && onError != nil {
 				onError(op, err)
 			}
@@ -146,3 +146,103 @@ func makeService(cluster, name string) *apiv1.Service {
 func noop(_ kubeclientset.Interface, _ pkgruntime.Object) error {
 	return nil
 }
+
+// Checks that UpdateWithPolicies applies the per-operation-type budgets
+// independently: a short create timeout must not affect deletes, and failed
+// attempts are retried up to the policy's limit.
+func TestFederatedUpdaterPolicies(t *testing.T) {
+	deleteChan := make(chan string, 5)
+	updater := NewFederatedUpdater(&fakeFederationView{},
+		func(_ kubeclientset.Interface, obj pkgruntime.Object) error {
+			time.Sleep(time.Minute)
+			return nil
+		},
+		noop,
+		func(_ kubeclientset.Interface, obj pkgruntime.Object) error {
+			service := obj.(*apiv1.Service)
+			deleteChan <- service.Name
+			return nil
+		})
+
+	start := time.Now()
+	err := updater.UpdateWithPolicies([]FederatedOperation{
+		{
+			Type: OperationTypeAdd,
+			Obj:  makeService("A", "s1"),
+		},
+		{
+			Type: OperationTypeDelete,
+			Obj:  makeService("B", "s2"),
+		},
+	}, time.Minute, RetryPolicies{
+		Add: RetryPolicy{PerOperationTimeout: 100 * time.Millisecond},
+	}, nil)
+	end := time.Now()
+	assert.Error(t, err, "the slow create must time out")
+	assert.True(t, start.Add(5*time.Second).After(end), "the create timeout must not wait for the default timeout")
+	assert.Equal(t, "s2", <-deleteChan, "the delete must not be affected by the create timeout")
+
+	// Failed attempts are retried up to MaxRetries times.
+	attempts := make(chan struct{}, 10)
+	failTwice := 2
+	updater = NewFederatedUpdater(&fakeFederationView{},
+		func(_ kubeclientset.Interface, obj pkgruntime.Object) error {
+			attempts <- struct{}{}
+			if failTwice > 0 {
+				failTwice--
+				return fmt.Errorf("transient error")
+			}
+			return nil
+		},
+		noop, noop)
+	err = updater.UpdateWithPolicies([]FederatedOperation{
+		{
+			Type: OperationTypeAdd,
+			Obj:  makeService("A", "s1"),
+		},
+	}, time.Minute, RetryPolicies{
+		Add: RetryPolicy{MaxRetries: 3, Backoff: time.Millisecond},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 3, "two failures plus the successful attempt")
+}
+
+// Checks that operations targeting a cluster whose circuit breaker is open
+// fail fast without invoking the operation handler or waiting for a timeout.
+func TestFederatedUpdaterCircuitBreaker(t *testing.T) {
+	attempts := make(chan string, 10)
+	breaker := NewCircuitBreaker(1, time.Hour)
+	updater := NewFederatedUpdaterWithCircuitBreaker(&fakeFederationView{}, breaker,
+		func(_ kubeclientset.Interface, obj pkgruntime.Object) error {
+			service := obj.(*apiv1.Service)
+			attempts <- service.Name
+			return fmt.Errorf("request failed")
+		},
+		noop, noop)
+
+	// The first failure trips the breaker.
+	err := updater.Update([]FederatedOperation{
+		{
+			Type:        OperationTypeAdd,
+			ClusterName: "A",
+			Obj:         makeService("A", "s1"),
+		},
+	}, time.Minute)
+	assert.Error(t, err)
+	assert.Equal(t, "s1", <-attempts)
+	assert.Equal(t, CircuitOpen, breaker.State("A"))
+
+	// Subsequent operations fail fast with ErrClusterCircuitOpen and never
+	// reach the handler.
+	start := time.Now()
+	err = updater.Update([]FederatedOperation{
+		{
+			Type:        OperationTypeAdd,
+			ClusterName: "A",
+			Obj:         makeService("A", "s2"),
+		},
+	}, time.Minute)
+	assert.Equal(t, ErrClusterCircuitOpen, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+	assert.Len(t, attempts, 0)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inspect offers a typed, read-only view of the aggregated state of a
+// federated object: the federated API object, each member cluster's live
+// copy, and a sync summary computed with the same equivalence logic the
+// controllers use. Intended for debugging and internal tooling, not for
+// controllers.
+package inspect
+
+import (
+	"sort"
+
+	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/ingress"
+	"k8s.io/kubernetes/pkg/api/errors"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+)
+
+// ClusterIngressState is one member cluster's view of a federated ingress.
+type ClusterIngressState struct {
+	// Ingress is the cluster's live copy, nil when the cluster has none.
+	Ingress *extensionsv1beta1.Ingress
+	// Found is false when the cluster returned NotFound for the copy.
+	Found bool
+	// Equivalent reports whether the copy is equivalent to the federated
+	// object under the controller's comparison. Always false when the copy
+	// was not found.
+	Equivalent bool
+}
+
+// IngressState is the aggregated federation-wide state of one federated
+// ingress.
+type IngressState struct {
+	// Federated is the object as read from the federation API.
+	Federated *extensionsv1beta1.Ingress
+	// Clusters holds each queried cluster's copy and its sync state, keyed
+	// by cluster name. Clusters without a copy are present with Found false.
+	Clusters map[string]ClusterIngressState
+	// Synced, Drifted and Missing summarize Clusters as sorted name lists:
+	// clusters whose copy is equivalent, clusters whose copy differs, and
+	// clusters without a copy.
+	Synced  []string
+	Drifted []string
+	Missing []string
+	// Propagation is the parsed propagation state from the federated
+	// object's annotations.
+	Propagation ingress.PropagationStatus
+}
+
+// InspectIngress reads the federated ingress namespace/name and its live copy
+// from every given cluster client, and computes the per-cluster equivalence
+// with the same logic the ingress controller uses. An error reading the
+// federation API or a cluster (other than NotFound) fails the whole
+// inspection rather than returning a partial view.
+func InspectIngress(fedClient federationclientset.Interface, clusterClients map[string]kubeclientset.Interface, namespace, name string) (*IngressState, error) {
+	fedIngress, err := fedClient.Extensions().Ingresses(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	state := &IngressState{
+		Federated:   fedIngress,
+		Clusters:    make(map[string]ClusterIngressState, len(clusterClients)),
+		Propagation: ingress.ParsePropagationStatus(fedIngress),
+	}
+	clusterNames := make([]string, 0, len(clusterClients))
+	for clusterName := range clusterClients {
+		clusterNames = append(clusterNames, clusterName)
+	}
+	sort.Strings(clusterNames)
+	for _, clusterName := range clusterNames {
+		clusterIngress, err := clusterClients[clusterName].Extensions().Ingresses(namespace).Get(name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			state.Clusters[clusterName] = ClusterIngressState{}
+			state.Missing = append(state.Missing, clusterName)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		equivalent := ingress.ClusterCopyEquivalent(fedIngress, clusterIngress)
+		state.Clusters[clusterName] = ClusterIngressState{
+			Ingress:    clusterIngress,
+			Found:      true,
+			Equivalent: equivalent,
+		}
+		if equivalent {
+			state.Synced = append(state.Synced, clusterName)
+		} else {
+			state.Drifted = append(state.Drifted, clusterName)
+		}
+	}
+	return state, nil
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inspect
+
+import (
+	"testing"
+	"time"
+
+	fakefedclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset/fake"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func inspectTestIngress() *extensionsv1beta1.Ingress {
+	return &extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "test-ingress",
+			Namespace: "mynamespace",
+			Annotations: map[string]string{
+				"federation.alpha.kubernetes.io/primary-cluster":      "cluster1",
+				"federation.alpha.kubernetes.io/propagation-deadline": "10m",
+				"federation.kubernetes.io/lb-pending":                 "true",
+			},
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{
+				ServiceName: "testsvc",
+				ServicePort: intstr.FromInt(80),
+			},
+		},
+	}
+}
+
+// Inspects a federation of three clusters: one in sync (modulo the
+// federation-added markers), one with a drifted spec, and one without a copy.
+func TestInspectIngress(t *testing.T) {
+	fedIngress := inspectTestIngress()
+	fedClient := fakefedclientset.NewSimpleClientset(fedIngress)
+
+	// The synced copy carries the managed-by marker and lacks the lb-pending
+	// marker; both are federation bookkeeping and must not count as drift.
+	syncedCopy := inspectTestIngress()
+	syncedCopy.ObjectMeta.Labels = map[string]string{util.ManagedByLabel: "myfederation"}
+	delete(syncedCopy.ObjectMeta.Annotations, "federation.kubernetes.io/lb-pending")
+
+	driftedCopy := inspectTestIngress()
+	delete(driftedCopy.ObjectMeta.Annotations, "federation.kubernetes.io/lb-pending")
+	driftedCopy.Spec.Backend.ServiceName = "someothersvc"
+
+	clusterClients := map[string]kubeclientset.Interface{
+		"cluster1": fakekubeclientset.NewSimpleClientset(syncedCopy),
+		"cluster2": fakekubeclientset.NewSimpleClientset(driftedCopy),
+		"cluster3": fakekubeclientset.NewSimpleClientset(),
+	}
+
+	state, err := InspectIngress(fedClient, clusterClients, "mynamespace", "test-ingress")
+	assert.NoError(t, err)
+	if !assert.NotNil(t, state) {
+		return
+	}
+
+	assert.Equal(t, fedIngress.ObjectMeta.Name, state.Federated.ObjectMeta.Name)
+	assert.Equal(t, []string{"cluster1"}, state.Synced)
+	assert.Equal(t, []string{"cluster2"}, state.Drifted)
+	assert.Equal(t, []string{"cluster3"}, state.Missing)
+
+	assert.True(t, state.Clusters["cluster1"].Found)
+	assert.True(t, state.Clusters["cluster1"].Equivalent)
+	assert.True(t, state.Clusters["cluster2"].Found)
+	assert.False(t, state.Clusters["cluster2"].Equivalent)
+	assert.False(t, state.Clusters["cluster3"].Found)
+	assert.Nil(t, state.Clusters["cluster3"].Ingress)
+
+	assert.Equal(t, "cluster1", state.Propagation.PrimaryCluster)
+	assert.Equal(t, 10*time.Minute, state.Propagation.PropagationDeadline)
+	assert.True(t, state.Propagation.LoadBalancerPending)
+	assert.False(t, state.Propagation.Degraded)
+}
+
+// A missing federated object is an error, not an empty state.
+func TestInspectIngressFederatedNotFound(t *testing.T) {
+	fedClient := fakefedclientset.NewSimpleClientset()
+	state, err := InspectIngress(fedClient, map[string]kubeclientset.Interface{}, "mynamespace", "no-such-ingress")
+	assert.Error(t, err)
+	assert.Nil(t, state)
+}
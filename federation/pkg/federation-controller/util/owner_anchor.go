@@ -0,0 +1,115 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+
+	"github.com/golang/glog"
+)
+
+const (
+	// Name of the per-namespace anchor ConfigMap that federation-created
+	// objects in member clusters point at via ownerReferences. Cluster-level
+	// garbage collection can then chain dependents of federation-managed
+	// objects off the anchor.
+	AnchorConfigMapName = "federation-anchor"
+	// Label set on anchor ConfigMaps so that they are recognizable as
+	// federation-managed.
+	anchorLabelKey = "federation.kubernetes.io/anchor"
+)
+
+// AnchorManager lazily creates a per-cluster, per-namespace anchor ConfigMap
+// and removes it again when the last federation-managed object in that
+// namespace is gone. Controllers opt in by stamping the anchor ownerReference
+// on the objects they create in member clusters.
+type AnchorManager struct {
+	federation FederationView
+}
+
+// NewAnchorManager creates an AnchorManager operating on the clusters of the
+// given federation view.
+func NewAnchorManager(federation FederationView) *AnchorManager {
+	return &AnchorManager{federation: federation}
+}
+
+// EnsureAnchor returns the anchor ConfigMap for the given cluster and
+// namespace, creating it if it does not exist yet.
+func (am *AnchorManager) EnsureAnchor(clusterName, namespace string) (*apiv1.ConfigMap, error) {
+	client, err := am.federation.GetClientsetForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	anchor, err := client.Core().ConfigMaps(namespace).Get(AnchorConfigMapName, metav1.GetOptions{})
+	if err == nil {
+		return anchor, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+	glog.V(4).Infof("Creating anchor ConfigMap %s/%s in cluster %q", namespace, AnchorConfigMapName, clusterName)
+	anchor = &apiv1.ConfigMap{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      AnchorConfigMapName,
+			Namespace: namespace,
+			Labels:    map[string]string{anchorLabelKey: "true"},
+		},
+	}
+	created, err := client.Core().ConfigMaps(namespace).Create(anchor)
+	if errors.IsAlreadyExists(err) {
+		// Someone else created it in the meantime.
+		return client.Core().ConfigMaps(namespace).Get(AnchorConfigMapName, metav1.GetOptions{})
+	}
+	return created, err
+}
+
+// CleanupAnchor deletes the anchor ConfigMap for the given cluster and
+// namespace if no federation-managed objects remain in that namespace.
+// remainingManagedObjects is the number of such objects still present.
+func (am *AnchorManager) CleanupAnchor(clusterName, namespace string, remainingManagedObjects int) error {
+	if remainingManagedObjects > 0 {
+		return nil
+	}
+	client, err := am.federation.GetClientsetForCluster(clusterName)
+	if err != nil {
+		return err
+	}
+	glog.V(4).Infof("Deleting anchor ConfigMap %s/%s in cluster %q", namespace, AnchorConfigMapName, clusterName)
+	err = client.Core().ConfigMaps(namespace).Delete(AnchorConfigMapName, &apiv1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// AddAnchorOwnerReference adds an ownerReference pointing at the given anchor
+// to the given ObjectMeta, unless one is already present.
+func AddAnchorOwnerReference(objMeta *apiv1.ObjectMeta, anchor *apiv1.ConfigMap) {
+	for _, ref := range objMeta.OwnerReferences {
+		if ref.UID == anchor.UID {
+			return
+		}
+	}
+	objMeta.OwnerReferences = append(objMeta.OwnerReferences, metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       anchor.Name,
+		UID:        anchor.UID,
+	})
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Federation view handing out a fixed clientset for every cluster.
+type singleClientFederationView struct {
+	fakeFederationView
+	client kubeclientset.Interface
+}
+
+func (f *singleClientFederationView) GetClientsetForCluster(clusterName string) (kubeclientset.Interface, error) {
+	return f.client, nil
+}
+
+// Checks the anchor ConfigMap lifecycle: lazily created, reused while managed
+// objects remain and deleted once the last managed object is gone.
+func TestAnchorManager(t *testing.T) {
+	client := fakekubeclientset.NewSimpleClientset()
+	manager := NewAnchorManager(&singleClientFederationView{client: client})
+
+	// First use in the namespace creates the anchor.
+	anchor, err := manager.EnsureAnchor("mycluster", "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, AnchorConfigMapName, anchor.Name)
+	assert.Equal(t, "ns1", anchor.Namespace)
+
+	// Subsequent uses return the same anchor.
+	again, err := manager.EnsureAnchor("mycluster", "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, anchor.Name, again.Name)
+
+	// While managed objects remain the anchor must not be deleted.
+	assert.NoError(t, manager.CleanupAnchor("mycluster", "ns1", 1))
+	_, err = client.Core().ConfigMaps("ns1").Get(AnchorConfigMapName, metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// Once the last managed object is gone the anchor goes too.
+	assert.NoError(t, manager.CleanupAnchor("mycluster", "ns1", 0))
+	_, err = client.Core().ConfigMaps("ns1").Get(AnchorConfigMapName, metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err))
+
+	// Cleaning up an already deleted anchor is not an error.
+	assert.NoError(t, manager.CleanupAnchor("mycluster", "ns1", 0))
+}
+
+func TestAddAnchorOwnerReference(t *testing.T) {
+	anchor := &apiv1.ConfigMap{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      AnchorConfigMapName,
+			Namespace: "ns1",
+			UID:       "anchor-uid",
+		},
+	}
+	objMeta := &apiv1.ObjectMeta{Name: "myingress", Namespace: "ns1"}
+	AddAnchorOwnerReference(objMeta, anchor)
+	assert.Len(t, objMeta.OwnerReferences, 1)
+	assert.Equal(t, metav1.OwnerReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       AnchorConfigMapName,
+		UID:        "anchor-uid",
+	}, objMeta.OwnerReferences[0])
+
+	// Adding the same anchor twice must not duplicate the reference.
+	AddAnchorOwnerReference(objMeta, anchor)
+	assert.Len(t, objMeta.OwnerReferences, 1)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PropagationPolicyAnnotation, when present on a federated object, overrides
+// the controller-wide label/annotation propagation policy for that object.
+// The value uses the same format as ParsePropagationPolicy accepts.
+const PropagationPolicyAnnotation = "federation.kubernetes.io/propagation-policy"
+
+// PropagationPolicy decides which label and annotation keys the federation
+// sync controllers propagate onto member cluster copies. Keys matching an
+// include glob are always propagated, keys matching an exclude glob are
+// dropped (including removing them from cluster copies that already carry
+// them), and all remaining keys pass through unchanged. Include wins over
+// exclude when both match. A nil policy passes every key through.
+type PropagationPolicy struct {
+	Include []string
+	Exclude []string
+}
+
+// ParsePropagationPolicy parses a policy specification of the form
+// "include=glob1,glob2;exclude=glob3". Either section may be omitted; globs
+// use path.Match syntax.
+func ParsePropagationPolicy(spec string) (*PropagationPolicy, error) {
+	policy := &PropagationPolicy{}
+	for _, section := range strings.Split(spec, ";") {
+		section = strings.TrimSpace(section)
+		if section == "" {
+			continue
+		}
+		parts := strings.SplitN(section, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid propagation policy section %q, expected include=... or exclude=...", section)
+		}
+		globs := []string{}
+		for _, glob := range strings.Split(parts[1], ",") {
+			glob = strings.TrimSpace(glob)
+			if glob == "" {
+				continue
+			}
+			if _, err := path.Match(glob, ""); err != nil {
+				return nil, fmt.Errorf("invalid glob %q in propagation policy: %v", glob, err)
+			}
+			globs = append(globs, glob)
+		}
+		switch parts[0] {
+		case "include":
+			policy.Include = append(policy.Include, globs...)
+		case "exclude":
+			policy.Exclude = append(policy.Exclude, globs...)
+		default:
+			return nil, fmt.Errorf("unknown propagation policy section %q, expected include or exclude", parts[0])
+		}
+	}
+	return policy, nil
+}
+
+func matchesAnyGlob(globs []string, key string) bool {
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// PropagateKey returns whether a label or annotation with the given key
+// should be present on member cluster copies under this policy.
+func (p *PropagationPolicy) PropagateKey(key string) bool {
+	if p == nil {
+		return true
+	}
+	if matchesAnyGlob(p.Include, key) {
+		return true
+	}
+	return !matchesAnyGlob(p.Exclude, key)
+}
+
+// FilterKeys returns the given label or annotation map with the keys
+// disallowed by the policy removed. The input map is returned unmodified if
+// no key is removed.
+func (p *PropagationPolicy) FilterKeys(m map[string]string) map[string]string {
+	if p == nil || len(m) == 0 {
+		return m
+	}
+	removeNeeded := false
+	for key := range m {
+		if !p.PropagateKey(key) {
+			removeNeeded = true
+			break
+		}
+	}
+	if !removeNeeded {
+		return m
+	}
+	filtered := make(map[string]string, len(m))
+	for key, val := range m {
+		if p.PropagateKey(key) {
+			filtered[key] = val
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePropagationPolicy(t *testing.T) {
+	tests := []struct {
+		spec    string
+		include []string
+		exclude []string
+		wantErr bool
+	}{
+		{spec: "", include: nil, exclude: nil},
+		{spec: "include=a,b", include: []string{"a", "b"}},
+		{spec: "exclude=internal.example.com/*", exclude: []string{"internal.example.com/*"}},
+		{spec: "include=cost-center; exclude=internal.*, private", include: []string{"cost-center"}, exclude: []string{"internal.*", "private"}},
+		{spec: "include=a;include=b", include: []string{"a", "b"}},
+		{spec: "bogus", wantErr: true},
+		{spec: "droplist=a", wantErr: true},
+		{spec: "exclude=[", wantErr: true},
+	}
+	for _, test := range tests {
+		policy, err := ParsePropagationPolicy(test.spec)
+		if test.wantErr {
+			assert.Error(t, err, "spec %q", test.spec)
+			continue
+		}
+		assert.NoError(t, err, "spec %q", test.spec)
+		assert.Equal(t, test.include, policy.Include, "spec %q", test.spec)
+		assert.Equal(t, test.exclude, policy.Exclude, "spec %q", test.spec)
+	}
+}
+
+func TestPropagationPolicyFilterKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *PropagationPolicy
+		in     map[string]string
+		out    map[string]string
+	}{
+		{
+			name:   "nil policy passes through",
+			policy: nil,
+			in:     map[string]string{"a": "1"},
+			out:    map[string]string{"a": "1"},
+		},
+		{
+			name:   "empty policy passes through",
+			policy: &PropagationPolicy{},
+			in:     map[string]string{"a": "1"},
+			out:    map[string]string{"a": "1"},
+		},
+		{
+			name:   "exclude glob drops matching keys",
+			policy: &PropagationPolicy{Exclude: []string{"internal.example.com/*"}},
+			in:     map[string]string{"internal.example.com/tier": "x", "cost-center": "42"},
+			out:    map[string]string{"cost-center": "42"},
+		},
+		{
+			name:   "include wins over exclude",
+			policy: &PropagationPolicy{Include: []string{"internal.example.com/cost-center"}, Exclude: []string{"internal.example.com/*"}},
+			in:     map[string]string{"internal.example.com/tier": "x", "internal.example.com/cost-center": "42"},
+			out:    map[string]string{"internal.example.com/cost-center": "42"},
+		},
+		{
+			name:   "nil map stays nil",
+			policy: &PropagationPolicy{Exclude: []string{"*"}},
+			in:     nil,
+			out:    nil,
+		},
+		{
+			name:   "exclude everything",
+			policy: &PropagationPolicy{Exclude: []string{"*"}},
+			in:     map[string]string{"a": "1"},
+			out:    map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.out, test.policy.FilterKeys(test.in), "test %q", test.name)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+)
+
+// scopedLoggerBackend lets tests capture the formatted output of a
+// ScopedLogger. Nil outside tests, in which case the lines go to glog.
+var scopedLoggerBackend func(severity, message string)
+
+// ScopedLogger is a thin wrapper around glog that prefixes every line with a
+// fixed, grep-friendly context: the controller name, the federated object key
+// and the member cluster name, whichever are set. Federation controllers
+// handle many objects across many clusters; a stable "cluster=..." token on
+// every per-cluster line is what makes their logs filterable.
+//
+// Loggers are immutable: WithKey and WithCluster return derived loggers, so a
+// controller-level logger can be shared and scoped per reconciliation or per
+// cluster without synchronization.
+type ScopedLogger struct {
+	prefix string
+}
+
+// NewScopedLogger returns a logger carrying the given controller name.
+func NewScopedLogger(controller string) *ScopedLogger {
+	return &ScopedLogger{prefix: fmt.Sprintf("[controller=%s]", controller)}
+}
+
+// WithKey returns a derived logger additionally carrying the federated
+// object's namespace/name key.
+func (l *ScopedLogger) WithKey(key string) *ScopedLogger {
+	return &ScopedLogger{prefix: fmt.Sprintf("%s[key=%s]", l.prefix, key)}
+}
+
+// WithCluster returns a derived logger additionally carrying the member
+// cluster name.
+func (l *ScopedLogger) WithCluster(cluster string) *ScopedLogger {
+	return &ScopedLogger{prefix: fmt.Sprintf("%s[cluster=%s]", l.prefix, cluster)}
+}
+
+// V returns a leveled logger whose Infof is a no-op unless glog runs at the
+// given verbosity, mirroring glog.V so existing -v settings keep selecting
+// the same lines after a controller converts to scoped logging.
+func (l *ScopedLogger) V(level glog.Level) ScopedVerbose {
+	return ScopedVerbose{enabled: bool(glog.V(level)), prefix: l.prefix}
+}
+
+// Infof logs an info line with the logger's context prefix.
+func (l *ScopedLogger) Infof(format string, args ...interface{}) {
+	line := l.prefix + " " + fmt.Sprintf(format, args...)
+	if scopedLoggerBackend != nil {
+		scopedLoggerBackend("INFO", line)
+		return
+	}
+	glog.InfoDepth(1, line)
+}
+
+// Warningf logs a warning line with the logger's context prefix.
+func (l *ScopedLogger) Warningf(format string, args ...interface{}) {
+	line := l.prefix + " " + fmt.Sprintf(format, args...)
+	if scopedLoggerBackend != nil {
+		scopedLoggerBackend("WARNING", line)
+		return
+	}
+	glog.WarningDepth(1, line)
+}
+
+// Errorf logs an error line with the logger's context prefix.
+func (l *ScopedLogger) Errorf(format string, args ...interface{}) {
+	line := l.prefix + " " + fmt.Sprintf(format, args...)
+	if scopedLoggerBackend != nil {
+		scopedLoggerBackend("ERROR", line)
+		return
+	}
+	glog.ErrorDepth(1, line)
+}
+
+// ScopedVerbose is the leveled counterpart of ScopedLogger, returned by V.
+type ScopedVerbose struct {
+	enabled bool
+	prefix  string
+}
+
+// Infof logs an info line with the context prefix if the verbosity level
+// passed to V was enabled.
+func (v ScopedVerbose) Infof(format string, args ...interface{}) {
+	if !v.enabled {
+		return
+	}
+	line := v.prefix + " " + fmt.Sprintf(format, args...)
+	if scopedLoggerBackend != nil {
+		scopedLoggerBackend("INFO", line)
+		return
+	}
+	glog.InfoDepth(1, line)
+}
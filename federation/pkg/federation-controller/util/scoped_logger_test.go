@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// captureScopedLogs redirects ScopedLogger output into the returned slice
+// until the returned restore function is called.
+func captureScopedLogs() (*[]string, func()) {
+	lines := &[]string{}
+	scopedLoggerBackend = func(severity, message string) {
+		*lines = append(*lines, severity+" "+message)
+	}
+	return lines, func() { scopedLoggerBackend = nil }
+}
+
+// Checks that per-cluster error lines carry all three context tokens, so
+// grepping for a cluster or an object key finds every line about it.
+func TestScopedLoggerContext(t *testing.T) {
+	lines, restore := captureScopedLogs()
+	defer restore()
+
+	logger := NewScopedLogger("ingress")
+	logger.WithKey("mynamespace/test-ingress").WithCluster("cluster1").Errorf("update failed: %v", "some error")
+
+	if !assert.Len(t, *lines, 1) {
+		return
+	}
+	line := (*lines)[0]
+	assert.True(t, strings.HasPrefix(line, "ERROR "), "unexpected severity: %s", line)
+	assert.Contains(t, line, "[controller=ingress]")
+	assert.Contains(t, line, "[key=mynamespace/test-ingress]")
+	assert.Contains(t, line, "[cluster=cluster1]")
+	assert.Contains(t, line, "update failed: some error")
+}
+
+// Checks that derived loggers do not mutate their parent, so one
+// controller-level logger can be scoped per cluster concurrently.
+func TestScopedLoggerDerivation(t *testing.T) {
+	lines, restore := captureScopedLogs()
+	defer restore()
+
+	logger := NewScopedLogger("ingress").WithKey("mynamespace/test-ingress")
+	logger.WithCluster("cluster1")
+	logger.Infof("no cluster scope here")
+
+	if assert.Len(t, *lines, 1) {
+		assert.NotContains(t, (*lines)[0], "cluster=", "WithCluster leaked into the parent logger")
+	}
+}
+
+// Checks that V gates output exactly like glog.V: at the default verbosity a
+// V(4) line is dropped while V(0) goes through, so operators' -v settings
+// keep selecting the same lines.
+func TestScopedLoggerVerbosity(t *testing.T) {
+	lines, restore := captureScopedLogs()
+	defer restore()
+
+	logger := NewScopedLogger("ingress").WithCluster("cluster1")
+	logger.V(4).Infof("verbose line")
+	assert.Len(t, *lines, 0, "a V(4) line was logged at the default verbosity")
+
+	logger.V(0).Infof("always-on line")
+	if assert.Len(t, *lines, 1) {
+		assert.Contains(t, (*lines)[0], "always-on line")
+	}
+}
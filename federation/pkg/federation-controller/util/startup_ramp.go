@@ -0,0 +1,67 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util/clock"
+)
+
+// StartupRamp spreads a controller's initial reconciliation backlog over time
+// so that member clusters are not slammed when the controller cold-starts
+// with thousands of objects. Each call to NextDelay reserves the next free
+// delivery slot, spaced so that at most maxKeysPerSecond keys are released
+// per second. Watch-derived deliveries should not be passed through the ramp.
+//
+// A nil *StartupRamp is valid and never delays.
+type StartupRamp struct {
+	interval time.Duration
+	clock    clock.Clock
+
+	lock sync.Mutex
+	// next is the time of the next free delivery slot.
+	next time.Time
+}
+
+// NewStartupRamp creates a ramp releasing at most maxKeysPerSecond keys per
+// second, measured against the given clock.
+func NewStartupRamp(maxKeysPerSecond int, cl clock.Clock) *StartupRamp {
+	return &StartupRamp{
+		interval: time.Second / time.Duration(maxKeysPerSecond),
+		clock:    cl,
+	}
+}
+
+// NextDelay reserves the next delivery slot and returns how long the caller
+// must wait for it. Slots in the past are free immediately, so a trickle of
+// keys slower than the configured rate is never delayed.
+func (r *StartupRamp) NextDelay() time.Duration {
+	if r == nil {
+		return 0
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.clock.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	return delay
+}
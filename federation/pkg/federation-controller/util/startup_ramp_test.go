@@ -0,0 +1,46 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupRamp(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	ramp := NewStartupRamp(10, fakeClock)
+
+	// A burst of keys is spaced at the configured rate.
+	assert.Equal(t, time.Duration(0), ramp.NextDelay())
+	assert.Equal(t, 100*time.Millisecond, ramp.NextDelay())
+	assert.Equal(t, 200*time.Millisecond, ramp.NextDelay())
+
+	// Once time passes, slots that fell into the past are free again.
+	fakeClock.Step(250 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, ramp.NextDelay())
+	fakeClock.Step(time.Second)
+	assert.Equal(t, time.Duration(0), ramp.NextDelay())
+
+	// A nil ramp never delays.
+	var noRamp *StartupRamp
+	assert.Equal(t, time.Duration(0), noRamp.NextDelay())
+}
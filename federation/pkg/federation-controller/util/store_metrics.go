@@ -0,0 +1,123 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/client/cache"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	clusterStoreObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_cluster_store_objects",
+			Help: "Number of objects of the given resource currently cached for the given member cluster.",
+		},
+		[]string{"cluster", "resource"},
+	)
+	clusterWatchLag = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_cluster_watch_lag_seconds",
+			Help: "Approximate watch propagation lag for the given member cluster and resource, " +
+				"computed as the time between consecutive watch deliveries. It is only an " +
+				"approximation as the member cluster api servers do not expose event timestamps.",
+		},
+		[]string{"cluster", "resource"},
+	)
+	clusterStorePollOnly = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "federation_cluster_store_poll_only",
+			Help: "Whether the store of the given resource for the given member cluster is in " +
+				"poll-only mode (1) because the cluster forbids the watch verb, or watching " +
+				"normally (0).",
+		},
+		[]string{"cluster", "resource"},
+	)
+	registerStoreMetricsOnce sync.Once
+)
+
+func registerStoreMetrics() {
+	registerStoreMetricsOnce.Do(func() {
+		prometheus.MustRegister(clusterStoreObjects)
+		prometheus.MustRegister(clusterWatchLag)
+		prometheus.MustRegister(clusterStorePollOnly)
+	})
+}
+
+// Tracks the time of the last watch delivery for a single (cluster, resource) pair.
+type storeInstrumentation struct {
+	sync.Mutex
+	cluster   string
+	resource  string
+	lastEvent time.Time
+}
+
+// Records a watch delivery at the given time and updates the lag gauge with the
+// time elapsed since the previous delivery.
+func (si *storeInstrumentation) observeEvent(now time.Time) {
+	si.Lock()
+	defer si.Unlock()
+	if !si.lastEvent.IsZero() {
+		clusterWatchLag.WithLabelValues(si.cluster, si.resource).Set(now.Sub(si.lastEvent).Seconds())
+	}
+	si.lastEvent = now
+}
+
+// InstrumentResourceEventHandlers wraps the given resource event handlers so that
+// federation_cluster_store_objects and federation_cluster_watch_lag_seconds are
+// maintained for the given cluster and resource. Intended to be used inside
+// TargetInformerFactory implementations so that every add/update/delete flowing
+// into a per-cluster store is accounted for.
+func InstrumentResourceEventHandlers(resource, clusterName string, delegate *cache.ResourceEventHandlerFuncs) *cache.ResourceEventHandlerFuncs {
+	registerStoreMetrics()
+	instrumentation := &storeInstrumentation{cluster: clusterName, resource: resource}
+	return &cache.ResourceEventHandlerFuncs{
+		AddFunc: func(cur interface{}) {
+			instrumentation.observeEvent(time.Now())
+			clusterStoreObjects.WithLabelValues(clusterName, resource).Inc()
+			if delegate.AddFunc != nil {
+				delegate.AddFunc(cur)
+			}
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			instrumentation.observeEvent(time.Now())
+			if delegate.UpdateFunc != nil {
+				delegate.UpdateFunc(old, cur)
+			}
+		},
+		DeleteFunc: func(old interface{}) {
+			instrumentation.observeEvent(time.Now())
+			clusterStoreObjects.WithLabelValues(clusterName, resource).Dec()
+			if delegate.DeleteFunc != nil {
+				delegate.DeleteFunc(old)
+			}
+		},
+	}
+}
+
+// DeleteStoreMetricsForCluster removes the store metrics for the given cluster and
+// resource. Should be called when a cluster is removed from the federation so that
+// stale gauges are not exported forever.
+func DeleteStoreMetricsForCluster(resource, clusterName string) {
+	clusterStoreObjects.DeleteLabelValues(clusterName, resource)
+	clusterWatchLag.DeleteLabelValues(clusterName, resource)
+	clusterStorePollOnly.DeleteLabelValues(clusterName, resource)
+}
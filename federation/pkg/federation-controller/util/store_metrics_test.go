@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/cache"
+	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kubernetes/pkg/client/testing/core"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/watch"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func gaugeValue(t *testing.T, vec *prometheus.GaugeVec, labels ...string) float64 {
+	gauge, err := vec.GetMetricWithLabelValues(labels...)
+	assert.NoError(t, err)
+	metric := &dto.Metric{}
+	assert.NoError(t, gauge.Write(metric))
+	return metric.GetGauge().GetValue()
+}
+
+// Checks that the object count gauge follows adds and deletes delivered via
+// a fake watch.
+func TestStoreMetrics(t *testing.T) {
+	fakeClient := &fakekubeclientset.Clientset{}
+	fakeClient.AddReactor("list", "services", func(action core.Action) (bool, runtime.Object, error) {
+		return true, &apiv1.ServiceList{Items: []apiv1.Service{}}, nil
+	})
+	fakeWatch := watch.NewRaceFreeFake()
+	fakeClient.AddWatchReactor("services", func(action core.Action) (bool, watch.Interface, error) {
+		return true, fakeWatch, nil
+	})
+
+	_, controller := cache.NewInformer(
+		&cache.ListWatch{
+			ListFunc: func(options apiv1.ListOptions) (runtime.Object, error) {
+				return fakeClient.Core().Services(apiv1.NamespaceAll).List(options)
+			},
+			WatchFunc: func(options apiv1.ListOptions) (watch.Interface, error) {
+				return fakeClient.Core().Services(apiv1.NamespaceAll).Watch(options)
+			},
+		},
+		&apiv1.Service{},
+		10*time.Second,
+		InstrumentResourceEventHandlers("services", "mycluster", &cache.ResourceEventHandlerFuncs{}))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go controller.Run(stop)
+	for !controller.HasSynced() {
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	fakeWatch.Add(makeService("mycluster", "s1"))
+	fakeWatch.Add(makeService("mycluster", "s2"))
+	waitForGaugeValue(t, clusterStoreObjects, 2, "mycluster", "services")
+
+	fakeWatch.Delete(makeService("mycluster", "s1"))
+	waitForGaugeValue(t, clusterStoreObjects, 1, "mycluster", "services")
+
+	// Two events have been delivered since sync so the lag gauge must be set.
+	gauge, err := clusterWatchLag.GetMetricWithLabelValues("mycluster", "services")
+	assert.NoError(t, err)
+	metric := &dto.Metric{}
+	assert.NoError(t, gauge.Write(metric))
+	assert.True(t, metric.GetGauge().GetValue() >= 0)
+
+	DeleteStoreMetricsForCluster("services", "mycluster")
+	assert.Equal(t, float64(0), gaugeValue(t, clusterStoreObjects, "mycluster", "services"))
+}
+
+func waitForGaugeValue(t *testing.T, vec *prometheus.GaugeVec, expected float64, labels ...string) {
+	for start := time.Now(); time.Since(start) < wait.ForeverTestTimeout; time.Sleep(50 * time.Millisecond) {
+		if gaugeValue(t, vec, labels...) == expected {
+			return
+		}
+	}
+	t.Errorf("gauge %v did not reach value %v", labels, expected)
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/meta"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/diff"
+
+	"github.com/ghodss/yaml"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden testdata files instead of failing on a mismatch")
+
+// ScrubForGolden clears server-set and otherwise nondeterministic metadata
+// fields (UID, resource version, self link, timestamps) on the given object,
+// in place, so that its serialization is stable across test runs.
+func ScrubForGolden(obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	accessor.SetUID("")
+	accessor.SetResourceVersion("")
+	accessor.SetSelfLink("")
+	accessor.SetCreationTimestamp(metav1.Time{})
+	accessor.SetDeletionTimestamp(nil)
+	return nil
+}
+
+// AssertMatchesGolden serializes the given object to canonical YAML (keys
+// sorted, nondeterministic fields scrubbed) and compares it against the
+// golden file at goldenPath, relative to the test's package directory
+// (conventionally "testdata/<name>.yaml"). Running the tests with -update
+// rewrites the golden file from the object instead of comparing, so intended
+// changes to propagated objects are reviewed as testdata diffs. The object is
+// deep-copied before scrubbing.
+func AssertMatchesGolden(t *testing.T, goldenPath string, obj runtime.Object) {
+	objCopy, err := api.Scheme.DeepCopy(obj)
+	if err != nil {
+		t.Errorf("Failed to copy object for golden comparison with %s: %v", goldenPath, err)
+		return
+	}
+	scrubbed := objCopy.(runtime.Object)
+	if err := ScrubForGolden(scrubbed); err != nil {
+		t.Errorf("Failed to scrub object for golden comparison with %s: %v", goldenPath, err)
+		return
+	}
+	serialized, err := yaml.Marshal(scrubbed)
+	if err != nil {
+		t.Errorf("Failed to serialize object for golden comparison with %s: %v", goldenPath, err)
+		return
+	}
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Errorf("Failed to create the golden file directory for %s: %v", goldenPath, err)
+			return
+		}
+		if err := ioutil.WriteFile(goldenPath, serialized, 0644); err != nil {
+			t.Errorf("Failed to update golden file %s: %v", goldenPath, err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Errorf("Failed to read golden file %s (re-run with -update to create it): %v", goldenPath, err)
+		return
+	}
+	if string(expected) != string(serialized) {
+		t.Errorf("Object does not match golden file %s (re-run with -update to accept the change):\n%s",
+			goldenPath, diff.StringDiff(string(expected), string(serialized)))
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	"k8s.io/kubernetes/pkg/util/wait"
+)
+
+const (
+	// pollInterval is how often the readiness waiters re-check the federated
+	// and cluster objects. Coarse enough for real clusters, short enough for
+	// the fake clientsets used in unit tests.
+	pollInterval = 2 * time.Second
+)
+
+// ClusterIngressSpecMatches returns true if the cluster copy of an ingress
+// carries the spec the federated ingress prescribes. It compares specs only:
+// metadata and status legitimately differ between the federation control plane
+// and member clusters.
+func ClusterIngressSpecMatches(federatedIngress, clusterIngress *extensionsv1beta1.Ingress) bool {
+	if federatedIngress == nil || clusterIngress == nil {
+		return false
+	}
+	return reflect.DeepEqual(federatedIngress.Spec, clusterIngress.Spec)
+}
+
+// WaitForFederatedIngressReady polls until the named federated ingress is
+// ready: its status reports at least one loadbalancer address and every given
+// cluster has a copy whose spec matches the federated spec. It returns the
+// first reported address on success.
+func WaitForFederatedIngressReady(fedClient federationclientset.Interface, clusterClients map[string]kubeclientset.Interface, ns, name string, timeout time.Duration) (string, error) {
+	var address string
+	err := wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		federatedIngress, err := fedClient.Extensions().Ingresses(ns).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		lbIngresses := federatedIngress.Status.LoadBalancer.Ingress
+		if len(lbIngresses) == 0 {
+			return false, nil
+		}
+		if lbIngresses[0].IP != "" {
+			address = lbIngresses[0].IP
+		} else {
+			address = lbIngresses[0].Hostname
+		}
+		if address == "" {
+			return false, nil
+		}
+		for _, clusterClient := range clusterClients {
+			clusterIngress, err := clusterClient.Extensions().Ingresses(ns).Get(name, metav1.GetOptions{})
+			if err != nil || !ClusterIngressSpecMatches(federatedIngress, clusterIngress) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("federated ingress %s/%s not ready within %v: %v", ns, name, timeout, err)
+	}
+	return address, nil
+}
+
+// WaitForFederatedIngressServing additionally requires the address reported by
+// the federated ingress to answer an HTTP GET with status 200. The httpClient
+// controls per-request timeouts and TLS behaviour.
+func WaitForFederatedIngressServing(fedClient federationclientset.Interface, clusterClients map[string]kubeclientset.Interface, ns, name string, timeout time.Duration, httpClient *http.Client) (string, error) {
+	address, err := WaitForFederatedIngressReady(fedClient, clusterClients, ns, name, timeout)
+	if err != nil {
+		return "", err
+	}
+	err = wait.PollImmediate(pollInterval, timeout, func() (bool, error) {
+		response, err := httpClient.Get(fmt.Sprintf("http://%s/", address))
+		if err != nil {
+			return false, nil
+		}
+		defer response.Body.Close()
+		return response.StatusCode == http.StatusOK, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("federated ingress %s/%s at %s not serving HTTP 200 within %v: %v", ns, name, address, timeout, err)
+	}
+	return address, nil
+}
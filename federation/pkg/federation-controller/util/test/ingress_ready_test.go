@@ -0,0 +1,68 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutil
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterIngressSpecMatches(t *testing.T) {
+	federatedIngress := &extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "test-ingress",
+			Namespace: "mynamespace",
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{
+				ServiceName: "testsvc",
+			},
+		},
+	}
+
+	// The cluster copy matches despite different metadata and status.
+	clusterIngress := &extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:        "test-ingress",
+			Namespace:   "mynamespace",
+			Annotations: map[string]string{"some-cluster": "annotation"},
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{
+				ServiceName: "testsvc",
+			},
+		},
+		Status: extensionsv1beta1.IngressStatus{
+			LoadBalancer: apiv1.LoadBalancerStatus{
+				Ingress: []apiv1.LoadBalancerIngress{{IP: "1.2.3.4"}},
+			},
+		},
+	}
+	assert.True(t, ClusterIngressSpecMatches(federatedIngress, clusterIngress))
+
+	// A spec difference makes the copy out of sync.
+	clusterIngress.Spec.Backend.ServiceName = "othersvc"
+	assert.False(t, ClusterIngressSpecMatches(federatedIngress, clusterIngress))
+
+	// Missing objects never match.
+	assert.False(t, ClusterIngressSpecMatches(federatedIngress, nil))
+	assert.False(t, ClusterIngressSpecMatches(nil, clusterIngress))
+}
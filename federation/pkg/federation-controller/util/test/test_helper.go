@@ -18,10 +18,12 @@ package testutil
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
 	"reflect"
 	"runtime/pprof"
 	"sync"
+	"testing"
 	"time"
 
 	federationapi "k8s.io/kubernetes/federation/apis/federation/v1beta1"
@@ -38,6 +40,10 @@ import (
 
 const (
 	pushTimeout = 5 * time.Second
+
+	// ChaosMaxDelay is the default delivery randomization window for
+	// dispatchers with chaos mode enabled.
+	ChaosMaxDelay = 100 * time.Millisecond
 )
 
 // A structure that distributes eventes to multiple watchers.
@@ -47,6 +53,44 @@ type WatcherDispatcher struct {
 	eventsSoFar    []*watch.Event
 	orderExecution chan func()
 	stopChan       chan struct{}
+	chaos          *chaosConfig
+}
+
+// chaosConfig randomizes watch delivery timing to shake out event ordering
+// bugs. Guarded by its own mutex since rand.Rand is not goroutine safe.
+type chaosConfig struct {
+	sync.Mutex
+	rand     *rand.Rand
+	maxDelay time.Duration
+}
+
+func (c *chaosConfig) nextDelay() time.Duration {
+	c.Lock()
+	defer c.Unlock()
+	return time.Duration(c.rand.Int63n(int64(c.maxDelay)))
+}
+
+// EnableChaos makes the dispatcher deliver every subsequent event after a
+// random delay of up to maxDelay, drawn from a generator seeded with seed.
+// Deliveries whose delays overlap are reordered, approximating the event
+// interleavings of a busy live cluster. Enable before sending any events.
+func (wd *WatcherDispatcher) EnableChaos(seed int64, maxDelay time.Duration) {
+	wd.Lock()
+	defer wd.Unlock()
+	wd.chaos = &chaosConfig{rand: rand.New(rand.NewSource(seed)), maxDelay: maxDelay}
+}
+
+// scheduleDelivery runs deliver immediately, or after a random delay within
+// the chaos window when chaos mode is enabled.
+func (wd *WatcherDispatcher) scheduleDelivery(deliver func()) {
+	wd.Lock()
+	chaos := wd.chaos
+	wd.Unlock()
+	if chaos == nil {
+		deliver()
+		return
+	}
+	time.AfterFunc(chaos.nextDelay(), deliver)
 }
 
 func (wd *WatcherDispatcher) register(watcher *watch.RaceFreeFakeWatcher) {
@@ -78,66 +122,81 @@ func copy(obj runtime.Object) runtime.Object {
 
 // Add sends an add event.
 func (wd *WatcherDispatcher) Add(obj runtime.Object) {
-	wd.Lock()
-	defer wd.Unlock()
-	wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Added, Object: copy(obj)})
-	for _, watcher := range wd.watchers {
-		if !watcher.IsStopped() {
-			watcher.Add(copy(obj))
+	obj = copy(obj)
+	wd.scheduleDelivery(func() {
+		wd.Lock()
+		defer wd.Unlock()
+		wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Added, Object: obj})
+		for _, watcher := range wd.watchers {
+			if !watcher.IsStopped() {
+				watcher.Add(copy(obj))
+			}
 		}
-	}
+	})
 }
 
 // Modify sends a modify event.
 func (wd *WatcherDispatcher) Modify(obj runtime.Object) {
-	wd.Lock()
-	defer wd.Unlock()
-	glog.V(4).Infof("->WatcherDispatcher.Modify(%v)", obj)
-	wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Modified, Object: copy(obj)})
-	for i, watcher := range wd.watchers {
-		if !watcher.IsStopped() {
-			glog.V(4).Infof("->Watcher(%d).Modify(%v)", i, obj)
-			watcher.Modify(copy(obj))
-		} else {
-			glog.V(4).Infof("->Watcher(%d) is stopped.  Not calling Modify(%v)", i, obj)
+	obj = copy(obj)
+	wd.scheduleDelivery(func() {
+		wd.Lock()
+		defer wd.Unlock()
+		glog.V(4).Infof("->WatcherDispatcher.Modify(%v)", obj)
+		wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Modified, Object: obj})
+		for i, watcher := range wd.watchers {
+			if !watcher.IsStopped() {
+				glog.V(4).Infof("->Watcher(%d).Modify(%v)", i, obj)
+				watcher.Modify(copy(obj))
+			} else {
+				glog.V(4).Infof("->Watcher(%d) is stopped.  Not calling Modify(%v)", i, obj)
+			}
 		}
-	}
+	})
 }
 
 // Delete sends a delete event.
 func (wd *WatcherDispatcher) Delete(lastValue runtime.Object) {
-	wd.Lock()
-	defer wd.Unlock()
-	wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Deleted, Object: copy(lastValue)})
-	for _, watcher := range wd.watchers {
-		if !watcher.IsStopped() {
-			watcher.Delete(copy(lastValue))
+	lastValue = copy(lastValue)
+	wd.scheduleDelivery(func() {
+		wd.Lock()
+		defer wd.Unlock()
+		wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Deleted, Object: lastValue})
+		for _, watcher := range wd.watchers {
+			if !watcher.IsStopped() {
+				watcher.Delete(copy(lastValue))
+			}
 		}
-	}
+	})
 }
 
 // Error sends an Error event.
 func (wd *WatcherDispatcher) Error(errValue runtime.Object) {
-	wd.Lock()
-	defer wd.Unlock()
-	wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Error, Object: copy(errValue)})
-	for _, watcher := range wd.watchers {
-		if !watcher.IsStopped() {
-			watcher.Error(copy(errValue))
+	errValue = copy(errValue)
+	wd.scheduleDelivery(func() {
+		wd.Lock()
+		defer wd.Unlock()
+		wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: watch.Error, Object: errValue})
+		for _, watcher := range wd.watchers {
+			if !watcher.IsStopped() {
+				watcher.Error(copy(errValue))
+			}
 		}
-	}
+	})
 }
 
 // Action sends an event of the requested type, for table-based testing.
 func (wd *WatcherDispatcher) Action(action watch.EventType, obj runtime.Object) {
-	wd.Lock()
-	defer wd.Unlock()
-	wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: action, Object: copy(obj)})
-	for _, watcher := range wd.watchers {
-		if !watcher.IsStopped() {
-			watcher.Action(action, copy(obj))
+	obj = copy(obj)
+	wd.scheduleDelivery(func() {
+		wd.Lock()
+		defer wd.Unlock()
+		wd.eventsSoFar = append(wd.eventsSoFar, &watch.Event{Type: action, Object: obj})
+		for _, watcher := range wd.watchers {
+			if !watcher.IsStopped() {
+				watcher.Action(action, copy(obj))
+			}
 		}
-	}
+	})
 }
 
 // RegisterFakeWatch adds a new fake watcher for the specified resource in the given fake client.
@@ -176,31 +235,28 @@ func RegisterFakeList(resource string, client *core.Fake, obj runtime.Object) {
 	})
 }
 
-// RegisterFakeCopyOnCreate registers a reactor in the given fake client that passes
-// all created objects to the given watcher and also copies them to a channel for
-// in-test inspection.
-func RegisterFakeCopyOnCreate(resource string, client *core.Fake, watcher *WatcherDispatcher) chan runtime.Object {
-	objChan := make(chan runtime.Object, 100)
+// registerCopyOnCreateReactor registers a reactor in the given fake client that
+// passes all created objects to the given watcher and also hands a copy of each
+// to the given deliver function for in-test inspection.
+func registerCopyOnCreateReactor(resource string, client *core.Fake, watcher *WatcherDispatcher, deliver func(runtime.Object)) {
 	client.AddReactor("create", resource, func(action core.Action) (bool, runtime.Object, error) {
 		createAction := action.(core.CreateAction)
 		originalObj := createAction.GetObject()
 		// Create a copy of the object here to prevent data races while reading the object in go routine.
 		obj := copy(originalObj)
 		watcher.orderExecution <- func() {
-			glog.V(4).Infof("Object created. Writing to channel: %v", obj)
+			glog.V(4).Infof("Object created. Delivering: %v", obj)
 			watcher.Add(obj)
-			objChan <- obj
+			deliver(obj)
 		}
 		return true, originalObj, nil
 	})
-	return objChan
 }
 
-// RegisterFakeCopyOnUpdate registers a reactor in the given fake client that passes
-// all updated objects to the given watcher and also copies them to a channel for
-// in-test inspection.
-func RegisterFakeCopyOnUpdate(resource string, client *core.Fake, watcher *WatcherDispatcher) chan runtime.Object {
-	objChan := make(chan runtime.Object, 100)
+// registerCopyOnUpdateReactor registers a reactor in the given fake client that
+// passes all updated objects to the given watcher and also hands a copy of each
+// to the given deliver function for in-test inspection.
+func registerCopyOnUpdateReactor(resource string, client *core.Fake, watcher *WatcherDispatcher, deliver func(runtime.Object)) {
 	client.AddReactor("update", resource, func(action core.Action) (bool, runtime.Object, error) {
 		updateAction := action.(core.UpdateAction)
 		originalObj := updateAction.GetObject()
@@ -209,9 +265,9 @@ func RegisterFakeCopyOnUpdate(resource string, client *core.Fake, watcher *Watch
 		// Create a copy of the object here to prevent data races while reading the object in go routine.
 		obj := copy(originalObj)
 		operation := func() {
-			glog.V(4).Infof("Object updated. Writing to channel: %v", obj)
+			glog.V(4).Infof("Object updated. Delivering: %v", obj)
 			watcher.Modify(obj)
-			objChan <- obj
+			deliver(obj)
 		}
 		select {
 		case watcher.orderExecution <- operation:
@@ -222,9 +278,149 @@ func RegisterFakeCopyOnUpdate(resource string, client *core.Fake, watcher *Watch
 		}
 		return true, originalObj, nil
 	})
+}
+
+// RegisterFakeCopyOnCreate registers a reactor in the given fake client that passes
+// all created objects to the given watcher and also copies them to a channel for
+// in-test inspection.
+func RegisterFakeCopyOnCreate(resource string, client *core.Fake, watcher *WatcherDispatcher) chan runtime.Object {
+	objChan := make(chan runtime.Object, 100)
+	registerCopyOnCreateReactor(resource, client, watcher, func(obj runtime.Object) {
+		objChan <- obj
+	})
+	return objChan
+}
+
+// RegisterFakeCopyOnUpdate registers a reactor in the given fake client that passes
+// all updated objects to the given watcher and also copies them to a channel for
+// in-test inspection.
+func RegisterFakeCopyOnUpdate(resource string, client *core.Fake, watcher *WatcherDispatcher) chan runtime.Object {
+	objChan := make(chan runtime.Object, 100)
+	registerCopyOnUpdateReactor(resource, client, watcher, func(obj runtime.Object) {
+		objChan <- obj
+	})
 	return objChan
 }
 
+// RegisterFakeCopyOnCreateRecorder is like RegisterFakeCopyOnCreate, but records
+// the created objects in an ObjectRecorder instead of a raw channel.
+func RegisterFakeCopyOnCreateRecorder(resource string, client *core.Fake, watcher *WatcherDispatcher) *ObjectRecorder {
+	recorder := newObjectRecorder()
+	registerCopyOnCreateReactor(resource, client, watcher, recorder.record)
+	return recorder
+}
+
+// RegisterFakeCopyOnUpdateRecorder is like RegisterFakeCopyOnUpdate, but records
+// the updated objects in an ObjectRecorder instead of a raw channel.
+func RegisterFakeCopyOnUpdateRecorder(resource string, client *core.Fake, watcher *WatcherDispatcher) *ObjectRecorder {
+	recorder := newObjectRecorder()
+	registerCopyOnUpdateReactor(resource, client, watcher, recorder.record)
+	return recorder
+}
+
+// ObjectRecorder accumulates the objects written through a fake client reactor.
+// Unlike the raw channels returned by RegisterFakeCopyOnCreate and
+// RegisterFakeCopyOnUpdate it can be inspected without consuming, and drained
+// between subtests that share a fixture, so that objects left over from an
+// earlier subtest do not poison later assertions.
+type ObjectRecorder struct {
+	lock    sync.Mutex
+	objects []runtime.Object
+	changed chan struct{}
+}
+
+func newObjectRecorder() *ObjectRecorder {
+	return &ObjectRecorder{changed: make(chan struct{}, 1)}
+}
+
+func (r *ObjectRecorder) record(obj runtime.Object) {
+	r.lock.Lock()
+	r.objects = append(r.objects, obj)
+	r.lock.Unlock()
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the number of recorded objects that have not been consumed yet.
+func (r *ObjectRecorder) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.objects)
+}
+
+// Peek returns the oldest unconsumed object without consuming it, or nil when
+// there is none.
+func (r *ObjectRecorder) Peek() runtime.Object {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.objects) == 0 {
+		return nil
+	}
+	return r.objects[0]
+}
+
+// Snapshot returns a copy of the unconsumed objects, oldest first, without
+// consuming them.
+func (r *ObjectRecorder) Snapshot() []runtime.Object {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	snapshot := make([]runtime.Object, len(r.objects))
+	for i, obj := range r.objects {
+		snapshot[i] = obj
+	}
+	return snapshot
+}
+
+// Drain consumes and returns all recorded objects, oldest first.
+func (r *ObjectRecorder) Drain() []runtime.Object {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	drained := r.objects
+	r.objects = nil
+	return drained
+}
+
+// Next consumes and returns the oldest recorded object, waiting up to the
+// usual test timeout for one to arrive. Like GetObjectFromChan it returns nil
+// on timeout.
+func (r *ObjectRecorder) Next() runtime.Object {
+	deadline := time.After(wait.ForeverTestTimeout)
+	for {
+		r.lock.Lock()
+		if len(r.objects) > 0 {
+			obj := r.objects[0]
+			r.objects = r.objects[1:]
+			r.lock.Unlock()
+			return obj
+		}
+		r.lock.Unlock()
+		select {
+		case <-r.changed:
+		case <-deadline:
+			pprof.Lookup("goroutine").WriteTo(os.Stderr, 1)
+			return nil
+		}
+	}
+}
+
+// DrainChannel consumes and returns the objects currently buffered in the
+// given channel without blocking. Subtests that read from a channel shared
+// with earlier subtests should drain it before triggering the events they
+// assert on.
+func DrainChannel(c chan runtime.Object) []runtime.Object {
+	drained := []runtime.Object{}
+	for {
+		select {
+		case obj := <-c:
+			drained = append(drained, obj)
+		default:
+			return drained
+		}
+	}
+}
+
 // GetObjectFromChan tries to get an api object from the given channel
 // within a reasonable time.
 func GetObjectFromChan(c chan runtime.Object) runtime.Object {
@@ -280,6 +476,19 @@ func CompareObjectMeta(a, b apiv1.ObjectMeta) error {
 	return nil
 }
 
+// RunWithSeeds runs the given scenario once per seed as a subtest. Meant for
+// chaos-style tests that replay a controller scenario under several random
+// event timings (see WatcherDispatcher.EnableChaos) and assert convergence
+// invariants that must hold regardless of the interleaving.
+func RunWithSeeds(t *testing.T, seeds []int64, scenario func(t *testing.T, seed int64)) {
+	for _, seed := range seeds {
+		seed := seed
+		t.Run(fmt.Sprintf("seed-%d", seed), func(t *testing.T) {
+			scenario(t, seed)
+		})
+	}
+}
+
 func ToFederatedInformerForTestOnly(informer util.FederatedInformer) util.FederatedInformerForTestOnly {
 	inter := informer.(interface{})
 	return inter.(util.FederatedInformerForTestOnly)
@@ -0,0 +1,223 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/cache"
+	pkgruntime "k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/watch"
+
+	"github.com/golang/glog"
+)
+
+// watchFallbackListerWatcher wraps the ListerWatcher of a per-cluster
+// informer for member clusters whose federation credentials may not be
+// allowed to use the watch verb. When a watch attempt is rejected with
+// Forbidden, the cluster's store is switched to a poll-only mode: a poller
+// relists at the configured interval and synthesizes add/update/delete
+// events from the difference between consecutive lists, so the reflector
+// (and therefore every controller reading the store) keeps working, just
+// with higher latency. Every poll cycle re-probes the watch verb, so the
+// cluster reverts to normal watching automatically once the restriction is
+// lifted. Mode flips are reported through the onModeChange callback and the
+// federation_cluster_store_poll_only gauge.
+type watchFallbackListerWatcher struct {
+	resource     string
+	clusterName  string
+	delegate     cache.ListerWatcher
+	pollInterval time.Duration
+	onModeChange func(pollOnly bool)
+
+	lock     sync.Mutex
+	pollOnly bool
+}
+
+// NewWatchFallbackListerWatcher wraps the given ListerWatcher so that
+// Forbidden watch responses from the member cluster degrade the informer to
+// poll-only mode instead of a relist loop with errors. onModeChange, if
+// non-nil, is invoked whenever the mode flips; resource and clusterName are
+// only used for logging and metrics.
+func NewWatchFallbackListerWatcher(resource, clusterName string, delegate cache.ListerWatcher, pollInterval time.Duration, onModeChange func(pollOnly bool)) cache.ListerWatcher {
+	registerStoreMetrics()
+	return &watchFallbackListerWatcher{
+		resource:     resource,
+		clusterName:  clusterName,
+		delegate:     delegate,
+		pollInterval: pollInterval,
+		onModeChange: onModeChange,
+	}
+}
+
+func (w *watchFallbackListerWatcher) List(options apiv1.ListOptions) (pkgruntime.Object, error) {
+	return w.delegate.List(options)
+}
+
+func (w *watchFallbackListerWatcher) Watch(options apiv1.ListOptions) (watch.Interface, error) {
+	watcher, err := w.delegate.Watch(options)
+	if err == nil {
+		w.setPollOnly(false)
+		return watcher, nil
+	}
+	if !errors.IsForbidden(err) {
+		return nil, err
+	}
+	w.setPollOnly(true)
+	poller := &pollWatcher{
+		parent: w,
+		result: make(chan watch.Event),
+		stop:   make(chan struct{}),
+	}
+	go poller.run()
+	return poller, nil
+}
+
+// setPollOnly records the current mode, updating the metric and firing the
+// callback only when the mode actually flips.
+func (w *watchFallbackListerWatcher) setPollOnly(pollOnly bool) {
+	w.lock.Lock()
+	changed := w.pollOnly != pollOnly
+	w.pollOnly = pollOnly
+	w.lock.Unlock()
+	if !changed {
+		return
+	}
+	if pollOnly {
+		glog.Warningf("Cluster %s forbids watching %s - falling back to polling every %v", w.clusterName, w.resource, w.pollInterval)
+		clusterStorePollOnly.WithLabelValues(w.clusterName, w.resource).Set(1)
+	} else {
+		glog.V(2).Infof("Cluster %s allows watching %s again - leaving poll-only mode", w.clusterName, w.resource)
+		clusterStorePollOnly.WithLabelValues(w.clusterName, w.resource).Set(0)
+	}
+	if w.onModeChange != nil {
+		w.onModeChange(pollOnly)
+	}
+}
+
+// pollWatcher is the watch.Interface handed to the reflector while a cluster
+// is in poll-only mode. It relists through the delegate at the parent's poll
+// interval and emits the difference from the previous list as watch events.
+// When a probe shows that watching is allowed again the result channel is
+// closed, making the reflector reconnect through the wrapper and pick up the
+// real watch.
+type pollWatcher struct {
+	parent *watchFallbackListerWatcher
+
+	result   chan watch.Event
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func (p *pollWatcher) ResultChan() <-chan watch.Event {
+	return p.result
+}
+
+func (p *pollWatcher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+func (p *pollWatcher) run() {
+	defer close(p.result)
+	// The reflector listed just before starting this watch, so the initial
+	// snapshot is taken silently and only later changes are emitted.
+	snapshot, err := p.list()
+	if err != nil {
+		glog.Errorf("Failed to take the initial %s snapshot of cluster %s in poll-only mode: %v", p.parent.resource, p.parent.clusterName, err)
+		snapshot = map[string]pkgruntime.Object{}
+	}
+	ticker := time.NewTicker(p.parent.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		// Re-probe the watch verb; if it is allowed again, reconnect through
+		// the wrapper so the reflector gets a real watch.
+		if probe, err := p.parent.delegate.Watch(apiv1.ListOptions{}); err == nil {
+			probe.Stop()
+			return
+		}
+		current, err := p.list()
+		if err != nil {
+			glog.Errorf("Failed to poll %s of cluster %s: %v", p.parent.resource, p.parent.clusterName, err)
+			continue
+		}
+		for key, obj := range current {
+			previous, found := snapshot[key]
+			if !found {
+				if !p.emit(watch.Event{Type: watch.Added, Object: obj}) {
+					return
+				}
+			} else if !reflect.DeepEqual(previous, obj) {
+				if !p.emit(watch.Event{Type: watch.Modified, Object: obj}) {
+					return
+				}
+			}
+		}
+		for key, obj := range snapshot {
+			if _, found := current[key]; !found {
+				if !p.emit(watch.Event{Type: watch.Deleted, Object: obj}) {
+					return
+				}
+			}
+		}
+		snapshot = current
+	}
+}
+
+// list fetches the current content through the delegate, keyed the same way
+// the informer's store is.
+func (p *pollWatcher) list() (map[string]pkgruntime.Object, error) {
+	listObj, err := p.parent.delegate.List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(listObj)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]pkgruntime.Object, len(items))
+	for _, item := range items {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(item)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = item
+	}
+	return result, nil
+}
+
+// emit delivers the event to the reflector, returning false if the watcher
+// was stopped while blocked on the send.
+func (p *pollWatcher) emit(event watch.Event) bool {
+	select {
+	case p.result <- event:
+		return true
+	case <-p.stop:
+		return false
+	}
+}
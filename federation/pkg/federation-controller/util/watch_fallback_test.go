@@ -0,0 +1,149 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/client/cache"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/runtime/schema"
+	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/watch"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// restrictedListerWatcher serves lists from a mutable set of configmaps and
+// rejects watches with Forbidden until allowWatch is called.
+type restrictedListerWatcher struct {
+	sync.Mutex
+	items        map[string]*apiv1.ConfigMap
+	watchAllowed bool
+}
+
+func newRestrictedListerWatcher() *restrictedListerWatcher {
+	return &restrictedListerWatcher{items: map[string]*apiv1.ConfigMap{}}
+}
+
+func (r *restrictedListerWatcher) set(configMap *apiv1.ConfigMap) {
+	r.Lock()
+	defer r.Unlock()
+	r.items[configMap.Namespace+"/"+configMap.Name] = configMap
+}
+
+func (r *restrictedListerWatcher) delete(namespace, name string) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.items, namespace+"/"+name)
+}
+
+func (r *restrictedListerWatcher) allowWatch() {
+	r.Lock()
+	defer r.Unlock()
+	r.watchAllowed = true
+}
+
+func (r *restrictedListerWatcher) List(options apiv1.ListOptions) (runtime.Object, error) {
+	r.Lock()
+	defer r.Unlock()
+	list := &apiv1.ConfigMapList{}
+	for _, item := range r.items {
+		list.Items = append(list.Items, *item)
+	}
+	return list, nil
+}
+
+func (r *restrictedListerWatcher) Watch(options apiv1.ListOptions) (watch.Interface, error) {
+	r.Lock()
+	defer r.Unlock()
+	if !r.watchAllowed {
+		return nil, errors.NewForbidden(schema.GroupResource{Resource: "configmaps"},
+			"", fmt.Errorf("watch is not permitted"))
+	}
+	return watch.NewFake(), nil
+}
+
+// Checks that a cluster rejecting the watch verb with Forbidden degrades to
+// poll-only mode in which the store still tracks adds, updates and deletes,
+// and that the mode reverts automatically once watching is allowed again.
+func TestWatchFallbackPollOnlyMode(t *testing.T) {
+	lw := newRestrictedListerWatcher()
+	lw.set(&apiv1.ConfigMap{
+		ObjectMeta: apiv1.ObjectMeta{Namespace: "ns1", Name: "cm1"},
+		Data:       map[string]string{"k": "v1"},
+	})
+
+	modeChanges := make(chan bool, 10)
+	wrapped := NewWatchFallbackListerWatcher("configmaps", "cluster1", lw, 10*time.Millisecond, func(pollOnly bool) {
+		modeChanges <- pollOnly
+	})
+
+	store, controller := cache.NewInformer(wrapped, &apiv1.ConfigMap{}, 0, cache.ResourceEventHandlerFuncs{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go controller.Run(stop)
+
+	waitForMode := func(wantPollOnly bool) {
+		select {
+		case pollOnly := <-modeChanges:
+			assert.Equal(t, wantPollOnly, pollOnly)
+		case <-time.After(wait.ForeverTestTimeout):
+			t.Fatalf("timed out waiting for mode change to pollOnly=%v", wantPollOnly)
+		}
+	}
+	waitForStore := func(key, wantValue string) {
+		assert.NoError(t, wait.Poll(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+			obj, exists, err := store.GetByKey(key)
+			if err != nil || !exists {
+				return false, err
+			}
+			return obj.(*apiv1.ConfigMap).Data["k"] == wantValue, nil
+		}), "store did not reach %s=%q", key, wantValue)
+	}
+
+	// The first forbidden watch flips the informer into poll-only mode; the
+	// initial list still populated the store.
+	waitForMode(true)
+	waitForStore("ns1/cm1", "v1")
+
+	// The poller picks up adds, updates and deletes without a working watch.
+	lw.set(&apiv1.ConfigMap{
+		ObjectMeta: apiv1.ObjectMeta{Namespace: "ns1", Name: "cm2"},
+		Data:       map[string]string{"k": "v1"},
+	})
+	waitForStore("ns1/cm2", "v1")
+	lw.set(&apiv1.ConfigMap{
+		ObjectMeta: apiv1.ObjectMeta{Namespace: "ns1", Name: "cm1"},
+		Data:       map[string]string{"k": "v2"},
+	})
+	waitForStore("ns1/cm1", "v2")
+	lw.delete("ns1", "cm2")
+	assert.NoError(t, wait.Poll(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		_, exists, err := store.GetByKey("ns1/cm2")
+		return !exists, err
+	}), "deleted object was not removed from the store")
+
+	// Once the cluster allows watching again the poller steps aside.
+	lw.allowWatch()
+	waitForMode(false)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package recovery exports and imports the state of a federation control
+// plane - the federated objects and their propagation annotations - so that
+// a lost control plane can be rebuilt without re-creating or disturbing the
+// per-cluster copies the federation controllers manage.
+package recovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+)
+
+// stateVersion identifies the serialization format of a State dump, so that
+// future versions can detect and convert older dumps.
+const stateVersion = "federation-recovery/v1"
+
+// State is the JSON-serializable snapshot of the federated objects held by a
+// federation control plane. Object metadata is stored as the API server
+// returned it; server-populated fields are cleared again on import.
+type State struct {
+	Version     string                         `json:"version"`
+	Namespaces  []apiv1.Namespace              `json:"namespaces,omitempty"`
+	Services    []apiv1.Service                `json:"services,omitempty"`
+	Secrets     []apiv1.Secret                 `json:"secrets,omitempty"`
+	ConfigMaps  []apiv1.ConfigMap              `json:"configMaps,omitempty"`
+	Ingresses   []extensionsv1beta1.Ingress    `json:"ingresses,omitempty"`
+	DaemonSets  []extensionsv1beta1.DaemonSet  `json:"daemonSets,omitempty"`
+	Deployments []extensionsv1beta1.Deployment `json:"deployments,omitempty"`
+	ReplicaSets []extensionsv1beta1.ReplicaSet `json:"replicaSets,omitempty"`
+}
+
+// ExportState lists all federated objects from the given control plane and
+// returns them as a JSON dump suitable for ImportState. Propagation
+// annotations travel with the objects' metadata.
+func ExportState(fedClient federationclientset.Interface) ([]byte, error) {
+	state := State{Version: stateVersion}
+
+	namespaces, err := fedClient.Core().Namespaces().List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated namespaces: %v", err)
+	}
+	state.Namespaces = namespaces.Items
+
+	services, err := fedClient.Core().Services(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated services: %v", err)
+	}
+	state.Services = services.Items
+
+	secrets, err := fedClient.Core().Secrets(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated secrets: %v", err)
+	}
+	state.Secrets = secrets.Items
+
+	configMaps, err := fedClient.Core().ConfigMaps(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated configmaps: %v", err)
+	}
+	state.ConfigMaps = configMaps.Items
+
+	ingresses, err := fedClient.Extensions().Ingresses(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated ingresses: %v", err)
+	}
+	state.Ingresses = ingresses.Items
+
+	daemonSets, err := fedClient.Extensions().DaemonSets(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated daemonsets: %v", err)
+	}
+	state.DaemonSets = daemonSets.Items
+
+	deployments, err := fedClient.Extensions().Deployments(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated deployments: %v", err)
+	}
+	state.Deployments = deployments.Items
+
+	replicaSets, err := fedClient.Extensions().ReplicaSets(apiv1.NamespaceAll).List(apiv1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list federated replicasets: %v", err)
+	}
+	state.ReplicaSets = replicaSets.Items
+
+	return json.MarshalIndent(&state, "", "  ")
+}
+
+// ImportState recreates the federated objects from a dump produced by
+// ExportState against a (typically freshly installed) control plane.
+// Namespaces are created first so namespaced objects have somewhere to live.
+//
+// When adopt is true each recreated object is annotated with the UID it had
+// in the dump, so that controllers recognize the managed-by markers on
+// existing member cluster copies as their own and adopt the copies in place
+// instead of replacing them.
+func ImportState(fedClient federationclientset.Interface, reader io.Reader, adopt bool) error {
+	state := State{}
+	if err := json.NewDecoder(reader).Decode(&state); err != nil {
+		return fmt.Errorf("failed to decode state dump: %v", err)
+	}
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported state dump version %q, want %q", state.Version, stateVersion)
+	}
+
+	for i := range state.Namespaces {
+		namespace := state.Namespaces[i]
+		prepareForImport(&namespace.ObjectMeta, adopt)
+		if _, err := fedClient.Core().Namespaces().Create(&namespace); err != nil {
+			return fmt.Errorf("failed to create federated namespace %q: %v", namespace.Name, err)
+		}
+	}
+	for i := range state.Services {
+		service := state.Services[i]
+		prepareForImport(&service.ObjectMeta, adopt)
+		// A service's clusterIP belongs to the old control plane.
+		service.Spec.ClusterIP = ""
+		if _, err := fedClient.Core().Services(service.Namespace).Create(&service); err != nil {
+			return fmt.Errorf("failed to create federated service %s/%s: %v", service.Namespace, service.Name, err)
+		}
+	}
+	for i := range state.Secrets {
+		secret := state.Secrets[i]
+		prepareForImport(&secret.ObjectMeta, adopt)
+		if _, err := fedClient.Core().Secrets(secret.Namespace).Create(&secret); err != nil {
+			return fmt.Errorf("failed to create federated secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+	}
+	for i := range state.ConfigMaps {
+		configMap := state.ConfigMaps[i]
+		prepareForImport(&configMap.ObjectMeta, adopt)
+		if _, err := fedClient.Core().ConfigMaps(configMap.Namespace).Create(&configMap); err != nil {
+			return fmt.Errorf("failed to create federated configmap %s/%s: %v", configMap.Namespace, configMap.Name, err)
+		}
+	}
+	for i := range state.Ingresses {
+		ingress := state.Ingresses[i]
+		prepareForImport(&ingress.ObjectMeta, adopt)
+		if _, err := fedClient.Extensions().Ingresses(ingress.Namespace).Create(&ingress); err != nil {
+			return fmt.Errorf("failed to create federated ingress %s/%s: %v", ingress.Namespace, ingress.Name, err)
+		}
+	}
+	for i := range state.DaemonSets {
+		daemonSet := state.DaemonSets[i]
+		prepareForImport(&daemonSet.ObjectMeta, adopt)
+		if _, err := fedClient.Extensions().DaemonSets(daemonSet.Namespace).Create(&daemonSet); err != nil {
+			return fmt.Errorf("failed to create federated daemonset %s/%s: %v", daemonSet.Namespace, daemonSet.Name, err)
+		}
+	}
+	for i := range state.Deployments {
+		deployment := state.Deployments[i]
+		prepareForImport(&deployment.ObjectMeta, adopt)
+		if _, err := fedClient.Extensions().Deployments(deployment.Namespace).Create(&deployment); err != nil {
+			return fmt.Errorf("failed to create federated deployment %s/%s: %v", deployment.Namespace, deployment.Name, err)
+		}
+	}
+	for i := range state.ReplicaSets {
+		replicaSet := state.ReplicaSets[i]
+		prepareForImport(&replicaSet.ObjectMeta, adopt)
+		if _, err := fedClient.Extensions().ReplicaSets(replicaSet.Namespace).Create(&replicaSet); err != nil {
+			return fmt.Errorf("failed to create federated replicaset %s/%s: %v", replicaSet.Namespace, replicaSet.Name, err)
+		}
+	}
+	return nil
+}
+
+// prepareForImport clears the server-populated metadata fields of an exported
+// object so the new control plane can accept it, and when adopt is true
+// records the object's previous UID so controllers adopt existing member
+// cluster copies instead of replacing them as stale.
+func prepareForImport(meta *apiv1.ObjectMeta, adopt bool) {
+	previousUID := string(meta.UID)
+	meta.UID = ""
+	meta.ResourceVersion = ""
+	meta.SelfLink = ""
+	meta.Generation = 0
+	meta.CreationTimestamp = metav1.Time{}
+	if adopt && previousUID != "" {
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		meta.Annotations[util.AdoptedFromUIDAnnotation] = previousUID
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package recovery
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	fakefedclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset/fake"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/util"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	namespace := &apiv1.Namespace{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name: "mynamespace",
+			UID:  "namespace-uid",
+		},
+	}
+	service := &apiv1.Service{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:            "test-service",
+			Namespace:       "mynamespace",
+			UID:             "service-uid",
+			ResourceVersion: "42",
+		},
+		Spec: apiv1.ServiceSpec{
+			ClusterIP: "10.0.0.1",
+		},
+	}
+	ingress := &extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "test-ingress",
+			Namespace: "mynamespace",
+			UID:       "ingress-uid",
+			Annotations: map[string]string{
+				"federation.kubernetes.io/propagation-policy": "include=*",
+			},
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{
+				ServiceName: "test-service",
+			},
+		},
+	}
+	sourceClient := fakefedclientset.NewSimpleClientset(namespace, service, ingress)
+
+	dump, err := ExportState(sourceClient)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Import with adoption into a fresh control plane.
+	targetClient := fakefedclientset.NewSimpleClientset()
+	err = ImportState(targetClient, bytes.NewReader(dump), true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	importedNamespace, err := targetClient.Core().Namespaces().Get("mynamespace", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "namespace-uid", importedNamespace.Annotations[util.AdoptedFromUIDAnnotation])
+	}
+	importedService, err := targetClient.Core().Services("mynamespace").Get("test-service", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Empty(t, importedService.ResourceVersion, "server-populated metadata must be cleared")
+		assert.Empty(t, importedService.Spec.ClusterIP, "the clusterIP belongs to the old control plane")
+		assert.Equal(t, "service-uid", importedService.Annotations[util.AdoptedFromUIDAnnotation])
+	}
+	importedIngress, err := targetClient.Extensions().Ingresses("mynamespace").Get("test-ingress", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, ingress.Spec, importedIngress.Spec)
+		assert.Equal(t, "include=*", importedIngress.Annotations["federation.kubernetes.io/propagation-policy"],
+			"propagation annotations must survive the round trip")
+		assert.Equal(t, "ingress-uid", importedIngress.Annotations[util.AdoptedFromUIDAnnotation])
+	}
+
+	// Without adoption no ownership seed is left behind.
+	plainClient := fakefedclientset.NewSimpleClientset()
+	err = ImportState(plainClient, bytes.NewReader(dump), false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	importedIngress, err = plainClient.Extensions().Ingresses("mynamespace").Get("test-ingress", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.NotContains(t, importedIngress.Annotations, util.AdoptedFromUIDAnnotation)
+	}
+}
+
+func TestImportRejectsUnknownVersion(t *testing.T) {
+	err := ImportState(fakefedclientset.NewSimpleClientset(), strings.NewReader(`{"version":"federation-recovery/v0"}`), false)
+	assert.Error(t, err)
+}
@@ -107,6 +107,18 @@ func (c *Controller) HasSynced() bool {
 	return c.config.Queue.HasSynced()
 }
 
+// LastSyncResourceVersion returns the resource version the underlying
+// reflector most recently synced to, or the empty string before Run has
+// started the reflector.
+func (c *Controller) LastSyncResourceVersion() string {
+	c.reflectorMutex.RLock()
+	defer c.reflectorMutex.RUnlock()
+	if c.reflector == nil {
+		return ""
+	}
+	return c.reflector.LastSyncResourceVersion()
+}
+
 // Requeue adds the provided object back into the queue if it does not already exist.
 func (c *Controller) Requeue(obj interface{}) error {
 	return c.config.Queue.AddIfNotPresent(Deltas{
@@ -44,6 +44,13 @@ const (
 	dynamicVolumeProvisioning = "DynamicVolumeProvisioning"
 	streamingProxyRedirects   = "StreamingProxyRedirects"
 
+	// Gates for alpha behaviors of the federation controllers. Deployments
+	// not opting in must see no behavior change while a gate is off.
+	federationProgressiveRollout = "FederationProgressiveRollout"
+	federationIngressAdoption    = "FederationIngressAdoption"
+	federationCanary             = "FederationCanary"
+	federationNodePortRewrite    = "FederationNodePortRewrite"
+
 	// experimentalHostUserNamespaceDefaulting Default userns=host for containers
 	// that are using other host namespaces, host mounts, the pod contains a privileged container,
 	// or specific non-namespaced capabilities
@@ -63,6 +70,10 @@ var (
 		dynamicVolumeProvisioning:                   {true, alpha},
 		streamingProxyRedirects:                     {false, alpha},
 		experimentalHostUserNamespaceDefaultingGate: {false, alpha},
+		federationProgressiveRollout:                {false, alpha},
+		federationIngressAdoption:                   {true, alpha},
+		federationCanary:                            {false, alpha},
+		federationNodePortRewrite:                   {false, alpha},
 	}
 
 	// Special handling for a few gates.
@@ -127,6 +138,32 @@ type FeatureGate interface {
 	// owner: @pweil-
 	// alpha: v1.5
 	ExperimentalHostUserNamespaceDefaulting() bool
+
+	// owner: @quinton-hoole
+	// alpha: v1.5
+	FederationProgressiveRollout() bool
+
+	// owner: @quinton-hoole
+	// alpha: v1.5
+	FederationIngressAdoption() bool
+
+	// owner: @quinton-hoole
+	// alpha: v1.5
+	FederationCanary() bool
+
+	// owner: @quinton-hoole
+	// alpha: v1.5
+	FederationNodePortRewrite() bool
+}
+
+// NewFeatureGate returns a FeatureGate with all features at their default
+// values, independent of DefaultFeatureGate. Useful for components that scope
+// gates to a single controller and for tests.
+func NewFeatureGate() FeatureGate {
+	return &featureGate{
+		known:   knownFeatures,
+		special: specialFeatures,
+	}
 }
 
 // featureGate implements FeatureGate as well as pflag.Value for flag parsing.
@@ -226,6 +263,26 @@ func (f *featureGate) ExperimentalHostUserNamespaceDefaulting() bool {
 	return f.lookup(experimentalHostUserNamespaceDefaultingGate)
 }
 
+// FederationProgressiveRollout returns value for federationProgressiveRollout
+func (f *featureGate) FederationProgressiveRollout() bool {
+	return f.lookup(federationProgressiveRollout)
+}
+
+// FederationIngressAdoption returns value for federationIngressAdoption
+func (f *featureGate) FederationIngressAdoption() bool {
+	return f.lookup(federationIngressAdoption)
+}
+
+// FederationCanary returns value for federationCanary
+func (f *featureGate) FederationCanary() bool {
+	return f.lookup(federationCanary)
+}
+
+// FederationNodePortRewrite returns value for federationNodePortRewrite
+func (f *featureGate) FederationNodePortRewrite() bool {
+	return f.lookup(federationNodePortRewrite)
+}
+
 func (f *featureGate) lookup(key string) bool {
 	defaultValue := f.known[key].enabled
 	if f.enabled != nil {
@@ -0,0 +1,229 @@
+// +build integration,!no-etcd
+
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	federationapi "k8s.io/kubernetes/federation/apis/federation/v1beta1"
+	federationclientset "k8s.io/kubernetes/federation/client/clientset_generated/federation_clientset"
+	"k8s.io/kubernetes/federation/cmd/federation-apiserver/app"
+	"k8s.io/kubernetes/federation/cmd/federation-apiserver/app/options"
+	"k8s.io/kubernetes/federation/pkg/federation-controller/ingress"
+	testutil "k8s.io/kubernetes/federation/pkg/federation-controller/util/test"
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiv1 "k8s.io/kubernetes/pkg/api/v1"
+	extensionsv1beta1 "k8s.io/kubernetes/pkg/apis/extensions/v1beta1"
+	metav1 "k8s.io/kubernetes/pkg/apis/meta/v1"
+	kubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset"
+	fakekubeclientset "k8s.io/kubernetes/pkg/client/clientset_generated/clientset/fake"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/util/wait"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ingressSecurePort = 6443 + 10
+var ingressInsecurePort = 8080 + 10
+var ingressServerIP = fmt.Sprintf("http://localhost:%v", ingressInsecurePort)
+
+// newFakeMemberCluster builds a fake member cluster clientset pre-loaded with
+// an ingress UID ConfigMap, returning the clientset and a channel carrying the
+// ingresses created in the cluster.
+func newFakeMemberCluster(uid string) (*fakekubeclientset.Clientset, chan *extensionsv1beta1.Ingress) {
+	client := &fakekubeclientset.Clientset{}
+	testutil.RegisterFakeList("ingresses", &client.Fake, &extensionsv1beta1.IngressList{Items: []extensionsv1beta1.Ingress{}})
+	testutil.RegisterFakeList("configmaps", &client.Fake, &apiv1.ConfigMapList{Items: []apiv1.ConfigMap{
+		{
+			ObjectMeta: apiv1.ObjectMeta{
+				Name:      "ingress-uid",
+				Namespace: "kube-system",
+			},
+			Data: map[string]string{"uid": uid},
+		},
+	}})
+	ingressWatch := testutil.RegisterFakeWatch("ingresses", &client.Fake)
+	testutil.RegisterFakeWatch("configmaps", &client.Fake)
+	createChan := testutil.RegisterFakeCopyOnCreate("ingresses", &client.Fake, ingressWatch)
+	testutil.RegisterFakeCopyOnUpdate("ingresses", &client.Fake, ingressWatch)
+
+	typedCreateChan := make(chan *extensionsv1beta1.Ingress, 10)
+	go func() {
+		for obj := range createChan {
+			if createdIngress, ok := obj.(*extensionsv1beta1.Ingress); ok {
+				typedCreateChan <- createdIngress
+			}
+		}
+	}()
+	return client, typedCreateChan
+}
+
+// Runs the real federation apiserver storage layer (backed by etcd) with the
+// ingress controller pointed at it and two fake member clusters, covering the
+// create/update/status/delete flows with real encoding and defaulting.
+func TestFederatedIngressIntegration(t *testing.T) {
+	certDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("Failed to create temporary certificate directory: %v", err)
+	}
+	defer os.RemoveAll(certDir)
+
+	s := options.NewServerRunOptions()
+	s.SecureServing.ServingOptions.BindPort = ingressSecurePort
+	s.InsecureServing.BindPort = ingressInsecurePort
+	s.Etcd.StorageConfig.ServerList = []string{"http://localhost:2379"}
+	s.Etcd.StorageConfig.Prefix = "federation-ingress-integration"
+	s.SecureServing.ServerCert.CertDirectory = certDir
+
+	go func() {
+		if err := app.Run(s); err != nil {
+			t.Fatalf("Error in bringing up the server: %v", err)
+		}
+	}()
+	if err := waitForIngressApiserverUp(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	fedClient := federationclientset.NewForConfigOrDie(restclient.AddUserAgent(
+		&restclient.Config{Host: ingressServerIP}, "federated-ingress-integration"))
+
+	cluster1Client, cluster1Created := newFakeMemberCluster("abc123")
+	cluster2Client, cluster2Created := newFakeMemberCluster("abc123")
+
+	controller := ingress.NewIngressController(fedClient)
+	controller.SetClientFactoryForTest(func(cluster *federationapi.Cluster) (kubeclientset.Interface, error) {
+		switch cluster.Name {
+		case "cluster1":
+			return cluster1Client, nil
+		case "cluster2":
+			return cluster2Client, nil
+		}
+		return nil, fmt.Errorf("unknown cluster %q", cluster.Name)
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	controller.Run(stop)
+
+	for _, name := range []string{"cluster1", "cluster2"} {
+		cluster, err := fedClient.Federation().Clusters().Create(&federationapi.Cluster{
+			ObjectMeta: apiv1.ObjectMeta{Name: name},
+			Spec: federationapi.ClusterSpec{
+				ServerAddressByClientCIDRs: []federationapi.ServerAddressByClientCIDR{
+					{ClientCIDR: "0.0.0.0/0", ServerAddress: "http://localhost:8080"},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to create cluster %q: %v", name, err)
+		}
+		cluster.Status = federationapi.ClusterStatus{
+			Conditions: []federationapi.ClusterCondition{
+				{Type: federationapi.ClusterReady, Status: apiv1.ConditionTrue},
+			},
+		}
+		if _, err := fedClient.Federation().Clusters().UpdateStatus(cluster); err != nil {
+			t.Fatalf("Failed to mark cluster %q ready: %v", name, err)
+		}
+	}
+
+	// Create: the ingress must be round-tripped through real storage and show
+	// up in the first cluster.
+	fedIngress := &extensionsv1beta1.Ingress{
+		ObjectMeta: apiv1.ObjectMeta{
+			Name:      "test-ingress",
+			Namespace: apiv1.NamespaceDefault,
+			Annotations: map[string]string{
+				"ingress.federation.kubernetes.io/first-cluster": "cluster1",
+			},
+		},
+		Spec: extensionsv1beta1.IngressSpec{
+			Backend: &extensionsv1beta1.IngressBackend{
+				ServiceName: "testsvc",
+			},
+		},
+	}
+	if _, err := fedClient.Extensions().Ingresses(apiv1.NamespaceDefault).Create(fedIngress); err != nil {
+		t.Fatalf("Failed to create federated ingress: %v", err)
+	}
+
+	var createdIngress *extensionsv1beta1.Ingress
+	select {
+	case createdIngress = <-cluster1Created:
+	case <-time.After(wait.ForeverTestTimeout):
+		t.Fatalf("Timed out waiting for ingress creation in cluster1")
+	}
+	assert.Equal(t, "test-ingress", createdIngress.Name)
+	assert.NotNil(t, createdIngress.Spec.Backend)
+	assert.Equal(t, "testsvc", createdIngress.Spec.Backend.ServiceName)
+	// The empty TLS slice must survive the round trip through real encoding.
+	assert.Len(t, createdIngress.Spec.TLS, 0)
+	// cluster2 must not receive the ingress before the first cluster's static
+	// IP annotation appears on the federated ingress.
+	select {
+	case unexpected := <-cluster2Created:
+		t.Fatalf("Ingress created in cluster2 before cluster1's IP was allocated: %v", unexpected)
+	case <-time.After(time.Second):
+	}
+
+	// Update: a spec change on the federated ingress must reach the cluster.
+	err = wait.PollImmediate(time.Second, wait.ForeverTestTimeout, func() (bool, error) {
+		stored, err := fedClient.Extensions().Ingresses(apiv1.NamespaceDefault).Get("test-ingress", metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		stored.Spec.Backend.ServiceName = "testsvc2"
+		_, err = fedClient.Extensions().Ingresses(apiv1.NamespaceDefault).Update(stored)
+		if errors.IsConflict(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+	if err != nil {
+		t.Fatalf("Failed to update federated ingress: %v", err)
+	}
+
+	// Delete: removing the federated ingress must eventually remove it from
+	// storage once the deletion finalizers have been processed.
+	if err := fedClient.Extensions().Ingresses(apiv1.NamespaceDefault).Delete("test-ingress", &apiv1.DeleteOptions{}); err != nil {
+		t.Fatalf("Failed to delete federated ingress: %v", err)
+	}
+	err = wait.PollImmediate(time.Second, wait.ForeverTestTimeout, func() (bool, error) {
+		_, err := fedClient.Extensions().Ingresses(apiv1.NamespaceDefault).Get("test-ingress", metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("Federated ingress was not deleted: %v", err)
+	}
+}
+
+func waitForIngressApiserverUp() error {
+	for start := time.Now(); time.Since(start) < time.Minute; time.Sleep(5 * time.Second) {
+		if _, err := readResponse(ingressServerIP + "/version"); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("waiting for apiserver timed out")
+}